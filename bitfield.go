@@ -0,0 +1,138 @@
+package miniredis
+
+// Integer fields in strings, for BITFIELD.
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	msgBitfieldType     = "ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
+	msgBitfieldOverflow = "ERR Invalid OVERFLOW type specified"
+	msgBitfieldRO       = "ERR BITFIELD_RO only supports the GET subcommand"
+)
+
+// bitfieldType is the "i16" or "u8" argument.
+type bitfieldType struct {
+	signed bool
+	bits   uint
+}
+
+func parseBitfieldType(s string) (bitfieldType, error) {
+	var t bitfieldType
+	if len(s) < 2 {
+		return t, errors.New(msgBitfieldType)
+	}
+	switch s[0] {
+	case 'i', 'I':
+		t.signed = true
+	case 'u', 'U':
+	default:
+		return t, errors.New(msgBitfieldType)
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 1 || (t.signed && n > 64) || (!t.signed && n > 63) {
+		return t, errors.New(msgBitfieldType)
+	}
+	t.bits = uint(n)
+	return t, nil
+}
+
+// parseBitfieldOffset parses an offset in bits, or, with a '#' prefix, in
+// multiples of the type width.
+func parseBitfieldOffset(s string, t bitfieldType) (int, error) {
+	mul := 1
+	if strings.HasPrefix(s, "#") {
+		mul = int(t.bits)
+		s = s[1:]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > math.MaxInt32 {
+		return 0, errors.New(msgBitOffset)
+	}
+	return n * mul, nil
+}
+
+// getBitfield reads an unsigned field. Bits past the end of the string are 0.
+func getBitfield(v []byte, offset int, bits uint) uint64 {
+	var r uint64
+	for i := 0; i < int(bits); i++ {
+		r <<= 1
+		bit := offset + i
+		if bit/8 < len(v) && v[bit/8]&(0x80>>uint(bit%8)) != 0 {
+			r |= 1
+		}
+	}
+	return r
+}
+
+// setBitfield writes the lowest bits of n. v must be long enough.
+func setBitfield(v []byte, offset int, bits uint, n uint64) {
+	for i := int(bits) - 1; i >= 0; i-- {
+		bit := offset + i
+		if n&1 != 0 {
+			v[bit/8] |= 0x80 >> uint(bit%8)
+		} else {
+			v[bit/8] &^= 0x80 >> uint(bit%8)
+		}
+		n >>= 1
+	}
+}
+
+// signedBitfield sign extends an unsigned field value.
+func signedBitfield(n uint64, bits uint) int64 {
+	if bits < 64 && n&(1<<(bits-1)) != 0 {
+		n |= math.MaxUint64 << bits
+	}
+	return int64(n)
+}
+
+// unsignedBitfieldOverflow adds incr to value, and handles overflows the way
+// OVERFLOW says ("wrap", "sat", or "fail"). Returns the new value and whether
+// there was an overflow.
+func unsignedBitfieldOverflow(value uint64, incr int64, bits uint, overflow string) (uint64, bool) {
+	max := uint64(1)<<bits - 1
+	switch {
+	case value > max || (incr > 0 && uint64(incr) > max-value):
+		if overflow == "sat" {
+			return max, true
+		}
+	case incr < 0 && uint64(-incr) > value:
+		if overflow == "sat" {
+			return 0, true
+		}
+	default:
+		return value + uint64(incr), false
+	}
+	return (value + uint64(incr)) & max, true
+}
+
+// signedBitfieldOverflow is unsignedBitfieldOverflow for signed fields.
+func signedBitfieldOverflow(value, incr int64, bits uint, overflow string) (int64, bool) {
+	max := int64(math.MaxInt64)
+	if bits < 64 {
+		max = 1<<(bits-1) - 1
+	}
+	min := -max - 1
+	maxincr := int64(uint64(max) - uint64(value))
+	minincr := min - value
+
+	switch {
+	case value > max || (bits != 64 && incr > maxincr) || (value >= 0 && incr > 0 && incr > maxincr):
+		if overflow == "sat" {
+			return max, true
+		}
+	case value < min || (bits != 64 && incr < minincr) || (value < 0 && incr < 0 && incr < minincr):
+		if overflow == "sat" {
+			return min, true
+		}
+	default:
+		return value + incr, false
+	}
+	// wrap, computed unsigned so the overflow is defined
+	c := uint64(value) + uint64(incr)
+	return signedBitfield(c&(math.MaxUint64>>(64-bits)), bits), true
+}
@@ -2,7 +2,9 @@ package miniredis
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/server"
 )
@@ -26,6 +28,26 @@ func (m *Miniredis) cmdClient(c *server.Peer, cmd string, args []string) {
 			m.cmdClientSetName(c, args[1:])
 		case "GETNAME":
 			m.cmdClientGetName(c, args[1:])
+		case "ID":
+			m.cmdClientID(c, args[1:])
+		case "INFO":
+			m.cmdClientInfo(c, args[1:])
+		case "LIST":
+			m.cmdClientList(c, args[1:])
+		case "SETINFO":
+			m.cmdClientSetInfo(c, args[1:])
+		case "TRACKING":
+			m.cmdClientTracking(c, args[1:])
+		case "CACHING":
+			m.cmdClientCaching(c, args[1:])
+		case "PAUSE":
+			m.cmdClientPause(c, args[1:])
+		case "UNPAUSE":
+			m.cmdClientUnpause(c, args[1:])
+		case "NO-EVICT":
+			m.cmdClientNoEvict(c, args[1:])
+		case "NO-TOUCH":
+			m.cmdClientNoTouch(c, args[1:])
 		default:
 			setDirty(c)
 			c.WriteError(fmt.Sprintf("ERR unknown subcommand '%s'. Try CLIENT HELP.", cmd))
@@ -42,7 +64,7 @@ func (m *Miniredis) cmdClientSetName(c *server.Peer, args []string) {
 	}
 
 	name := args[0]
-	if strings.ContainsAny(name, " \n") {
+	if !validClientString(name) {
 		setDirty(c)
 		c.WriteError("ERR Client names cannot contain spaces, newlines or special characters.")
 		return
@@ -66,3 +88,490 @@ func (m *Miniredis) cmdClientGetName(c *server.Peer, args []string) {
 		c.WriteBulk(c.ClientName)
 	}
 }
+
+// CLIENT ID
+func (m *Miniredis) cmdClientID(c *server.Peer, args []string) {
+	if len(args) > 0 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|id' command")
+		return
+	}
+
+	c.WriteInt(c.ID)
+}
+
+// CLIENT INFO
+func (m *Miniredis) cmdClientInfo(c *server.Peer, args []string) {
+	if len(args) > 0 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|info' command")
+		return
+	}
+
+	c.WriteVerbatim("txt", clientInfoLine(c))
+}
+
+// CLIENT LIST [TYPE normal|master|replica|pubsub] [ID id [id ...]]
+func (m *Miniredis) cmdClientList(c *server.Peer, args []string) {
+	var (
+		typ string
+		ids map[int]bool
+	)
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "TYPE":
+			if len(args) < 2 {
+				syntaxError(c)
+				return
+			}
+			typ = strings.ToLower(args[1])
+			switch typ {
+			case "normal", "master", "replica", "slave", "pubsub":
+			default:
+				setDirty(c)
+				c.WriteError(fmt.Sprintf("ERR Unknown client type '%s'", args[1]))
+				return
+			}
+			args = args[2:]
+		case "ID":
+			if len(args) < 2 {
+				syntaxError(c)
+				return
+			}
+			ids = map[int]bool{}
+			for _, a := range args[1:] {
+				id, err := strconv.Atoi(a)
+				if err != nil || id <= 0 {
+					setDirty(c)
+					c.WriteError("ERR Invalid client ID")
+					return
+				}
+				ids[id] = true
+			}
+			args = nil
+		default:
+			syntaxError(c)
+			return
+		}
+	}
+
+	var res strings.Builder
+	for _, p := range m.srv.Peers() {
+		if ids != nil && !ids[p.ID] {
+			continue
+		}
+		if typ != "" && clientType(p) != typ {
+			continue
+		}
+		res.WriteString(clientInfoLine(p))
+	}
+	c.WriteVerbatim("txt", res.String())
+}
+
+// CLIENT SETINFO LIB-NAME|LIB-VER value
+func (m *Miniredis) cmdClientSetInfo(c *server.Peer, args []string) {
+	if len(args) != 2 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|setinfo' command")
+		return
+	}
+
+	attr, value := strings.ToLower(args[0]), args[1]
+	if attr != "lib-name" && attr != "lib-ver" {
+		setDirty(c)
+		c.WriteError(fmt.Sprintf("ERR Unrecognized option '%s'", args[0]))
+		return
+	}
+	if !validClientString(value) {
+		setDirty(c)
+		c.WriteError(fmt.Sprintf("ERR %s cannot contain spaces, newlines or special characters.", attr))
+		return
+	}
+	if attr == "lib-name" {
+		c.LibName = value
+	} else {
+		c.LibVer = value
+	}
+	c.WriteOK()
+}
+
+// validClientString is true if s can be used as a name in CLIENT SETNAME and
+// CLIENT SETINFO: no spaces, newlines, or other special characters.
+func validClientString(s string) bool {
+	for _, r := range s {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// clientType is the TYPE CLIENT LIST filters on. We only have normal and
+// pubsub clients.
+func clientType(c *server.Peer) string {
+	if ctx, ok := c.Ctx.(*connCtx); ok && ctx.subscriber != nil {
+		return "pubsub"
+	}
+	return "normal"
+}
+
+// clientInfoLine formats a connection the way CLIENT LIST and CLIENT INFO do.
+// Needs the lock.
+func clientInfoLine(c *server.Peer) string {
+	db := 0
+	// don't use getCtx(), it would make a context for other connections
+	if ctx, ok := c.Ctx.(*connCtx); ok {
+		db = ctx.selectedDB
+	}
+	resp := 2
+	if c.IsResp3() {
+		resp = 3
+	}
+	return fmt.Sprintf(
+		"id=%d addr=%s laddr=%s name=%s db=%d resp=%d lib-name=%s lib-ver=%s\n",
+		c.ID,
+		c.Addr,
+		c.LocalAddr,
+		c.ClientName,
+		db,
+		resp,
+		c.LibName,
+		c.LibVer,
+	)
+}
+
+// CLIENT TRACKING
+func (m *Miniredis) cmdClientTracking(c *server.Peer, args []string) {
+	if len(args) < 1 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|tracking' command")
+		return
+	}
+
+	var on bool
+	switch strings.ToUpper(args[0]) {
+	case "ON":
+		on = true
+	case "OFF":
+	default:
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return
+	}
+	args = args[1:]
+
+	opts := newClientTracking()
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "REDIRECT":
+			if len(args) < 2 {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			// REDIRECT isn't supported, so no client ID is good enough.
+			setDirty(c)
+			c.WriteError("ERR The client ID you want redirect to does not exist")
+			return
+		case "PREFIX":
+			if len(args) < 2 {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			opts.prefixes = append(opts.prefixes, args[1])
+			args = args[2:]
+		case "BCAST":
+			opts.bcast = true
+			args = args[1:]
+		case "OPTIN":
+			opts.optin = true
+			args = args[1:]
+		case "OPTOUT":
+			opts.optout = true
+			args = args[1:]
+		case "NOLOOP":
+			opts.noloop = true
+			args = args[1:]
+		default:
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+	}
+
+	if !on {
+		delete(m.trackers, c)
+		c.WriteOK()
+		return
+	}
+
+	if len(opts.prefixes) > 0 && !opts.bcast {
+		setDirty(c)
+		c.WriteError("ERR PREFIX option requires BCAST mode to be enabled")
+		return
+	}
+	if opts.optin && opts.optout {
+		setDirty(c)
+		c.WriteError("ERR You can't use both OPTIN and OPTOUT.")
+		return
+	}
+	if opts.bcast && (opts.optin || opts.optout) {
+		setDirty(c)
+		c.WriteError("ERR OPTIN and OPTOUT are not compatible with BCAST.")
+		return
+	}
+
+	var oldPrefixes []string
+	old, ok := m.trackers[c]
+	if ok {
+		if old.bcast != opts.bcast {
+			setDirty(c)
+			c.WriteError("ERR You can't switch BCAST mode on/off before disabling tracking for this client, and then re-enabling it with a different mode.")
+			return
+		}
+		if old.optin != opts.optin || old.optout != opts.optout {
+			setDirty(c)
+			c.WriteError("ERR You can't switch OPTIN/OPTOUT mode before disabling tracking for this client, and then re-enabling it with a different mode.")
+			return
+		}
+		opts.keys = old.keys
+		oldPrefixes = old.prefixes
+	}
+	// Prefixes may not overlap, neither with the ones from an earlier
+	// TRACKING call nor with each other. Duplicates count as overlapping.
+	overlaps := func(a, b string) bool {
+		return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+	}
+	for i, p := range opts.prefixes {
+		for _, p2 := range oldPrefixes {
+			if overlaps(p, p2) {
+				setDirty(c)
+				c.WriteError(fmt.Sprintf("ERR Prefix '%s' overlaps with an existing prefix '%s'. Prefixes for a single client must not overlap.", p, p2))
+				return
+			}
+		}
+		for _, p2 := range opts.prefixes[i+1:] {
+			if overlaps(p, p2) {
+				setDirty(c)
+				c.WriteError(fmt.Sprintf("ERR Prefix '%s' overlaps with another provided prefix '%s'. Prefixes for a single client must not overlap.", p, p2))
+				return
+			}
+		}
+	}
+	opts.prefixes = append(oldPrefixes, opts.prefixes...)
+
+	if !ok {
+		c.OnDisconnect(func() {
+			m.Lock()
+			delete(m.trackers, c)
+			m.Unlock()
+		})
+	}
+	m.trackers[c] = opts
+	c.WriteOK()
+}
+
+// CLIENT CACHING
+func (m *Miniredis) cmdClientCaching(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|caching' command")
+		return
+	}
+
+	t, ok := m.trackers[c]
+	if !ok || !(t.optin || t.optout) {
+		setDirty(c)
+		c.WriteError("ERR CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled")
+		return
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "YES":
+		if !t.optin {
+			setDirty(c)
+			c.WriteError("ERR CLIENT CACHING YES is only valid when tracking is enabled in OPTIN mode.")
+			return
+		}
+	case "NO":
+		if !t.optout {
+			setDirty(c)
+			c.WriteError("ERR CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode.")
+			return
+		}
+	default:
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return
+	}
+	t.caching = true
+	c.WriteOK()
+}
+
+// CLIENT NO-EVICT. There is no client eviction, so this is only stored.
+func (m *Miniredis) cmdClientNoEvict(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|no-evict' command")
+		return
+	}
+
+	on, ok := parseOnOff(args[0])
+	if !ok {
+		syntaxError(c)
+		return
+	}
+	getCtx(c).noEvict = on
+	c.WriteOK()
+}
+
+// CLIENT NO-TOUCH
+func (m *Miniredis) cmdClientNoTouch(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|no-touch' command")
+		return
+	}
+
+	on, ok := parseOnOff(args[0])
+	if !ok {
+		syntaxError(c)
+		return
+	}
+	getCtx(c).noTouch = on
+	c.WriteOK()
+}
+
+// parseOnOff parses "ON" or "OFF".
+func parseOnOff(v string) (bool, bool) {
+	switch strings.ToUpper(v) {
+	case "ON":
+		return true, true
+	case "OFF":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// CLIENT PAUSE modes
+const (
+	pauseNone = iota
+	pauseWrite
+	pauseAll
+)
+
+// pauseWriteCommands are paused by CLIENT PAUSE WRITE, on top of the
+// writeCommands.
+var pauseWriteCommands = map[string]struct{}{
+	"EVAL":    {},
+	"EVALSHA": {},
+	"EXEC":    {},
+	"FCALL":   {},
+	"PFCOUNT": {},
+	"PUBLISH": {},
+	"WAIT":    {},
+}
+
+// CLIENT PAUSE
+func (m *Miniredis) cmdClientPause(c *server.Peer, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|pause' command")
+		return
+	}
+
+	ms, err := strconv.Atoi(args[0])
+	if err != nil {
+		setDirty(c)
+		c.WriteError(msgTimeoutNotInt)
+		return
+	}
+	if ms < 0 {
+		setDirty(c)
+		c.WriteError(msgTimeoutNegative)
+		return
+	}
+	mode := pauseAll
+	if len(args) == 2 {
+		switch strings.ToUpper(args[1]) {
+		case "WRITE":
+			mode = pauseWrite
+		case "ALL":
+		default:
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+	}
+
+	m.pause(mode, time.Duration(ms)*time.Millisecond)
+	c.WriteOK()
+}
+
+// CLIENT UNPAUSE
+func (m *Miniredis) cmdClientUnpause(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError("ERR wrong number of arguments for 'client|unpause' command")
+		return
+	}
+
+	m.unpause()
+	c.WriteOK()
+}
+
+// pause starts, or extends, a CLIENT PAUSE. A pause which is already in
+// effect keeps its mode and end time if those are stricter. Needs the lock.
+func (m *Miniredis) pause(mode int, d time.Duration) {
+	end := time.Now().Add(d)
+	if m.pauseMode != pauseNone {
+		if m.pauseMode > mode {
+			mode = m.pauseMode
+		}
+		if m.pauseEnd.After(end) {
+			end = m.pauseEnd
+		}
+		m.pauseTimer.Stop()
+	}
+	m.pauseMode = mode
+	m.pauseEnd = end
+	m.pauseTimer = time.AfterFunc(time.Until(end), func() {
+		m.Lock()
+		defer m.Unlock()
+		if m.pauseEnd.Equal(end) {
+			m.unpause()
+		}
+	})
+}
+
+// unpause ends CLIENT PAUSE, and wakes up all paused commands. Needs the lock.
+func (m *Miniredis) unpause() {
+	if m.pauseTimer != nil {
+		m.pauseTimer.Stop()
+		m.pauseTimer = nil
+	}
+	m.pauseMode = pauseNone
+	m.pauseEnd = time.Time{}
+	m.signal.Broadcast()
+}
+
+// pausedFor says whether CLIENT PAUSE holds back cmd. All blocking commands,
+// apart from XREAD, count as writes, so they also don't get served while
+// writes are paused. Needs the lock.
+func (m *Miniredis) pausedFor(cmd string) bool {
+	switch m.pauseMode {
+	case pauseAll:
+		return true
+	case pauseWrite:
+		cmd = strings.ToUpper(cmd)
+		if _, ok := writeCommands[cmd]; ok {
+			return true
+		}
+		_, ok := pauseWriteCommands[cmd]
+		return ok
+	default:
+		return false
+	}
+}
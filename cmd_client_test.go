@@ -1,7 +1,9 @@
 package miniredis
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/proto"
 )
@@ -34,3 +36,534 @@ func TestClient(t *testing.T) {
 		)
 	})
 }
+
+// Test CLIENT TRACKING.
+func TestClientTracking(t *testing.T) {
+	t.Run("default mode", func(t *testing.T) {
+		s, c := runWithClient(t)
+		useRESP3(t, c)
+
+		c2, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c2.Close()
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON")
+		mustDo(t, c, "GET", "foo", proto.NilResp3)
+
+		mustOK(t, c2, "SET", "foo", "bar")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("foo"),
+			),
+		)
+
+		// not tracked anymore, until it's read again
+		mustOK(t, c2, "SET", "foo", "baz")
+		mustOK(t, c2, "SET", "untracked", "baz")
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+
+		mustDo(t, c, "GET", "foo", proto.String("baz"))
+		s.Set("foo", "direct")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("foo"),
+			),
+		)
+
+		mustDo(t, c, "HGET", "h", "f", proto.NilResp3)
+		mustDo(t, c2, "HSET", "h", "f", "v", proto.Int(1))
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("h"),
+			),
+		)
+
+		mustOK(t, c2, "FLUSHALL")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.NilResp3,
+			),
+		)
+
+		mustOK(t, c, "CLIENT", "TRACKING", "OFF")
+		mustDo(t, c, "GET", "foo", proto.NilResp3)
+		mustOK(t, c2, "SET", "foo", "bar")
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+	})
+
+	t.Run("bcast", func(t *testing.T) {
+		s, c := runWithClient(t)
+		useRESP3(t, c)
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "user:", "PREFIX", "session:")
+
+		s.Set("user:1", "alice")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("user:1"),
+			),
+		)
+		s.Set("other", "value")
+		s.Set("session:1", "abc")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("session:1"),
+			),
+		)
+		s.Del("user:1")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("user:1"),
+			),
+		)
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+	})
+
+	t.Run("noloop", func(t *testing.T) {
+		s, c := runWithClient(t)
+		useRESP3(t, c)
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "BCAST", "NOLOOP")
+		mustOK(t, c, "SET", "foo", "bar")
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+
+		s.Set("foo", "baz")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("foo"),
+			),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c,
+			"CLIENT", "TRACKING",
+			proto.Error("ERR wrong number of arguments for 'client|tracking' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "MAYBE",
+			proto.Error("ERR syntax error"),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "FOO",
+			proto.Error("ERR syntax error"),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "PREFIX", "foo",
+			proto.Error("ERR PREFIX option requires BCAST mode to be enabled"),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "OPTIN", "OPTOUT",
+			proto.Error("ERR You can't use both OPTIN and OPTOUT."),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "BCAST", "OPTIN",
+			proto.Error("ERR OPTIN and OPTOUT are not compatible with BCAST."),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foo", "PREFIX", "foobar",
+			proto.Error("ERR Prefix 'foo' overlaps with another provided prefix 'foobar'. Prefixes for a single client must not overlap."),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foo", "PREFIX", "foo",
+			proto.Error("ERR Prefix 'foo' overlaps with another provided prefix 'foo'. Prefixes for a single client must not overlap."),
+		)
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "REDIRECT", "12",
+			proto.Error("ERR The client ID you want redirect to does not exist"),
+		)
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON")
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "BCAST",
+			proto.Error("ERR You can't switch BCAST mode on/off before disabling tracking for this client, and then re-enabling it with a different mode."),
+		)
+		mustOK(t, c, "CLIENT", "TRACKING", "OFF")
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foo")
+		mustDo(t, c,
+			"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "bar", "PREFIX", "foobar",
+			proto.Error("ERR Prefix 'foobar' overlaps with an existing prefix 'foo'. Prefixes for a single client must not overlap."),
+		)
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "bar")
+	})
+}
+
+// Test CLIENT CACHING.
+func TestClientCaching(t *testing.T) {
+	t.Run("optin", func(t *testing.T) {
+		s, c := runWithClient(t)
+		useRESP3(t, c)
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "OPTIN")
+		mustDo(t, c, "GET", "notcached", proto.NilResp3)
+		mustOK(t, c, "CLIENT", "CACHING", "YES")
+		mustDo(t, c, "GET", "cached", proto.NilResp3)
+		// only the command right after CACHING YES is tracked
+		mustDo(t, c, "GET", "notcached2", proto.NilResp3)
+
+		s.Set("notcached", "foo")
+		s.Set("notcached2", "foo")
+		s.Set("cached", "foo")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("cached"),
+			),
+		)
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+	})
+
+	t.Run("optout", func(t *testing.T) {
+		s, c := runWithClient(t)
+		useRESP3(t, c)
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "OPTOUT")
+		mustOK(t, c, "CLIENT", "CACHING", "NO")
+		mustDo(t, c, "GET", "notcached", proto.NilResp3)
+		mustDo(t, c, "GET", "cached", proto.NilResp3)
+
+		s.Set("notcached", "foo")
+		s.Set("cached", "foo")
+		mustRead(t, c,
+			proto.Push(
+				proto.String("invalidate"),
+				proto.Strings("cached"),
+			),
+		)
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c,
+			"CLIENT", "CACHING",
+			proto.Error("ERR wrong number of arguments for 'client|caching' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "CACHING", "YES",
+			proto.Error("ERR CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled"),
+		)
+		mustOK(t, c, "CLIENT", "TRACKING", "ON")
+		mustDo(t, c,
+			"CLIENT", "CACHING", "YES",
+			proto.Error("ERR CLIENT CACHING can be called only when the client is in tracking mode with OPTIN or OPTOUT mode enabled"),
+		)
+		mustOK(t, c, "CLIENT", "TRACKING", "OFF")
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "OPTIN")
+		mustDo(t, c,
+			"CLIENT", "CACHING", "NO",
+			proto.Error("ERR CLIENT CACHING NO is only valid when tracking is enabled in OPTOUT mode."),
+		)
+		mustDo(t, c,
+			"CLIENT", "CACHING", "MAYBE",
+			proto.Error("ERR syntax error"),
+		)
+		mustOK(t, c, "CLIENT", "TRACKING", "OFF")
+
+		mustOK(t, c, "CLIENT", "TRACKING", "ON", "OPTOUT")
+		mustDo(t, c,
+			"CLIENT", "CACHING", "YES",
+			proto.Error("ERR CLIENT CACHING YES is only valid when tracking is enabled in OPTIN mode."),
+		)
+	})
+}
+
+// Test CLIENT PAUSE and CLIENT UNPAUSE.
+func TestClientPause(t *testing.T) {
+	t.Run("write", func(t *testing.T) {
+		s, c := runWithClient(t)
+		s.Set("foo", "bar")
+
+		mustOK(t, c, "CLIENT", "PAUSE", "10000", "WRITE")
+		// reads are fine
+		mustDo(t, c, "GET", "foo", proto.String("bar"))
+
+		set := goStrings(t, s, "SET", "foo", "baz")
+		time.Sleep(30 * time.Millisecond)
+		equals(t, 0, len(set))
+		s.CheckGet(t, "foo", "bar")
+
+		mustOK(t, c, "CLIENT", "UNPAUSE")
+		equals(t, proto.Inline("OK"), <-set)
+		s.CheckGet(t, "foo", "baz")
+	})
+
+	t.Run("blocking command started while paused", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		mustOK(t, c, "CLIENT", "PAUSE", "10000", "WRITE")
+		got := goStrings(t, s, "BLPOP", "l", "0")
+		push := goStrings(t, s, "LPUSH", "l", "aap")
+		time.Sleep(30 * time.Millisecond)
+		equals(t, 0, len(got))
+		equals(t, 0, len(push))
+		equals(t, false, s.Exists("l"))
+
+		mustOK(t, c, "CLIENT", "UNPAUSE")
+		equals(t, proto.Int(1), <-push)
+		equals(t, proto.Strings("l", "aap"), <-got)
+	})
+
+	t.Run("blocked before the pause", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		got := goStrings(t, s, "BLPOP", "l", "0")
+		time.Sleep(30 * time.Millisecond)
+		mustOK(t, c, "CLIENT", "PAUSE", "10000", "WRITE")
+		_, err := s.Push("l", "aap")
+		ok(t, err)
+		time.Sleep(30 * time.Millisecond)
+		equals(t, 0, len(got))
+
+		mustOK(t, c, "CLIENT", "UNPAUSE")
+		equals(t, proto.Strings("l", "aap"), <-got)
+		equals(t, false, s.Exists("l"))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		mustOK(t, c, "CLIENT", "PAUSE", "50")
+		get := goStrings(t, s, "GET", "foo")
+		time.Sleep(20 * time.Millisecond)
+		equals(t, 0, len(get))
+		equals(t, proto.Nil, <-get)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c,
+			"CLIENT", "PAUSE",
+			proto.Error("ERR wrong number of arguments for 'client|pause' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "PAUSE", "foo",
+			proto.Error("ERR timeout is not an integer or out of range"),
+		)
+		mustDo(t, c,
+			"CLIENT", "PAUSE", "-1",
+			proto.Error("ERR timeout is negative"),
+		)
+		mustDo(t, c,
+			"CLIENT", "PAUSE", "10", "READ",
+			proto.Error("ERR syntax error"),
+		)
+		mustDo(t, c,
+			"CLIENT", "PAUSE", "10", "WRITE", "ALL",
+			proto.Error("ERR wrong number of arguments for 'client|pause' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "UNPAUSE", "foo",
+			proto.Error("ERR wrong number of arguments for 'client|unpause' command"),
+		)
+	})
+}
+
+// Test CLIENT NO-TOUCH and CLIENT NO-EVICT.
+func TestClientNoTouch(t *testing.T) {
+	s, c := runWithClient(t)
+
+	start := time.Now()
+	s.SetTime(start)
+	mustOK(t, c, "SET", "foo", "bar")
+	s.SetTime(start.Add(time.Minute))
+
+	mustOK(t, c, "CLIENT", "NO-TOUCH", "ON")
+	mustDo(t, c, "GET", "foo", proto.String("bar"))
+	must1(t, c, "EXISTS", "foo")
+	mustDo(t, c, "OBJECT", "IDLETIME", "foo", proto.Int(60))
+
+	// other connections still touch
+	c2, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c2.Close()
+	mustDo(t, c2, "GET", "foo", proto.String("bar"))
+	mustDo(t, c, "OBJECT", "IDLETIME", "foo", proto.Int(0))
+
+	// TOUCH always touches
+	s.SetTime(start.Add(2 * time.Minute))
+	must1(t, c, "TOUCH", "foo")
+	mustDo(t, c, "OBJECT", "IDLETIME", "foo", proto.Int(0))
+
+	s.SetTime(start.Add(3 * time.Minute))
+	mustOK(t, c, "CLIENT", "NO-TOUCH", "OFF")
+	mustDo(t, c, "GET", "foo", proto.String("bar"))
+	mustDo(t, c, "OBJECT", "IDLETIME", "foo", proto.Int(0))
+
+	mustOK(t, c, "CLIENT", "NO-EVICT", "ON")
+	mustOK(t, c, "CLIENT", "NO-EVICT", "off")
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"CLIENT", "NO-TOUCH",
+			proto.Error("ERR wrong number of arguments for 'client|no-touch' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "NO-TOUCH", "MAYBE",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"CLIENT", "NO-EVICT",
+			proto.Error("ERR wrong number of arguments for 'client|no-evict' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "NO-EVICT", "ON", "OFF",
+			proto.Error("ERR wrong number of arguments for 'client|no-evict' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "NO-EVICT", "MAYBE",
+			proto.Error(msgSyntaxError),
+		)
+	})
+}
+
+// Test CLIENT ID, CLIENT INFO, CLIENT LIST, and CLIENT SETINFO.
+func TestClientList(t *testing.T) {
+	s, c := runWithClient(t)
+
+	// clientFields reads the "k=v k=v" lines from CLIENT LIST and CLIENT INFO
+	clientFields := func(t *testing.T, c *proto.Client, args ...string) []map[string]string {
+		t.Helper()
+		res, err := c.Do(args...)
+		ok(t, err)
+		str, err := proto.ReadString(res)
+		ok(t, err)
+		var lines []map[string]string
+		for _, l := range strings.Split(strings.TrimSuffix(str, "\n"), "\n") {
+			fs := map[string]string{}
+			for _, f := range strings.Split(l, " ") {
+				kv := strings.SplitN(f, "=", 2)
+				equals(t, 2, len(kv))
+				fs[kv[0]] = kv[1]
+			}
+			lines = append(lines, fs)
+		}
+		return lines
+	}
+
+	mustDo(t, c, "CLIENT", "ID", proto.Int(1))
+	mustOK(t, c, "CLIENT", "SETNAME", "aap")
+	mustOK(t, c, "CLIENT", "SETINFO", "LIB-NAME", "go-redis")
+	mustOK(t, c, "CLIENT", "SETINFO", "lib-ver", "9.0.0")
+	mustOK(t, c, "SELECT", "3")
+
+	info := clientFields(t, c, "CLIENT", "INFO")
+	equals(t, 1, len(info))
+	equals(t, "1", info[0]["id"])
+	equals(t, "aap", info[0]["name"])
+	equals(t, "3", info[0]["db"])
+	equals(t, "2", info[0]["resp"])
+	equals(t, "go-redis", info[0]["lib-name"])
+	equals(t, "9.0.0", info[0]["lib-ver"])
+	equals(t, s.Addr(), info[0]["laddr"])
+	assert(t, info[0]["addr"] != "", "addr")
+
+	c2, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c2.Close()
+	mustDo(t, c2, "CLIENT", "ID", proto.Int(2))
+	mustContain(t, c2, "HELLO", "3", "proto")
+
+	list := clientFields(t, c, "CLIENT", "LIST")
+	equals(t, 2, len(list))
+	equals(t, info[0], list[0])
+	equals(t, "2", list[1]["id"])
+	equals(t, "", list[1]["name"])
+	equals(t, "0", list[1]["db"])
+	equals(t, "3", list[1]["resp"])
+	equals(t, "", list[1]["lib-name"])
+
+	list = clientFields(t, c, "CLIENT", "LIST", "ID", "2", "99")
+	equals(t, 1, len(list))
+	equals(t, "2", list[0]["id"])
+
+	list = clientFields(t, c, "CLIENT", "LIST", "TYPE", "normal")
+	equals(t, 2, len(list))
+	mustDo(t, c, "CLIENT", "LIST", "TYPE", "pubsub", proto.String(""))
+
+	// what go-redis v9 sends on connect
+	c3, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c3.Close()
+	mustOK(t, c3, "CLIENT", "SETINFO", "LIB-NAME", "go-redis(,go1.21.0)")
+	mustOK(t, c3, "CLIENT", "SETINFO", "LIB-VER", "9.2.1")
+	list = clientFields(t, c, "CLIENT", "LIST", "ID", "3")
+	equals(t, "go-redis(,go1.21.0)", list[0]["lib-name"])
+	equals(t, "9.2.1", list[0]["lib-ver"])
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"CLIENT", "ID", "foo",
+			proto.Error("ERR wrong number of arguments for 'client|id' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "INFO", "foo",
+			proto.Error("ERR wrong number of arguments for 'client|info' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "LIST", "ID", "foo",
+			proto.Error("ERR Invalid client ID"),
+		)
+		mustDo(t, c,
+			"CLIENT", "LIST", "TYPE", "foo",
+			proto.Error("ERR Unknown client type 'foo'"),
+		)
+		mustDo(t, c,
+			"CLIENT", "LIST", "foo",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"CLIENT", "SETINFO", "LIB-NAME",
+			proto.Error("ERR wrong number of arguments for 'client|setinfo' command"),
+		)
+		mustDo(t, c,
+			"CLIENT", "SETINFO", "foo", "bar",
+			proto.Error("ERR Unrecognized option 'foo'"),
+		)
+		mustDo(t, c,
+			"CLIENT", "SETINFO", "LIB-NAME", "go redis",
+			proto.Error("ERR lib-name cannot contain spaces, newlines or special characters."),
+		)
+		mustDo(t, c,
+			"CLIENT", "SETINFO", "LIB-VER", "9\x01",
+			proto.Error("ERR lib-ver cannot contain spaces, newlines or special characters."),
+		)
+		mustDo(t, c,
+			"CLIENT", "SETNAME", "caf\u00e9",
+			proto.Error("ERR Client names cannot contain spaces, newlines or special characters."),
+		)
+	})
+
+	t.Run("concurrent HELLO", func(t *testing.T) {
+		// for the race detector: c2 switches protocols while c lists it
+		c2, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c2.Close()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 20; i++ {
+				c2.Do("HELLO", "3")
+				c2.Do("HELLO", "2")
+			}
+		}()
+		for i := 0; i < 20; i++ {
+			_, err := c.Do("CLIENT", "LIST")
+			ok(t, err)
+		}
+		<-done
+	})
+}
@@ -0,0 +1,111 @@
+// Commands from https://redis.io/commands#server
+
+package miniredis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+// commandsConfig handles CONFIG operations.
+func commandsConfig(m *Miniredis) {
+	m.srv.Register("CONFIG", m.cmdConfig)
+}
+
+// CONFIG
+func (m *Miniredis) cmdConfig(c *server.Peer, cmd string, args []string) {
+	if len(args) == 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	switch sub := strings.ToUpper(args[0]); sub {
+	case "GET":
+		m.cmdConfigGet(c, args[1:])
+	case "SET":
+		m.cmdConfigSet(c, args[1:])
+	default:
+		setDirty(c)
+		c.WriteError(fmt.Sprintf("ERR unknown subcommand '%s'. Try CONFIG HELP.", args[0]))
+	}
+}
+
+// CONFIG GET
+func (m *Miniredis) cmdConfigGet(c *server.Peer, args []string) {
+	if len(args) < 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("config|get"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		found := map[string]struct{}{}
+		for _, pat := range args {
+			for _, k := range m.configKeys(strings.ToLower(pat)) {
+				found[k] = struct{}{}
+			}
+		}
+		keys := make([]string, 0, len(found))
+		for k := range found {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		c.WriteMapLen(len(keys))
+		for _, k := range keys {
+			c.WriteBulk(k)
+			c.WriteBulk(m.configValue(k))
+		}
+	})
+}
+
+// CONFIG SET
+func (m *Miniredis) cmdConfigSet(c *server.Peer, args []string) {
+	if len(args) < 2 || len(args)%2 != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("config|set"))
+		return
+	}
+
+	values := map[string]string{}
+	for len(args) > 0 {
+		k, v := strings.ToLower(args[0]), args[1]
+		args = args[2:]
+		p, ok := configParams[k]
+		if !ok {
+			setDirty(c)
+			c.WriteError(fmt.Sprintf("ERR Unknown option or number of arguments for CONFIG SET - '%s'", k))
+			return
+		}
+		if _, ok := values[k]; ok {
+			setDirty(c)
+			c.WriteError(fmt.Sprintf("ERR CONFIG SET failed (possibly related to argument '%s') - duplicate parameter", k))
+			return
+		}
+		if p.check != nil {
+			if err := p.check(v); err != nil {
+				setDirty(c)
+				c.WriteError(fmt.Sprintf("ERR CONFIG SET failed (possibly related to argument '%s') - %s", k, err))
+				return
+			}
+		}
+		values[k] = v
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		for k, v := range values {
+			m.setConfigValue(k, v)
+		}
+		c.WriteOK()
+	})
+}
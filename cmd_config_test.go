@@ -0,0 +1,167 @@
+package miniredis
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+// Test CONFIG GET and CONFIG SET.
+func TestConfig(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"CONFIG", "GET", "set-max-intset-entries",
+		proto.Strings("set-max-intset-entries", "512"),
+	)
+	mustDo(t, c,
+		"CONFIG", "GET", "set-max-listpack-*",
+		proto.Strings(
+			"set-max-listpack-entries", "128",
+			"set-max-listpack-value", "64",
+		),
+	)
+	mustDo(t, c,
+		"CONFIG", "GET", "nosuch",
+		proto.Strings(),
+	)
+
+	mustOK(t, c, "CONFIG", "SET", "set-max-intset-entries", "12", "SET-MAX-LISTPACK-VALUE", "13")
+	mustDo(t, c,
+		"CONFIG", "GET", "set-max-intset-entries", "set-max-listpack-value",
+		proto.Strings(
+			"set-max-intset-entries", "12",
+			"set-max-listpack-value", "13",
+		),
+	)
+
+	t.Run("RESP3", func(t *testing.T) {
+		useRESP3(t, c)
+		mustDo(t, c,
+			"CONFIG", "GET", "set-max-intset-entries",
+			proto.StringMap("set-max-intset-entries", "12"),
+		)
+	})
+
+	t.Run("requirepass", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		mustDo(t, c,
+			"CONFIG", "GET", "requirepass",
+			proto.Strings("requirepass", ""),
+		)
+		mustOK(t, c, "CONFIG", "SET", "requirepass", "secret")
+		mustDo(t, c,
+			"CONFIG", "GET", "requirepass",
+			proto.Strings("requirepass", "secret"),
+		)
+
+		c2, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c2.Close()
+		mustDo(t, c2,
+			"GET", "foo",
+			proto.Error("NOAUTH Authentication required."),
+		)
+		mustDo(t, c2,
+			"AUTH", "wrong",
+			proto.Error("WRONGPASS invalid username-password pair"),
+		)
+		mustOK(t, c2, "AUTH", "secret")
+		mustNil(t, c2, "GET", "foo")
+
+		s.SetPassword("other")
+		mustDo(t, c2,
+			"CONFIG", "GET", "requirepass",
+			proto.Strings("requirepass", "other"),
+		)
+
+		mustOK(t, c2, "CONFIG", "SET", "requirepass", "")
+		mustDo(t, c2,
+			"CONFIG", "GET", "requirepass",
+			proto.Strings("requirepass", ""),
+		)
+		c3, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c3.Close()
+		mustNil(t, c3, "GET", "foo")
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c,
+			"CONFIG", "GET", "maxmemory-policy",
+			proto.Strings("maxmemory-policy", "noeviction"),
+		)
+		mustDo(t, c,
+			"CONFIG", "GET", "save", "appendonly", "appendfsync", "timeout", "tcp-keepalive", "databases",
+			proto.Strings(
+				"appendfsync", "everysec",
+				"appendonly", "no",
+				"databases", "16",
+				"save", "3600 1 300 100 60 10000",
+				"tcp-keepalive", "300",
+				"timeout", "0",
+			),
+		)
+
+		mustOK(t, c, "CONFIG", "SET", "save", "", "appendonly", "yes", "appendfsync", "always")
+		mustDo(t, c,
+			"CONFIG", "GET", "append*", "save",
+			proto.Strings(
+				"appendfsync", "always",
+				"appendonly", "yes",
+				"save", "",
+			),
+		)
+
+		mustDo(t, c,
+			"CONFIG", "SET", "databases", "12",
+			proto.Error("ERR CONFIG SET failed (possibly related to argument 'databases') - can't set immutable config"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "appendonly", "maybe",
+			proto.Error("ERR CONFIG SET failed (possibly related to argument 'appendonly') - argument must be 'yes' or 'no'"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "appendfsync", "never",
+			proto.Error("ERR CONFIG SET failed (possibly related to argument 'appendfsync') - argument(s) must be one of the following: always, everysec, no"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "save", "3600",
+			proto.Error("ERR CONFIG SET failed (possibly related to argument 'save') - Invalid save parameters"),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"CONFIG",
+			proto.Error("ERR wrong number of arguments for 'config' command"),
+		)
+		mustDo(t, c,
+			"CONFIG", "FOO",
+			proto.Error("ERR unknown subcommand 'FOO'. Try CONFIG HELP."),
+		)
+		mustDo(t, c,
+			"CONFIG", "GET",
+			proto.Error("ERR wrong number of arguments for 'config|get' command"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "set-max-intset-entries",
+			proto.Error("ERR wrong number of arguments for 'config|set' command"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "nosuch", "1",
+			proto.Error("ERR Unknown option or number of arguments for CONFIG SET - 'nosuch'"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "set-max-intset-entries", "foo",
+			proto.Error("ERR CONFIG SET failed (possibly related to argument 'set-max-intset-entries') - argument couldn't be parsed into an integer"),
+		)
+		mustDo(t, c,
+			"CONFIG", "SET", "set-max-intset-entries", "1", "set-max-intset-entries", "2",
+			proto.Error("ERR CONFIG SET failed (possibly related to argument 'set-max-intset-entries') - duplicate parameter"),
+		)
+	})
+}
@@ -5,6 +5,7 @@ package miniredis
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/server"
 )
@@ -87,17 +88,17 @@ func (m *Miniredis) cmdAuth(c *server.Peer, cmd string, args []string) {
 		opts.username, opts.password = args[0], args[1]
 	}
 
+	var delay time.Duration
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		if len(m.passwords) == 0 && opts.username == "default" {
 			c.WriteError("ERR AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
 			return
 		}
 		setPW, ok := m.passwords[opts.username]
-		if !ok {
-			c.WriteError("WRONGPASS invalid username-password pair")
-			return
-		}
-		if setPW != opts.password {
+		if !ok || setPW != opts.password {
+			m.authFailures++
+			ctx.authFailures++
+			delay = m.authFailDelay
 			c.WriteError("WRONGPASS invalid username-password pair")
 			return
 		}
@@ -105,25 +106,30 @@ func (m *Miniredis) cmdAuth(c *server.Peer, cmd string, args []string) {
 		ctx.authenticated = true
 		c.WriteOK()
 	})
+	// not while we have the lock. The reply is only sent after this.
+	time.Sleep(delay)
 }
 
 // HELLO
 func (m *Miniredis) cmdHello(c *server.Peer, cmd string, args []string) {
-	if len(args) < 1 {
-		c.WriteError(errWrongNumber(cmd))
-		return
-	}
-
 	var opts struct {
 		version  int
 		username string
 		password string
+		setName  bool
+		name     string
 	}
 
-	if ok := optIntErr(c, args[0], &opts.version, "ERR Protocol version is not an integer or out of range"); !ok {
-		return
+	opts.version = 2
+	if c.Resp3 {
+		opts.version = 3
+	}
+	if len(args) > 0 {
+		if ok := optIntErr(c, args[0], &opts.version, "ERR Protocol version is not an integer or out of range"); !ok {
+			return
+		}
+		args = args[1:]
 	}
-	args = args[1:]
 
 	switch opts.version {
 	case 2, 3:
@@ -147,45 +153,68 @@ func (m *Miniredis) cmdHello(c *server.Peer, cmd string, args []string) {
 				c.WriteError(fmt.Sprintf("ERR Syntax error in HELLO option '%s'", args[0]))
 				return
 			}
-			_, args = args[1], args[2:]
+			opts.setName, opts.name, args = true, args[1], args[2:]
+			if strings.ContainsAny(opts.name, " \n") {
+				c.WriteError("ERR Client names cannot contain spaces, newlines or special characters.")
+				return
+			}
 		default:
 			c.WriteError(fmt.Sprintf("ERR Syntax error in HELLO option '%s'", args[0]))
 			return
 		}
 	}
 
+	ctx := getCtx(c)
+	if ctx.nested {
+		// not allowed in Redis either, and we already have the lock
+		c.WriteError(msgNotFromScripts(ctx.nestedSHA))
+		return
+	}
+
+	m.Lock()
 	if len(m.passwords) == 0 && opts.username == "default" {
 		// redis ignores legacy "AUTH" if it's not enabled.
 		checkAuth = false
 	}
 	if checkAuth {
 		setPW, ok := m.passwords[opts.username]
-		if !ok {
-			c.WriteError("WRONGPASS invalid username-password pair")
-			return
-		}
-		if setPW != opts.password {
+		if !ok || setPW != opts.password {
+			m.authFailures++
+			ctx.authFailures++
+			delay := m.authFailDelay
+			m.Unlock()
+			time.Sleep(delay)
 			c.WriteError("WRONGPASS invalid username-password pair")
 			return
 		}
-		getCtx(c).authenticated = true
+		ctx.authenticated = true
 	}
-
-	c.Resp3 = opts.version == 3
+	if !m.isAuthenticated(ctx) {
+		m.Unlock()
+		c.WriteError("NOAUTH HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time")
+		return
+	}
+	role := m.currentRole()
+	// under the lock, since CLIENT LIST reads these
+	if opts.setName {
+		c.ClientName = opts.name
+	}
+	c.SetResp3(opts.version == 3)
+	m.Unlock()
 
 	c.WriteMapLen(7)
 	c.WriteBulk("server")
 	c.WriteBulk("miniredis")
 	c.WriteBulk("version")
-	c.WriteBulk("6.0.5")
+	c.WriteBulk(redisVersion)
 	c.WriteBulk("proto")
 	c.WriteInt(opts.version)
 	c.WriteBulk("id")
-	c.WriteInt(42)
+	c.WriteInt(c.ID)
 	c.WriteBulk("mode")
 	c.WriteBulk("standalone")
 	c.WriteBulk("role")
-	c.WriteBulk("master")
+	c.WriteBulk(role)
 	c.WriteBulk("modules")
 	c.WriteLen(0)
 }
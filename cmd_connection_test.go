@@ -2,6 +2,7 @@ package miniredis
 
 import (
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/proto"
 )
@@ -60,6 +61,131 @@ func TestAuth(t *testing.T) {
 		)
 	})
 
+	t.Run("SetPassword", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+
+		s.SetPassword("secret")
+		// already connected, keeps working
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+
+		c2, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c2.Close()
+		mustDo(t, c2,
+			"PING",
+			proto.Error("NOAUTH Authentication required."),
+		)
+		mustDo(t, c2,
+			"AUTH", "secret",
+			proto.Inline("OK"),
+		)
+		mustDo(t, c2, "PING", proto.Inline("PONG"))
+
+		// a new password doesn't affect authenticated connections
+		s.SetPassword("other")
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+		mustDo(t, c2, "PING", proto.Inline("PONG"))
+
+		c3, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c3.Close()
+		mustDo(t, c3,
+			"AUTH", "secret",
+			proto.Error("WRONGPASS invalid username-password pair"),
+		)
+		mustDo(t, c3,
+			"AUTH", "other",
+			proto.Inline("OK"),
+		)
+
+		// disabled
+		s.SetPassword("")
+		c4, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c4.Close()
+		mustDo(t, c4, "PING", proto.Inline("PONG"))
+
+		// RequireAuth() still locks out everyone
+		s.RequireAuth("secret")
+		mustDo(t, c,
+			"PING",
+			proto.Error("NOAUTH Authentication required."),
+		)
+		mustDo(t, c2, "PING", proto.Inline("PONG"))
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		s.RequireAuth("secret")
+		for i := 0; i < 3; i++ {
+			mustDo(t, c,
+				"AUTH", "wrong",
+				proto.Error("WRONGPASS invalid username-password pair"),
+			)
+		}
+		mustDo(t, c,
+			"AUTH", "nosuch", "secret",
+			proto.Error("WRONGPASS invalid username-password pair"),
+		)
+		mustContain(t, c,
+			"HELLO", "3", "AUTH", "default", "wrong",
+			"WRONGPASS",
+		)
+		equals(t, 5, s.AuthFailures())
+		mustDo(t, c, "CLIENT", "ID", proto.Int(1))
+		equals(t, 5, s.ClientAuthFailures(1))
+		equals(t, 0, s.ClientAuthFailures(100))
+
+		mustOK(t, c, "AUTH", "secret")
+		equals(t, 5, s.AuthFailures())
+		mustDo(t, c,
+			"INFO", "STATS",
+			proto.String("# Stats\nevicted_keys:0\r\nacl_access_denied_auth:5\r\n"),
+		)
+
+		s.SetAuthFailDelay(50 * time.Millisecond)
+		start := time.Now()
+		mustDo(t, c,
+			"AUTH", "wrong",
+			proto.Error("WRONGPASS invalid username-password pair"),
+		)
+		assert(t, time.Since(start) >= 50*time.Millisecond, "no delay")
+		equals(t, 6, s.AuthFailures())
+
+		start = time.Now()
+		mustContain(t, c,
+			"HELLO", "3", "AUTH", "default", "wrong",
+			"WRONGPASS",
+		)
+		assert(t, time.Since(start) >= 50*time.Millisecond, "no HELLO delay")
+		equals(t, 7, s.AuthFailures())
+		equals(t, 7, s.ClientAuthFailures(1))
+
+		// counted per connection
+		c3, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c3.Close()
+		mustContain(t, c3, "AUTH", "wrong", "WRONGPASS")
+		equals(t, 8, s.AuthFailures())
+		equals(t, 7, s.ClientAuthFailures(1))
+
+		// the server isn't blocked while waiting
+		c2, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c2.Close()
+		got := make(chan error, 1)
+		go func() {
+			_, err := c2.Do("AUTH", "wrong")
+			got <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+		ok(t, <-got)
+	})
+
 	t.Run("error cases", func(t *testing.T) {
 		_, c := runWithClient(t)
 
@@ -233,6 +359,94 @@ func TestSetError(t *testing.T) {
 	)
 }
 
+func TestDisableCommand(t *testing.T) {
+	s, c := runWithClient(t)
+
+	s.Set("foo", "bar")
+	s.DisableCommand("flushall")
+	mustContain(t, c,
+		"FLUSHALL",
+		"ERR unknown command `FLUSHALL`",
+	)
+	mustDo(t, c,
+		"GET", "foo",
+		proto.String("bar"),
+	)
+
+	t.Run("multi", func(t *testing.T) {
+		mustOK(t, c, "MULTI")
+		mustContain(t, c, "FLUSHALL", "ERR unknown command")
+		mustDo(t, c,
+			"EXEC",
+			proto.Error("EXECABORT Transaction discarded because of previous errors."),
+		)
+	})
+
+	t.Run("lua", func(t *testing.T) {
+		mustContain(t, c,
+			"EVAL", "return redis.call('FLUSHALL')", "0",
+			"Unknown Redis command called from script",
+		)
+	})
+
+	s.EnableCommand("FLUSHALL")
+	mustOK(t, c, "FLUSHALL")
+	equals(t, false, s.Exists("foo"))
+}
+
+func TestRenameCommand(t *testing.T) {
+	s, c := runWithClient(t)
+
+	ok(t, s.RenameCommand("CONFIG", "b840fc02d524045429941cc15f59e41cb7be6c52"))
+	mustContain(t, c,
+		"CONFIG", "GET", "set-max-intset-entries",
+		"ERR unknown command `CONFIG`",
+	)
+	mustDo(t, c,
+		"b840fc02d524045429941cc15f59e41cb7be6c52", "GET", "set-max-intset-entries",
+		proto.Strings("set-max-intset-entries", "512"),
+	)
+
+	ok(t, s.RenameCommand("flushall", ""))
+	mustContain(t, c,
+		"FLUSHALL",
+		"ERR unknown command",
+	)
+
+	t.Run("errors", func(t *testing.T) {
+		assert(t, s.RenameCommand("nosuch", "foo") != nil, "rename of unknown command")
+		assert(t, s.RenameCommand("CONFIG", "foo") != nil, "rename of renamed command")
+		assert(t, s.RenameCommand("GET", "SET") != nil, "rename onto existing command")
+		mustNil(t, c, "GET", "nosuch")
+		mustOK(t, c, "SET", "foo", "bar")
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		s.Close()
+		ok(t, s.Restart())
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c.Close()
+
+		mustContain(t, c,
+			"CONFIG", "GET", "set-max-intset-entries",
+			"ERR unknown command",
+		)
+		mustDo(t, c,
+			"b840fc02d524045429941cc15f59e41cb7be6c52", "GET", "set-max-intset-entries",
+			proto.Strings("set-max-intset-entries", "512"),
+		)
+		// failed renames are not replayed
+		mustNil(t, c, "GET", "nosuch")
+	})
+
+	t.Run("before start", func(t *testing.T) {
+		m := NewMiniRedis()
+		ok(t, m.RenameCommand("nosuch", "foo"))
+		assert(t, m.Start() != nil, "start with unknown rename")
+	})
+}
+
 func TestHello(t *testing.T) {
 	t.Run("default user", func(t *testing.T) {
 		s, c := runWithClient(t)
@@ -241,7 +455,7 @@ func TestHello(t *testing.T) {
 			proto.String("server"), proto.String("miniredis"),
 			proto.String("version"), proto.String("6.0.5"),
 			proto.String("proto"), proto.Int(3),
-			proto.String("id"), proto.Int(42),
+			proto.String("id"), proto.Int(1),
 			proto.String("mode"), proto.String("standalone"),
 			proto.String("role"), proto.String("master"),
 			proto.String("modules"), proto.Array(),
@@ -267,10 +481,6 @@ func TestHello(t *testing.T) {
 		)
 
 		t.Run("errors", func(t *testing.T) {
-			mustDo(t, c,
-				"HELLO",
-				proto.Error(errWrongNumber("HELLO")),
-			)
 			mustDo(t, c,
 				"HELLO", "foo",
 				proto.Error("ERR Protocol version is not an integer or out of range"),
@@ -283,6 +493,104 @@ func TestHello(t *testing.T) {
 				"HELLO", "3", "AUTH", "foo", "bar", "SETNAME",
 				proto.Error("ERR Syntax error in HELLO option 'SETNAME'"),
 			)
+			mustDo(t, c,
+				"HELLO", "3", "SETNAME", "santa claus",
+				proto.Error("ERR Client names cannot contain spaces, newlines or special characters."),
+			)
+			mustDo(t, c,
+				"HELLO", "4",
+				proto.Error("NOPROTO unsupported protocol version"),
+			)
 		})
 	})
+
+	t.Run("no version", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		payl := func(version int) string {
+			return proto.Map(
+				proto.String("server"), proto.String("miniredis"),
+				proto.String("version"), proto.String("6.0.5"),
+				proto.String("proto"), proto.Int(version),
+				proto.String("id"), proto.Int(1),
+				proto.String("mode"), proto.String("standalone"),
+				proto.String("role"), proto.String("master"),
+				proto.String("modules"), proto.Array(),
+			)
+		}
+		// RESP2 "maps" are arrays
+		mustDo(t, c,
+			"HELLO",
+			proto.Array(
+				proto.String("server"), proto.String("miniredis"),
+				proto.String("version"), proto.String("6.0.5"),
+				proto.String("proto"), proto.Int(2),
+				proto.String("id"), proto.Int(1),
+				proto.String("mode"), proto.String("standalone"),
+				proto.String("role"), proto.String("master"),
+				proto.String("modules"), proto.Array(),
+			),
+		)
+		mustDo(t, c, "HELLO", "3", payl(3))
+		mustDo(t, c, "HELLO", payl(3))
+	})
+
+	t.Run("SETNAME", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustContain(t, c, "HELLO", "2", "SETNAME", "santa", "miniredis")
+		mustDo(t, c,
+			"CLIENT", "GETNAME",
+			proto.String("santa"),
+		)
+	})
+
+	t.Run("script", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustContain(t, c,
+			"EVAL", "return redis.call('HELLO')", "0",
+			"This Redis command is not allowed from script",
+		)
+		mustContain(t, c,
+			"EVAL", "return redis.call('HELLO', '3', 'AUTH', 'default', 'wrong')", "0",
+			"This Redis command is not allowed from script",
+		)
+		mustContain(t, c, "HELLO", "miniredis")
+	})
+
+	t.Run("NOAUTH", func(t *testing.T) {
+		s, c := runWithClient(t)
+		s.RequireAuth("secret")
+
+		mustDo(t, c,
+			"HELLO", "3",
+			proto.Error("NOAUTH HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time"),
+		)
+		mustDo(t, c,
+			"HELLO", "3", "AUTH", "default", "wrong",
+			proto.Error("WRONGPASS invalid username-password pair"),
+		)
+		// still RESP2
+		mustDo(t, c,
+			"PING",
+			proto.Error("NOAUTH Authentication required."),
+		)
+		mustContain(t, c, "HELLO", "3", "AUTH", "default", "secret", "miniredis")
+		mustDo(t, c, "PING", proto.Inline("PONG"))
+	})
+
+	t.Run("pubsub", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		mustContain(t, c, "HELLO", "3", "miniredis")
+		mustDo(t, c,
+			"SUBSCRIBE", "news",
+			proto.Push(proto.String("subscribe"), proto.String("news"), proto.Int(1)),
+		)
+		s.Publish("news", "hello")
+		mustRead(t, c,
+			proto.Push(proto.String("message"), proto.String("news"), proto.String("hello")),
+		)
+	})
 }
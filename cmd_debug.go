@@ -0,0 +1,241 @@
+package miniredis
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+// commandsDebug handles DEBUG operations.
+func commandsDebug(m *Miniredis) {
+	m.srv.Register("DEBUG", m.cmdDebug)
+}
+
+// DEBUG
+func (m *Miniredis) cmdDebug(c *server.Peer, cmd string, args []string) {
+	if len(args) == 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	switch sub := strings.ToUpper(args[0]); {
+	case sub == "OBJECT" && len(args) == 2:
+		m.cmdDebugObject(c, args[1])
+	case sub == "STRINGMATCH-LEN" && (len(args) == 1 || len(args) == 3):
+		m.cmdDebugStringmatchLen(c, args[1:])
+	case sub == "QUICKLIST-PACKED-THRESHOLD" && len(args) == 2:
+		m.cmdDebugQuicklistPackedThreshold(c, args[1])
+	case sub == "LISTPACK" && len(args) == 2:
+		m.cmdDebugListpack(c, args[1])
+	default:
+		setDirty(c)
+		c.WriteError(fmt.Sprintf(msgFDebugUsage, args[0]))
+	}
+}
+
+// DEBUG OBJECT
+func (m *Miniredis) cmdDebugObject(c *server.Peer, key string) {
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		if _, ok := db.keys[key]; !ok {
+			c.WriteError(msgKeyNotFound)
+			return
+		}
+
+		lru := db.lru[key]
+		c.WriteInline(fmt.Sprintf(
+			"Value at:%s refcount:%d encoding:%s serializedlength:%d lru:%d lru_seconds_idle:%d%s",
+			fakeAddress(db.id, key),
+			db.refcount(key),
+			db.encoding(key),
+			db.serializedLength(key),
+			lru.Unix()&(1<<24-1),
+			int(m.effectiveNow().Sub(lru).Seconds()),
+			db.debugEncodingDetails(key),
+		))
+	})
+}
+
+// DEBUG STRINGMATCH-LEN [pattern string]
+// Without arguments redis runs a fuzz test of its matcher. With a pattern and
+// a string it's ours: it gives 1 if the string matches the glob pattern, the
+// same way KEYS and SCAN match.
+func (m *Miniredis) cmdDebugStringmatchLen(c *server.Peer, args []string) {
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		if len(args) == 0 {
+			c.WriteInline("Apparently Redis did not crash: test passed")
+			return
+		}
+		pattern, str := args[0], args[1]
+		if re := patternRE(pattern); re != nil && re.MatchString(str) {
+			c.WriteInt(1)
+			return
+		}
+		c.WriteInt(0)
+	})
+}
+
+// defaultPackedThreshold is the default DEBUG QUICKLIST-PACKED-THRESHOLD,
+// 1GB.
+const defaultPackedThreshold = 1 << 30
+
+// DEBUG QUICKLIST-PACKED-THRESHOLD size
+// List elements of at least this size are stored in a "plain" quicklist node
+// of their own. 0 sets the default again.
+func (m *Miniredis) cmdDebugQuicklistPackedThreshold(c *server.Peer, arg string) {
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		sz, ok := parseMemory(arg)
+		if !ok || sz > 1<<32-1<<20 {
+			c.WriteError(msgPackedThreshold)
+			return
+		}
+		if sz == 0 {
+			sz = defaultPackedThreshold
+		}
+		m.qlThreshold = int(sz)
+		c.WriteOK()
+	})
+}
+
+// DEBUG LISTPACK key
+// Redis prints the listpack on its stdout, we don't.
+func (m *Miniredis) cmdDebugListpack(c *server.Peer, key string) {
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		if _, ok := db.keys[key]; !ok {
+			c.WriteError(msgKeyNotFound)
+			return
+		}
+		if db.encoding(key) != "listpack" {
+			c.WriteError(msgNotListpack)
+			return
+		}
+		c.WriteInline("Listpack structure printed on stdout")
+	})
+}
+
+// parseMemory parses a memory size the way Redis does in its config: a
+// number with an optional unit ("b", "k", "kb", "m", "mb", "g", "gb").
+func parseMemory(s string) (uint64, bool) {
+	units := []struct {
+		suffix string
+		mul    uint64
+	}{
+		{"kb", 1 << 10},
+		{"mb", 1 << 20},
+		{"gb", 1 << 30},
+		{"b", 1},
+		{"k", 1000},
+		{"m", 1000 * 1000},
+		{"g", 1000 * 1000 * 1000},
+	}
+	s = strings.ToLower(s)
+	mul := uint64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mul = strings.TrimSuffix(s, u.suffix), u.mul
+			break
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n > math.MaxUint64/mul {
+		return 0, false
+	}
+	return n * mul, true
+}
+
+// fakeAddress makes up a memory address for DEBUG OBJECT. It's stable for a
+// given key.
+func fakeAddress(db int, key string) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%s", db, key)
+	return fmt.Sprintf("0x7f%010x", h.Sum32())
+}
+
+// debugEncodingDetails gives the encoding specific DEBUG OBJECT fields, with
+// a leading space, or "".
+func (db *RedisDB) debugEncodingDetails(k string) string {
+	switch db.encoding(k) {
+	case "skiplist":
+		return fmt.Sprintf(" zsl_level:%d", skiplistLevel(len(db.sortedsetKeys[k])))
+	case "quicklist":
+		var (
+			nodes = db.quicklistNodes(k)
+			list  = db.listKeys[k]
+			size  = 0
+		)
+		for _, n := range nodes {
+			size += listpackBytes(list[:n])
+			list = list[n:]
+		}
+		return fmt.Sprintf(
+			" ql_nodes:%d ql_avg_node:%.2f ql_listpack_max:%d ql_compressed:0 ql_uncompressed_size:%d",
+			len(nodes),
+			float64(len(db.listKeys[k]))/float64(len(nodes)),
+			db.master.configInt("list-max-listpack-size"),
+			size,
+		)
+	default:
+		return ""
+	}
+}
+
+// skiplistLevel is the expected height of a Redis skiplist with n elements.
+// Redis picks levels randomly, with a 1/4 chance to go up a level, so we
+// use the typical value instead.
+func skiplistLevel(n int) int {
+	level := 1
+	for n >= 4 && level < 32 {
+		n /= 4
+		level++
+	}
+	return level
+}
+
+// serializedLength is a rough estimate of the size of a key in an RDB file.
+func (db *RedisDB) serializedLength(k string) int {
+	n := 0
+	switch db.t(k) {
+	case "string":
+		n = len(db.stringKeys[k])
+	case "hll":
+		n = len(db.hllKeys[k].Bytes())
+	case "hash":
+		for f, v := range db.hashKeys[k] {
+			n += len(f) + len(v)
+		}
+	case "list":
+		for _, v := range db.listKeys[k] {
+			n += len(v)
+		}
+	case "set":
+		for v := range db.setKeys[k] {
+			n += len(v)
+		}
+	case "zset":
+		for v := range db.sortedsetKeys[k] {
+			n += len(v) + 8
+		}
+	case "stream":
+		for _, e := range db.streamKeys[k].entries {
+			n += len(e.ID)
+			for _, v := range e.Values {
+				n += len(v)
+			}
+		}
+	}
+	return n
+}
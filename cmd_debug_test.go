@@ -0,0 +1,215 @@
+package miniredis
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+// Test DEBUG OBJECT.
+func TestDebugObject(t *testing.T) {
+	s, c := runWithClient(t)
+
+	now := time.Now()
+	s.SetTime(now)
+	mustDo(t, c, "SADD", "s", "1", "2", "3", proto.Int(3))
+	s.SetTime(now.Add(10 * time.Second))
+
+	res, err := c.Do("DEBUG", "OBJECT", "s")
+	ok(t, err)
+	re := regexp.MustCompile(`^\+Value at:0x[0-9a-f]+ refcount:1 encoding:intset serializedlength:3 lru:\d+ lru_seconds_idle:10\r\n$`)
+	assert(t, re.MatchString(res), "DEBUG OBJECT: %q", res)
+
+	mustDo(t, c, "SADD", "s", "foo", proto.Int(1))
+	mustContain(t, c, "DEBUG", "OBJECT", "s", "encoding:listpack")
+
+	t.Run("address", func(t *testing.T) {
+		at := func(key string) string {
+			t.Helper()
+			res, err := c.Do("DEBUG", "OBJECT", key)
+			ok(t, err)
+			for _, f := range strings.Fields(res) {
+				if strings.HasPrefix(f, "at:0x") {
+					return strings.TrimPrefix(f, "at:0x")
+				}
+			}
+			t.Fatalf("no at:0x in %q", res)
+			return ""
+		}
+		mustOK(t, c, "SET", "str", "value")
+		addr := at("str")
+		_, err := strconv.ParseUint(addr, 16, 64)
+		ok(t, err)
+
+		// stable for a key, even when it changes
+		mustOK(t, c, "SET", "str", "other value")
+		equals(t, addr, at("str"))
+		assert(t, addr != at("s"), "same address for different keys")
+	})
+
+	t.Run("hll", func(t *testing.T) {
+		mustDo(t, c, "PFADD", "h", "aap", "noot", proto.Int(1))
+		mustDo(t, c, "TYPE", "h", proto.Inline("string"))
+		res, err := c.Do("DEBUG", "OBJECT", "h")
+		ok(t, err)
+		re := regexp.MustCompile(`^\+Value at:0x[0-9a-f]+ refcount:1 encoding:raw serializedlength:\d+ lru:\d+ lru_seconds_idle:\d+\r\n$`)
+		assert(t, re.MatchString(res), "DEBUG OBJECT: %q", res)
+	})
+
+	t.Run("zset", func(t *testing.T) {
+		args := []string{"ZADD", "z"}
+		for i := 0; i < 200; i++ {
+			args = append(args, strconv.Itoa(i), fmt.Sprintf("member%d", i))
+		}
+		_, err := c.Do(args...)
+		ok(t, err)
+		mustContain(t, c, "DEBUG", "OBJECT", "z", "encoding:skiplist")
+		mustContain(t, c, "DEBUG", "OBJECT", "z", " zsl_level:4\r\n")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		mustOK(t, c, "CONFIG", "SET", "list-max-listpack-size", "3")
+
+		mustDo(t, c, "RPUSH", "l", "a", "b", "c", proto.Int(3))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+		res, err := c.Do("DEBUG", "OBJECT", "l")
+		ok(t, err)
+		assert(t, !strings.Contains(res, "ql_nodes"), "no quicklist fields: %q", res)
+
+		mustDo(t, c, "RPUSH", "l", "d", proto.Int(4))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("quicklist"))
+		mustContain(t, c, "DEBUG", "OBJECT", "l", "encoding:quicklist")
+		mustContain(t, c, "DEBUG", "OBJECT", "l", " ql_nodes:2 ql_avg_node:2.00 ql_listpack_max:3 ql_compressed:0 ql_uncompressed_size:26\r\n")
+
+		mustDo(t, c, "RPUSH", "l", "e", "f", "g", proto.Int(7))
+		mustContain(t, c, "DEBUG", "OBJECT", "l", " ql_nodes:3 ql_avg_node:2.33 ")
+
+		// more room per node
+		mustOK(t, c, "CONFIG", "SET", "list-max-listpack-size", "10")
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+	})
+
+	mustDo(t, c,
+		"DEBUG", "OBJECT", "nosuch",
+		proto.Error("ERR no such key"),
+	)
+	mustDo(t, c,
+		"DEBUG",
+		proto.Error("ERR wrong number of arguments for 'debug' command"),
+	)
+	mustDo(t, c,
+		"DEBUG", "OBJECT",
+		proto.Error("ERR unknown subcommand or wrong number of arguments for 'OBJECT'. Try DEBUG HELP."),
+	)
+	mustDo(t, c,
+		"DEBUG", "FOO",
+		proto.Error("ERR unknown subcommand or wrong number of arguments for 'FOO'. Try DEBUG HELP."),
+	)
+}
+
+func TestDebugStringmatchLen(t *testing.T) {
+	_, c := runWithClient(t)
+
+	for _, tc := range []struct {
+		pattern, str string
+		match        bool
+	}{
+		{"foo", "foo", true},
+		{"foo", "foobar", false},
+		{"f*", "foobar", true},
+		{"f*r", "foobar", true},
+		{"f*z", "foobar", false},
+		{"*", "", true},
+		{"f?o", "foo", true},
+		{"f?o", "fo", false},
+		{"h[ae]llo", "hello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`h\?llo`, "h?llo", true},
+		{`h[\]]llo`, "h]llo", true},
+		{"a.b", "a.b", true},
+		{"a.b", "axb", false},
+		{"h[]llo", "hllo", false},
+		{`foo\`, "foo", false},
+		{"line\nbreak*", "line\nbreak\nhere", true},
+	} {
+		want := proto.Int(0)
+		if tc.match {
+			want = proto.Int(1)
+		}
+		mustDo(t, c,
+			"DEBUG", "STRINGMATCH-LEN", tc.pattern, tc.str,
+			want,
+		)
+	}
+
+	mustDo(t, c,
+		"DEBUG", "STRINGMATCH-LEN",
+		proto.Inline("Apparently Redis did not crash: test passed"),
+	)
+	mustDo(t, c,
+		"DEBUG", "STRINGMATCH-LEN", "foo",
+		proto.Error("ERR unknown subcommand or wrong number of arguments for 'STRINGMATCH-LEN'. Try DEBUG HELP."),
+	)
+}
+
+func TestDebugQuicklistPackedThreshold(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c, "RPUSH", "l", "aa", "bb", proto.Int(2))
+	mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+
+	mustOK(t, c, "DEBUG", "QUICKLIST-PACKED-THRESHOLD", "1")
+	mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("quicklist"))
+	mustContain(t, c, "DEBUG", "OBJECT", "l", " ql_nodes:2 ")
+
+	mustOK(t, c, "DEBUG", "QUICKLIST-PACKED-THRESHOLD", "1K")
+	mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+	mustDo(t, c, "RPUSH", "l", strings.Repeat("x", 1000), proto.Int(3))
+	mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("quicklist"))
+
+	mustOK(t, c, "DEBUG", "QUICKLIST-PACKED-THRESHOLD", "0")
+	mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+
+	mustDo(t, c,
+		"DEBUG", "QUICKLIST-PACKED-THRESHOLD", "foo",
+		proto.Error(msgPackedThreshold),
+	)
+	mustDo(t, c,
+		"DEBUG", "QUICKLIST-PACKED-THRESHOLD", "4gb",
+		proto.Error(msgPackedThreshold),
+	)
+	// would overflow
+	mustDo(t, c,
+		"DEBUG", "QUICKLIST-PACKED-THRESHOLD", "20000000000gb",
+		proto.Error(msgPackedThreshold),
+	)
+	mustDo(t, c,
+		"DEBUG", "QUICKLIST-PACKED-THRESHOLD", "17179869184gb",
+		proto.Error(msgPackedThreshold),
+	)
+}
+
+func TestDebugListpack(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c, "RPUSH", "l", "a", "b", proto.Int(2))
+	mustDo(t, c, "DEBUG", "LISTPACK", "l", proto.Inline("Listpack structure printed on stdout"))
+	mustDo(t, c, "HSET", "h", "a", "b", proto.Int(1))
+	mustDo(t, c, "DEBUG", "LISTPACK", "h", proto.Inline("Listpack structure printed on stdout"))
+
+	mustOK(t, c, "SET", "s", "foo")
+	mustDo(t, c, "DEBUG", "LISTPACK", "s", proto.Error(msgNotListpack))
+	mustDo(t, c, "DEBUG", "LISTPACK", "nosuch", proto.Error(msgKeyNotFound))
+}
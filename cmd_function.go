@@ -0,0 +1,921 @@
+// Commands from https://redis.io/commands#scripting
+
+package miniredis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+// FunctionLibrary is a library loaded with FUNCTION LOAD.
+type FunctionLibrary struct {
+	Name      string
+	Code      string          // the full code, including the #! line
+	Functions []RedisFunction // sorted by name
+
+	l         *lua.LState               // the library's state, made by FUNCTION LOAD
+	callbacks map[string]*lua.LFunction // registered callbacks, by function name
+	redis     map[string]lua.LGFunction // implementation of the 'redis' module
+}
+
+// runningFunction is the function FCALL is running right now, for FUNCTION
+// STATS and FUNCTION KILL. FCALL holds the lock for as long as the function
+// runs, so everything those two need is in here, and never changes.
+type runningFunction struct {
+	name      string
+	command   []string // the FCALL command, with its arguments
+	start     time.Time
+	kill      context.CancelFunc
+	libraries int // FUNCTION STATS counts, which can't change during the run
+	functions int
+}
+
+// RedisFunction is a single function registered by a library.
+type RedisFunction struct {
+	Name        string
+	Description string
+	Flags       []string // as given to register_function, e.g. "no-writes"
+}
+
+// hasFlag is true if the function was registered with the flag.
+func (f RedisFunction) hasFlag(flag string) bool {
+	for _, fl := range f.Flags {
+		if fl == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnly is true if the function has the 'no-writes' flag.
+func (f RedisFunction) readOnly() bool {
+	return f.hasFlag("no-writes")
+}
+
+func commandsFunction(m *Miniredis) {
+	m.srv.Register("FUNCTION", m.cmdFunction)
+	m.srv.Register("FCALL", m.makeCmdFcall(false))
+	m.srv.Register("FCALL_RO", m.makeCmdFcall(true))
+}
+
+// FUNCTION
+func (m *Miniredis) cmdFunction(c *server.Peer, cmd string, args []string) {
+	if len(args) == 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if r := m.busyFunction(c, cmd, args); r != nil {
+		// the FCALL in the other connection has the lock
+		switch strings.ToLower(args[0]) {
+		case "stats":
+			writeFunctionStats(c, r, r.libraries, r.functions)
+		case "kill":
+			r.kill()
+			c.WriteOK()
+		}
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+	ctx := getCtx(c)
+	if ctx.nested {
+		c.WriteError(msgNotFromScripts(ctx.nestedSHA))
+		return
+	}
+
+	switch sub := strings.ToLower(args[0]); sub {
+	case "load":
+		m.cmdFunctionLoad(c, args[1:])
+	case "list":
+		m.cmdFunctionList(c, args[1:])
+	case "delete":
+		m.cmdFunctionDelete(c, args[1:])
+	case "flush":
+		m.cmdFunctionFlush(c, args[1:])
+	case "dump":
+		m.cmdFunctionDump(c, args[1:])
+	case "restore":
+		m.cmdFunctionRestore(c, args[1:])
+	case "stats":
+		m.cmdFunctionStats(c, args[1:])
+	case "kill":
+		m.cmdFunctionKill(c, args[1:])
+	default:
+		setDirty(c)
+		c.WriteError(fmt.Sprintf(msgFFunctionUsage, args[0]))
+	}
+}
+
+// FUNCTION LOAD
+func (m *Miniredis) cmdFunctionLoad(c *server.Peer, args []string) {
+	var opts struct {
+		replace bool
+		code    string
+	}
+	if len(args) > 0 && strings.ToUpper(args[0]) == "REPLACE" {
+		opts.replace = true
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|load"))
+		return
+	}
+	opts.code = args[0]
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		lib, err := parseLibrary(opts.code)
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+		if err := m.addLibrary(lib, opts.replace); err != nil {
+			lib.close()
+			c.WriteError(err.Error())
+			return
+		}
+		c.WriteBulk(lib.Name)
+	})
+}
+
+// FUNCTION LIST
+func (m *Miniredis) cmdFunctionList(c *server.Peer, args []string) {
+	var opts struct {
+		pattern  string
+		withCode bool
+	}
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "LIBRARYNAME":
+			if len(args) < 2 || opts.pattern != "" {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			opts.pattern = args[1]
+			args = args[2:]
+		case "WITHCODE":
+			if opts.withCode {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			opts.withCode = true
+			args = args[1:]
+		default:
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		// same glob matching as KEYS
+		var re *regexp.Regexp
+		if opts.pattern != "" {
+			re = patternRE(opts.pattern)
+			if re == nil {
+				// invalid pattern, matches nothing
+				c.WriteLen(0)
+				return
+			}
+		}
+		var libs []*FunctionLibrary
+		for _, lib := range m.libraries() {
+			if re != nil && !re.MatchString(lib.Name) {
+				continue
+			}
+			libs = append(libs, lib)
+		}
+
+		c.WriteLen(len(libs))
+		for _, lib := range libs {
+			if opts.withCode {
+				c.WriteMapLen(4)
+			} else {
+				c.WriteMapLen(3)
+			}
+			c.WriteBulk("library_name")
+			c.WriteBulk(lib.Name)
+			c.WriteBulk("engine")
+			c.WriteBulk("LUA")
+			c.WriteBulk("functions")
+			c.WriteLen(len(lib.Functions))
+			for _, f := range lib.Functions {
+				c.WriteMapLen(3)
+				c.WriteBulk("name")
+				c.WriteBulk(f.Name)
+				c.WriteBulk("description")
+				if f.Description == "" {
+					c.WriteNull()
+				} else {
+					c.WriteBulk(f.Description)
+				}
+				c.WriteBulk("flags")
+				c.WriteSetLen(len(f.Flags))
+				for _, fl := range f.Flags {
+					c.WriteBulk(fl)
+				}
+			}
+			if opts.withCode {
+				c.WriteBulk("library_code")
+				c.WriteBulk(lib.Code)
+			}
+		}
+	})
+}
+
+// FUNCTION DELETE
+func (m *Miniredis) cmdFunctionDelete(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|delete"))
+		return
+	}
+	name := args[0]
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		lib, ok := m.functions[name]
+		if !ok {
+			c.WriteError(msgLibraryNotFound)
+			return
+		}
+		lib.close()
+		delete(m.functions, name)
+		c.WriteOK()
+	})
+}
+
+// FUNCTION FLUSH
+func (m *Miniredis) cmdFunctionFlush(c *server.Peer, args []string) {
+	if len(args) == 1 {
+		switch strings.ToUpper(args[0]) {
+		case "SYNC", "ASYNC":
+			args = args[1:]
+		default:
+			setDirty(c)
+			c.WriteError("ERR FUNCTION FLUSH only supports SYNC|ASYNC option")
+			return
+		}
+	}
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|flush"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		for _, lib := range m.functions {
+			lib.close()
+		}
+		m.functions = map[string]*FunctionLibrary{}
+		c.WriteOK()
+	})
+}
+
+// FUNCTION DUMP
+func (m *Miniredis) cmdFunctionDump(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|dump"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		c.WriteBulk(string(dumpLibraries(m.libraries())))
+	})
+}
+
+// FUNCTION RESTORE
+func (m *Miniredis) cmdFunctionRestore(c *server.Peer, args []string) {
+	if len(args) != 1 && len(args) != 2 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|restore"))
+		return
+	}
+	var opts struct {
+		payload string
+		policy  string
+	}
+	opts.payload = args[0]
+	opts.policy = "APPEND"
+	if len(args) == 2 {
+		opts.policy = strings.ToUpper(args[1])
+		switch opts.policy {
+		case "FLUSH", "APPEND", "REPLACE":
+		default:
+			setDirty(c)
+			c.WriteError("ERR Wrong restore policy given, value should be either FLUSH, APPEND or REPLACE.")
+			return
+		}
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		codes, err := restoreLibraries([]byte(opts.payload))
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+
+		var libs []*FunctionLibrary
+		closeAll := func() {
+			for _, lib := range libs {
+				lib.close()
+			}
+		}
+		for _, code := range codes {
+			lib, err := parseLibrary(code)
+			if err != nil {
+				closeAll()
+				c.WriteError(err.Error())
+				return
+			}
+			libs = append(libs, lib)
+		}
+
+		// all or nothing
+		old := make(map[string]*FunctionLibrary, len(m.functions))
+		for k, v := range m.functions {
+			old[k] = v
+		}
+		if opts.policy == "FLUSH" {
+			m.functions = map[string]*FunctionLibrary{}
+		}
+		var replaced []*FunctionLibrary
+		for _, lib := range libs {
+			if prev, ok := m.functions[lib.Name]; ok && opts.policy == "REPLACE" {
+				// closed only when everything worked
+				replaced = append(replaced, prev)
+				delete(m.functions, lib.Name)
+			}
+			if err := m.addLibrary(lib, false); err != nil {
+				m.functions = old
+				closeAll()
+				c.WriteError(err.Error())
+				return
+			}
+		}
+		if opts.policy == "FLUSH" {
+			for _, lib := range old {
+				lib.close()
+			}
+		}
+		for _, lib := range replaced {
+			lib.close()
+		}
+		c.WriteOK()
+	})
+}
+
+// FUNCTION STATS
+func (m *Miniredis) cmdFunctionStats(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|stats"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		// FCALL holds the lock while it runs, so nothing is running here
+		writeFunctionStats(c, nil, len(m.functions), m.countFunctions())
+	})
+}
+
+func writeFunctionStats(c *server.Peer, r *runningFunction, libraries, functions int) {
+	c.WriteMapLen(2)
+	c.WriteBulk("running_script")
+	if r == nil {
+		c.WriteNull()
+	} else {
+		c.WriteMapLen(3)
+		c.WriteBulk("name")
+		c.WriteBulk(r.name)
+		c.WriteBulk("command")
+		c.WriteStrings(r.command)
+		c.WriteBulk("duration_ms")
+		c.WriteInt(int(time.Since(r.start).Milliseconds()))
+	}
+
+	c.WriteBulk("engines")
+	c.WriteMapLen(1)
+	c.WriteBulk("LUA")
+	c.WriteMapLen(2)
+	c.WriteBulk("libraries_count")
+	c.WriteInt(libraries)
+	c.WriteBulk("functions_count")
+	c.WriteInt(functions)
+}
+
+// countFunctions counts the functions of all libraries. Needs the lock.
+func (m *Miniredis) countFunctions() int {
+	n := 0
+	for _, lib := range m.functions {
+		n += len(lib.Functions)
+	}
+	return n
+}
+
+// FUNCTION KILL
+func (m *Miniredis) cmdFunctionKill(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("function|kill"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		// FCALL holds the lock while it runs, so nothing is running here
+		c.WriteError(msgNoFunctionRunning)
+	})
+}
+
+// busyFunction returns the function another connection is running, if cmd is
+// a FUNCTION STATS or FUNCTION KILL which should be handled right away,
+// without waiting for the lock. Otherwise it returns nil.
+func (m *Miniredis) busyFunction(c *server.Peer, cmd string, args []string) *runningFunction {
+	if !strings.EqualFold(cmd, "function") || len(args) != 1 {
+		return nil
+	}
+	if sub := strings.ToLower(args[0]); sub != "stats" && sub != "kill" {
+		return nil
+	}
+	if ctx := getCtx(c); ctx.nested || ctx.transaction != nil {
+		return nil
+	}
+
+	m.runningMu.Lock()
+	defer m.runningMu.Unlock()
+	return m.running
+}
+
+// FCALL and FCALL_RO
+func (m *Miniredis) makeCmdFcall(readOnly bool) server.Cmd {
+	return func(c *server.Peer, cmd string, args []string) {
+		if len(args) < 2 {
+			setDirty(c)
+			c.WriteError(errWrongNumber(cmd))
+			return
+		}
+		if !m.handleAuth(c) {
+			return
+		}
+		if m.checkPubsub(c, cmd) {
+			return
+		}
+		ctx := getCtx(c)
+		if ctx.nested {
+			c.WriteError(msgNotFromScripts(ctx.nestedSHA))
+			return
+		}
+
+		var opts struct {
+			name    string
+			keys    []string
+			args    []string
+			command []string
+		}
+		opts.command = append([]string{strings.ToLower(cmd)}, args...)
+		opts.name = args[0]
+		numKeys, err := strconv.Atoi(args[1])
+		if err != nil {
+			setDirty(c)
+			c.WriteError(msgInvalidInt)
+			return
+		}
+		args = args[2:]
+		if numKeys < 0 {
+			setDirty(c)
+			c.WriteError(msgNegativeKeysNumber)
+			return
+		}
+		if numKeys > len(args) {
+			setDirty(c)
+			c.WriteError(msgInvalidKeysNumber)
+			return
+		}
+		opts.keys, opts.args = args[:numKeys], args[numKeys:]
+
+		withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+			lib, f := m.findFunction(opts.name)
+			if lib == nil {
+				c.WriteError(msgFunctionNotFound)
+				return
+			}
+			if readOnly && !f.readOnly() {
+				c.WriteError(msgFunctionWriteRO)
+				return
+			}
+			// functions which might write are refused when we're out of
+			// memory, unless they have the 'allow-oom' flag
+			if !f.readOnly() && !f.hasFlag("allow-oom") && !m.evict() {
+				c.WriteError(msgOOM)
+				return
+			}
+			m.runFunction(c, lib, f, opts.command, opts.keys, opts.args)
+		})
+	}
+}
+
+// addLibrary stores a parsed library. Without replace an existing library
+// with the same name is an error. Function names need to be unique over all
+// libraries.
+func (m *Miniredis) addLibrary(lib *FunctionLibrary, replace bool) error {
+	if _, ok := m.functions[lib.Name]; ok && !replace {
+		return fmt.Errorf("ERR Library '%s' already exists", lib.Name)
+	}
+	for _, other := range m.functions {
+		if other.Name == lib.Name {
+			continue
+		}
+		for _, f := range lib.Functions {
+			for _, of := range other.Functions {
+				if f.Name == of.Name {
+					return fmt.Errorf("ERR Function %s already exists", f.Name)
+				}
+			}
+		}
+	}
+	if old, ok := m.functions[lib.Name]; ok {
+		old.close()
+	}
+	m.functions[lib.Name] = lib
+	return nil
+}
+
+// libraries gives all libraries, sorted by name.
+func (m *Miniredis) libraries() []*FunctionLibrary {
+	libs := make([]*FunctionLibrary, 0, len(m.functions))
+	for _, lib := range m.functions {
+		libs = append(libs, lib)
+	}
+	sort.Slice(libs, func(i, j int) bool { return libs[i].Name < libs[j].Name })
+	return libs
+}
+
+// findFunction looks up a function over all libraries.
+func (m *Miniredis) findFunction(name string) (*FunctionLibrary, *RedisFunction) {
+	for _, lib := range m.functions {
+		for i, f := range lib.Functions {
+			if f.Name == name {
+				return lib, &lib.Functions[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// runFunction executes a function, in the Lua state of its library. The
+// callback is called with the keys and args tables. While it runs it's the
+// FUNCTION STATS running_script, and it can be stopped with FUNCTION KILL.
+// Needs to run m.Lock()ed, from within withTx().
+func (m *Miniredis) runFunction(c *server.Peer, lib *FunctionLibrary, f *RedisFunction, command, keys, args []string) {
+	cb, ok := lib.callbacks[f.Name]
+	if !ok {
+		c.WriteError(msgFunctionNotFound)
+		return
+	}
+
+	l := lib.l
+	// functions with the 'no-writes' flag can never write, not even via FCALL
+	lib.redis, _ = mkLua(m.srv, c, f.Name, f.readOnly(), f.hasFlag("allow-oom"))
+	defer func() { lib.redis = nil }()
+
+	kctx, kill := context.WithCancel(context.Background())
+	m.runningMu.Lock()
+	m.running = &runningFunction{
+		name:      f.Name,
+		command:   command,
+		start:     time.Now(),
+		kill:      kill,
+		libraries: len(m.functions),
+		functions: m.countFunctions(),
+	}
+	m.runningMu.Unlock()
+	l.SetContext(kctx)
+	defer func() {
+		l.RemoveContext()
+		kill()
+		m.runningMu.Lock()
+		m.running = nil
+		m.runningMu.Unlock()
+	}()
+
+	keysTable := l.NewTable()
+	for i, k := range keys {
+		l.RawSet(keysTable, lua.LNumber(i+1), lua.LString(k))
+	}
+	argvTable := l.NewTable()
+	for i, a := range args {
+		l.RawSet(argvTable, lua.LNumber(i+1), lua.LString(a))
+	}
+
+	// lua can call redis.setresp(...), but it's tmp state.
+	oldresp := c.Resp3
+	defer func() {
+		c.SetResp3(oldresp)
+		c.SwitchResp3 = nil
+	}()
+	if err := l.CallByParam(lua.P{
+		Fn:      cb,
+		NRet:    1,
+		Protect: true,
+	}, keysTable, argvTable); err != nil {
+		if kctx.Err() != nil {
+			// only FUNCTION KILL cancels it while it runs
+			c.WriteError(msgFunctionKilled)
+			return
+		}
+		c.WriteError(errFunctionRunError(err))
+		return
+	}
+
+	res := l.Get(-1)
+	l.Pop(1)
+	luaToRedis(l, c, res)
+}
+
+// parseLibrary checks the "#!lua name=..." header, and runs the code to find
+// the registered functions.
+func parseLibrary(code string) (*FunctionLibrary, error) {
+	if !strings.HasPrefix(code, "#!") {
+		return nil, fmt.Errorf(msgLibraryMetadata)
+	}
+	header := code
+	if i := strings.IndexByte(code, '\n'); i >= 0 {
+		header = code[:i]
+	}
+	fields := strings.Fields(header[2:])
+	if len(fields) == 0 || strings.ToLower(fields[0]) != "lua" {
+		engine := ""
+		if len(fields) > 0 {
+			engine = fields[0]
+		}
+		return nil, fmt.Errorf("ERR Engine '%s' not found", engine)
+	}
+	lib := &FunctionLibrary{
+		Code: code,
+	}
+	for _, f := range fields[1:] {
+		if !strings.HasPrefix(f, "name=") {
+			return nil, fmt.Errorf("ERR Invalid metadata value given: %s", f)
+		}
+		lib.Name = strings.TrimPrefix(f, "name=")
+	}
+	if lib.Name == "" {
+		return nil, fmt.Errorf(msgLibraryNoName)
+	}
+	if !validFunctionName(lib.Name) {
+		return nil, fmt.Errorf(msgLibraryNameInvalid)
+	}
+
+	lib.l = newLuaState()
+	lib.callbacks = map[string]*lua.LFunction{}
+
+	// The 'redis' module forwards to lib.redis, which is set for every FCALL,
+	// since redis.call() needs the calling connection.
+	funcs := map[string]lua.LGFunction{}
+	names, _ := mkLua(nil, server.NewPeer(nil), "", false, false)
+	names["register_function"] = nil
+	for n := range names {
+		n := n
+		funcs[n] = func(l *lua.LState) int {
+			f, ok := lib.redis[n]
+			if !ok {
+				l.RaiseError("redis.%s is not available here", n)
+				return 0
+			}
+			return f(l)
+		}
+	}
+	openRedis(lib.l, funcs, luaRedisConstants)
+
+	var regErr error
+	lib.redis = map[string]lua.LGFunction{
+		"register_function": registerFunction(func(f RedisFunction, cb *lua.LFunction) {
+			if _, ok := lib.callbacks[f.Name]; ok {
+				regErr = fmt.Errorf("ERR Function %s already exists in the library", f.Name)
+			}
+			lib.Functions = append(lib.Functions, f)
+			lib.callbacks[f.Name] = cb
+		}),
+		"log": func(l *lua.LState) int {
+			return 0
+		},
+	}
+	err := runLibrary(lib.l, code)
+	lib.redis = nil
+	if err == nil {
+		err = regErr
+	}
+	if err == nil && len(lib.Functions) == 0 {
+		err = fmt.Errorf(msgNoFunctions)
+	}
+	if err != nil {
+		lib.close()
+		return nil, err
+	}
+	sort.Slice(lib.Functions, func(i, j int) bool {
+		return lib.Functions[i].Name < lib.Functions[j].Name
+	})
+	return lib, nil
+}
+
+// dumpLibraries makes a FUNCTION DUMP payload, in the same format as real
+// Redis uses: every library is its full code, and the payload ends with the
+// RDB version and a checksum.
+func dumpLibraries(libs []*FunctionLibrary) []byte {
+	var b []byte
+	for _, lib := range libs {
+		b = append(b, rdbOpcodeFunction2)
+		b = rdbAppendString(b, lib.Code)
+	}
+	return rdbFooter(b)
+}
+
+// restoreLibraries gives the library code from a FUNCTION DUMP payload. It
+// reads payloads made by real Redis, which might have compressed strings.
+func restoreLibraries(payload []byte) ([]string, error) {
+	b, err := rdbCheckFooter(payload)
+	if err != nil {
+		return nil, err
+	}
+	var codes []string
+	for len(b) > 0 {
+		if b[0] != rdbOpcodeFunction2 {
+			return nil, errRdbFormat
+		}
+		var code string
+		code, b, err = rdbReadString(b[1:])
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// close releases the Lua state.
+func (lib *FunctionLibrary) close() {
+	if lib.l != nil {
+		lib.l.Close()
+	}
+}
+
+// runLibrary runs the library code, without the #! line.
+func runLibrary(l *lua.LState, code string) error {
+	// keep the line numbers the same
+	if i := strings.IndexByte(code, '\n'); i >= 0 {
+		code = code[i:]
+	} else {
+		code = ""
+	}
+
+	proto, err := compile(code)
+	if err != nil {
+		return fmt.Errorf("ERR Error compiling function: %s", err.Error())
+	}
+	l.Push(l.NewFunctionFromProto(proto))
+	if err := l.PCall(0, lua.MultRet, nil); err != nil {
+		return fmt.Errorf("ERR Error registering functions: %s", err.Error())
+	}
+	return nil
+}
+
+// registerFunction is the redis.register_function() Lua function. It
+// supports both the positional and the table (named arguments) form.
+func registerFunction(cb func(RedisFunction, *lua.LFunction)) lua.LGFunction {
+	return func(l *lua.LState) int {
+		var (
+			f  RedisFunction
+			fn *lua.LFunction
+		)
+		switch l.GetTop() {
+		case 1:
+			t, ok := l.Get(1).(*lua.LTable)
+			if !ok {
+				l.RaiseError("calling redis.register_function with a single argument is only applicable to Lua table (representing named arguments).")
+				return 0
+			}
+			var err string
+			t.ForEach(func(k, v lua.LValue) {
+				if err != "" {
+					return
+				}
+				switch k.String() {
+				case "function_name":
+					s, ok := v.(lua.LString)
+					if !ok {
+						err = "function_name argument given to redis.register_function must be a string"
+						return
+					}
+					f.Name = string(s)
+				case "description":
+					s, ok := v.(lua.LString)
+					if !ok {
+						err = "description argument given to redis.register_function must be a string"
+						return
+					}
+					f.Description = string(s)
+				case "callback":
+					c, ok := v.(*lua.LFunction)
+					if !ok {
+						err = "callback argument given to redis.register_function must be a function"
+						return
+					}
+					fn = c
+				case "flags":
+					flags, ok := v.(*lua.LTable)
+					if !ok {
+						err = "flags argument to redis.register_function must be a table representing function flags"
+						return
+					}
+					flags.ForEach(func(_, flag lua.LValue) {
+						if err != "" {
+							return
+						}
+						switch fl := flag.String(); fl {
+						case "no-writes", "allow-oom", "allow-stale", "no-cluster", "allow-cross-slot-keys":
+							// only no-writes does anything here
+							for _, have := range f.Flags {
+								if have == fl {
+									return
+								}
+							}
+							f.Flags = append(f.Flags, fl)
+						default:
+							err = "unknown flag given"
+						}
+					})
+				default:
+					err = "unknown argument given to redis.register_function"
+				}
+			})
+			if err != "" {
+				l.RaiseError(err)
+				return 0
+			}
+		case 2:
+			name, ok := l.Get(1).(lua.LString)
+			if !ok {
+				l.RaiseError("first argument to redis.register_function must be a string")
+				return 0
+			}
+			f.Name = string(name)
+			fn, ok = l.Get(2).(*lua.LFunction)
+			if !ok {
+				l.RaiseError("second argument to redis.register_function must be a function")
+				return 0
+			}
+		default:
+			l.RaiseError("wrong number of arguments to redis.register_function")
+			return 0
+		}
+
+		if f.Name == "" {
+			l.RaiseError("redis.register_function must get a function name argument")
+			return 0
+		}
+		if fn == nil {
+			l.RaiseError("redis.register_function must get a callback argument")
+			return 0
+		}
+		if !validFunctionName(f.Name) {
+			l.RaiseError(msgFunctionNameInvalid)
+			return 0
+		}
+		cb(f, fn)
+		return 0
+	}
+}
+
+// validFunctionName is for both library and function names.
+func validFunctionName(n string) bool {
+	if n == "" {
+		return false
+	}
+	for _, r := range n {
+		switch {
+		case r >= 'a' && r <= 'z',
+			r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9',
+			r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,746 @@
+package miniredis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+func TestFunctionLoad(t *testing.T) {
+	_, c := runWithClient(t)
+
+	lib := `#!lua name=mylib
+redis.register_function('echo', function(keys, args) return args[1] end)
+redis.register_function{
+	function_name='getter',
+	callback=function(keys, args) return redis.call('GET', keys[1]) end,
+	flags={'no-writes'},
+	description='gets a key',
+}`
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", lib,
+		proto.String("mylib"),
+	)
+	mustDo(t, c,
+		"FUNCTION", "LOAD", lib,
+		proto.Error("ERR Library 'mylib' already exists"),
+	)
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "REPLACE", lib,
+		proto.String("mylib"),
+	)
+
+	mustDo(t, c,
+		"FUNCTION", "LIST",
+		proto.Array(
+			proto.Array(
+				proto.String("library_name"), proto.String("mylib"),
+				proto.String("engine"), proto.String("LUA"),
+				proto.String("functions"), proto.Array(
+					proto.Array(
+						proto.String("name"), proto.String("echo"),
+						proto.String("description"), proto.Nil,
+						proto.String("flags"), proto.Array(),
+					),
+					proto.Array(
+						proto.String("name"), proto.String("getter"),
+						proto.String("description"), proto.String("gets a key"),
+						proto.String("flags"), proto.Strings("no-writes"),
+					),
+				),
+			),
+		),
+	)
+	mustDo(t, c,
+		"FUNCTION", "LIST", "LIBRARYNAME", "nosuch*",
+		proto.Array(),
+	)
+	mustContain(t, c,
+		"FUNCTION", "LIST", "LIBRARYNAME", "my*", "WITHCODE",
+		"library_code",
+	)
+	for _, pat := range []string{"mylib", "my*", "*lib", "?ylib", "my[a-z]ib", "*"} {
+		mustContain(t, c,
+			"FUNCTION", "LIST", "LIBRARYNAME", pat,
+			"mylib",
+		)
+	}
+	for _, pat := range []string{"my", "mylib?", "[xyz]ylib", "MYLIB"} {
+		mustDo(t, c,
+			"FUNCTION", "LIST", "LIBRARYNAME", pat,
+			proto.Array(),
+		)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"FUNCTION",
+			proto.Error(errWrongNumber("function")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "FOO",
+			proto.Error("ERR unknown subcommand 'FOO'. Try FUNCTION HELP."),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD",
+			proto.Error(errWrongNumber("function|load")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "return 1",
+			proto.Error(msgLibraryMetadata),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!js name=foo\nreturn 1",
+			proto.Error("ERR Engine 'js' not found"),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua\nreturn 1",
+			proto.Error(msgLibraryNoName),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo bar=baz\nreturn 1",
+			proto.Error("ERR Invalid metadata value given: bar=baz"),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo-bar\nreturn 1",
+			proto.Error(msgLibraryNameInvalid),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo\nreturn 1",
+			proto.Error(msgNoFunctions),
+		)
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=other\nredis.register_function('echo', function() end)",
+			proto.Error("ERR Function echo already exists"),
+		)
+		mustContain(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo\nredis.register_function('f', 'notafunction')",
+			"second argument to redis.register_function must be a function",
+		)
+		mustContain(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo\nredis.register_function{function_name='f', callback=function() end, flags={'nosuch'}}",
+			"unknown flag given",
+		)
+		mustContain(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo\nredis.register_function('f-g', function() end)",
+			"Function names can only contain letters",
+		)
+		mustContain(t, c,
+			"FUNCTION", "LOAD", "#!lua name=foo\nthis is not lua",
+			"Error compiling function",
+		)
+		mustDo(t, c,
+			"FUNCTION", "LIST", "WITHCODE", "WITHCODE",
+			proto.Error(msgSyntaxError),
+		)
+	})
+}
+
+func TestFunctionFlags(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=flags
+redis.register_function{
+	function_name='f',
+	callback=function(keys, args) return 1 end,
+	flags={'allow-oom', 'no-writes', 'allow-stale', 'no-cluster', 'allow-cross-slot-keys', 'no-writes'},
+}`,
+		proto.String("flags"),
+	)
+	mustDo(t, c,
+		"FUNCTION", "LIST",
+		proto.Array(
+			proto.Array(
+				proto.String("library_name"), proto.String("flags"),
+				proto.String("engine"), proto.String("LUA"),
+				proto.String("functions"), proto.Array(
+					proto.Array(
+						proto.String("name"), proto.String("f"),
+						proto.String("description"), proto.Nil,
+						proto.String("flags"), proto.Strings("allow-oom", "no-writes", "allow-stale", "no-cluster", "allow-cross-slot-keys"),
+					),
+				),
+			),
+		),
+	)
+	mustDo(t, c, "FCALL_RO", "f", "0", proto.Int(1))
+
+	mustContain(t, c,
+		"FUNCTION", "LOAD", "#!lua name=foo\nredis.register_function{function_name='g', callback=function() end, flags={'allow-oom', 'no-reads'}}",
+		"unknown flag given",
+	)
+}
+
+func TestFunctionDelete(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "#!lua name=lib1\nredis.register_function('f1', function() return 1 end)",
+		proto.String("lib1"),
+	)
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "#!lua name=lib2\nredis.register_function('f2', function() return 2 end)",
+		proto.String("lib2"),
+	)
+
+	mustOK(t, c, "FUNCTION", "DELETE", "lib1")
+	mustDo(t, c,
+		"FCALL", "f1", "0",
+		proto.Error(msgFunctionNotFound),
+	)
+	mustDo(t, c,
+		"FUNCTION", "DELETE", "lib1",
+		proto.Error(msgLibraryNotFound),
+	)
+
+	mustOK(t, c, "FUNCTION", "FLUSH", "SYNC")
+	mustDo(t, c,
+		"FUNCTION", "LIST",
+		proto.Array(),
+	)
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"FUNCTION", "DELETE",
+			proto.Error(errWrongNumber("function|delete")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "FLUSH", "foo",
+			proto.Error("ERR FUNCTION FLUSH only supports SYNC|ASYNC option"),
+		)
+	})
+}
+
+func TestFcall(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=lib
+local function join(keys, args)
+	local res = {}
+	for _, k in ipairs(keys) do
+		table.insert(res, k)
+	end
+	for _, a in ipairs(args) do
+		table.insert(res, a)
+	end
+	return res
+end
+redis.register_function('join', join)
+redis.register_function('set', function(keys, args)
+	return redis.call('SET', keys[1], args[1])
+end)
+redis.register_function{
+	function_name='get',
+	callback=function(keys, args) return redis.call('GET', keys[1]) end,
+	flags={'no-writes'},
+}`,
+		proto.String("lib"),
+	)
+
+	mustDo(t, c,
+		"FCALL", "join", "2", "k1", "k2", "a1",
+		proto.Strings("k1", "k2", "a1"),
+	)
+	mustDo(t, c,
+		"FCALL", "join", "0",
+		proto.Strings(),
+	)
+	mustOK(t, c,
+		"FCALL", "set", "1", "foo", "bar",
+	)
+	s.CheckGet(t, "foo", "bar")
+	mustDo(t, c,
+		"FCALL", "get", "1", "foo",
+		proto.String("bar"),
+	)
+	mustDo(t, c,
+		"FCALL_RO", "get", "1", "foo",
+		proto.String("bar"),
+	)
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"FCALL", "join",
+			proto.Error(errWrongNumber("fcall")),
+		)
+		mustDo(t, c,
+			"FCALL", "nosuch", "0",
+			proto.Error(msgFunctionNotFound),
+		)
+		mustDo(t, c,
+			"FCALL", "join", "noint",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"FCALL", "join", "-1",
+			proto.Error(msgNegativeKeysNumber),
+		)
+		mustDo(t, c,
+			"FCALL", "join", "3", "k1",
+			proto.Error(msgInvalidKeysNumber),
+		)
+		mustDo(t, c,
+			"FCALL_RO", "set", "1", "foo", "bar",
+			proto.Error(msgFunctionWriteRO),
+		)
+		mustContain(t, c,
+			"EVAL", "return redis.call('FCALL', 'join', '0')", "0",
+			"This Redis command is not allowed from script",
+		)
+		// checked before the function lookup
+		mustContain(t, c,
+			"EVAL", "return redis.call('FCALL', 'nosuch', '0')", "0",
+			"This Redis command is not allowed from script",
+		)
+		mustContain(t, c,
+			"EVAL", "return redis.call('FCALL_RO', 'nosuch', '0')", "0",
+			"This Redis command is not allowed from script",
+		)
+	})
+}
+
+func TestFcallKeys(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=lib
+redis.register_function('count', function(keys, args)
+	local seen = 0
+	for i = 1, #keys do
+		seen = seen + 1
+	end
+	return {#keys, #args, seen, keys[#keys + 1] == nil}
+end)`,
+		proto.String("lib"),
+	)
+
+	for _, cas := range []struct {
+		numkeys    string
+		keys, args int
+	}{
+		{"0", 0, 3},
+		{"1", 1, 2},
+		{"2", 2, 1},
+		{"3", 3, 0},
+	} {
+		mustDo(t, c,
+			"FCALL", "count", cas.numkeys, "a", "b", "c",
+			proto.Array(
+				proto.Int(cas.keys),
+				proto.Int(cas.args),
+				proto.Int(cas.keys),
+				proto.Int(1),
+			),
+		)
+	}
+}
+
+func TestFcallState(t *testing.T) {
+	_, c := runWithClient(t)
+
+	// the library code runs once, on load
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=counter
+local n = 0
+redis.register_function('count', function(keys, args)
+	n = n + 1
+	return n
+end)
+redis.register_function('again', function(keys, args)
+	redis.register_function('more', function() end)
+end)`,
+		proto.String("counter"),
+	)
+	mustDo(t, c, "FCALL", "count", "0", proto.Int(1))
+	mustDo(t, c, "FCALL", "count", "0", proto.Int(2))
+	mustContain(t, c,
+		"FCALL", "again", "0",
+		"redis.register_function is not available here",
+	)
+
+	// replacing the library starts over
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "REPLACE", `#!lua name=counter
+local n = 10
+redis.register_function('count', function(keys, args)
+	n = n + 1
+	return n
+end)`,
+		proto.String("counter"),
+	)
+	mustDo(t, c, "FCALL", "count", "0", proto.Int(11))
+
+	mustContain(t, c,
+		"FUNCTION", "LOAD", `#!lua name=caller
+redis.call('SET', 'foo', 'bar')
+redis.register_function('f', function() end)`,
+		"redis.call is not available here",
+	)
+}
+
+func TestFcallNoWrites(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=lib
+redis.register_function{
+	function_name='sneaky',
+	callback=function(keys, args)
+		redis.call('GET', keys[1])
+		return redis.call('SET', keys[1], 'changed')
+	end,
+	flags={'no-writes'},
+}`,
+		proto.String("lib"),
+	)
+
+	s.Set("foo", "bar")
+	for _, cmd := range []string{"FCALL", "FCALL_RO"} {
+		mustContain(t, c,
+			cmd, "sneaky", "1", "foo",
+			msgWriteFromReadOnly,
+		)
+	}
+	s.CheckGet(t, "foo", "bar")
+}
+
+func TestFcallAllowOOM(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=lib
+redis.register_function('plain', function(keys, args) return redis.call('SET', keys[1], args[1]) end)
+redis.register_function{
+	function_name='oom',
+	callback=function(keys, args) return redis.call('SET', keys[1], args[1]) end,
+	flags={'allow-oom'},
+}
+redis.register_function{
+	function_name='ro',
+	callback=function(keys, args) return redis.call('GET', keys[1]) end,
+	flags={'no-writes'},
+}`,
+		proto.String("lib"),
+	)
+
+	mustOK(t, c, "SET", "foo", "bar")
+	s.SetMaxMemory(1)
+	mustContain(t, c,
+		"FCALL", "plain", "1", "foo", "baz",
+		msgOOM,
+	)
+	mustDo(t, c,
+		"FCALL", "ro", "1", "foo",
+		proto.String("bar"),
+	)
+	mustOK(t, c, "FCALL", "oom", "1", "foo", "baz")
+	s.CheckGet(t, "foo", "baz")
+
+	s.SetMaxMemory(0)
+	mustOK(t, c, "FCALL", "plain", "1", "foo", "qux")
+	s.CheckGet(t, "foo", "qux")
+}
+
+func TestFcallSetresp(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "#!lua name=lib\nredis.register_function('f', function() redis.setresp(3); error('boom') end)",
+		proto.String("lib"),
+	)
+	// redis.setresp() only lasts for the function, even if it fails
+	mustContain(t, c, "FCALL", "f", "0", "boom")
+	mustContain(t, c, "CLIENT", "INFO", " resp=2 ")
+}
+
+func TestFunctionDumpRestore(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "#!lua name=lib1\nredis.register_function('f1', function() return 1 end)",
+		proto.String("lib1"),
+	)
+	mustDo(t, c,
+		"FUNCTION", "LOAD", "#!lua name=lib2\nredis.register_function('f2', function() return 2 end)",
+		proto.String("lib2"),
+	)
+
+	payload, err := c.Do("FUNCTION", "DUMP")
+	ok(t, err)
+	dump, err := proto.Parse(payload)
+	ok(t, err)
+
+	mustDo(t, c,
+		"FUNCTION", "RESTORE", dump.(string),
+		proto.Error("ERR Library 'lib1' already exists"),
+	)
+
+	mustOK(t, c, "FUNCTION", "FLUSH")
+	mustOK(t, c, "FUNCTION", "RESTORE", dump.(string))
+	mustDo(t, c, "FCALL", "f1", "0", proto.Int(1))
+	mustDo(t, c, "FCALL", "f2", "0", proto.Int(2))
+
+	t.Run("policy", func(t *testing.T) {
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", dump.(string), "APPEND",
+			proto.Error("ERR Library 'lib1' already exists"),
+		)
+		mustOK(t, c, "FUNCTION", "RESTORE", dump.(string), "replace")
+		mustDo(t, c, "FCALL", "f1", "0", proto.Int(1))
+
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=other\nredis.register_function('o', function() return 3 end)",
+			proto.String("other"),
+		)
+		mustOK(t, c, "FUNCTION", "RESTORE", dump.(string), "FLUSH")
+		mustDo(t, c,
+			"FCALL", "o", "0",
+			proto.Error(msgFunctionNotFound),
+		)
+		mustDo(t, c, "FCALL", "f2", "0", proto.Int(2))
+
+		// nothing changes on errors
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=other\nredis.register_function('f1', function() return 3 end)",
+			proto.Error("ERR Function f1 already exists"),
+		)
+		mustOK(t, c, "FUNCTION", "DELETE", "lib2")
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", dump.(string),
+			proto.Error("ERR Library 'lib1' already exists"),
+		)
+		mustDo(t, c,
+			"FCALL", "f2", "0",
+			proto.Error(msgFunctionNotFound),
+		)
+		mustDo(t, c, "FCALL", "f1", "0", proto.Int(1))
+		mustOK(t, c, "FUNCTION", "RESTORE", dump.(string), "REPLACE")
+	})
+
+	t.Run("table style", func(t *testing.T) {
+		mustOK(t, c, "FUNCTION", "FLUSH")
+		mustDo(t, c,
+			"FUNCTION", "LOAD", "#!lua name=tbl\nredis.register_function{function_name='echo', callback=function(keys, args) return keys[1] .. ':' .. args[1] end, flags={'no-writes', 'allow-stale'}}",
+			proto.String("tbl"),
+		)
+		payload, err := c.Do("FUNCTION", "DUMP")
+		ok(t, err)
+		tdump, err := proto.Parse(payload)
+		ok(t, err)
+
+		mustOK(t, c, "FUNCTION", "FLUSH")
+		mustOK(t, c, "FUNCTION", "RESTORE", tdump.(string))
+		mustDo(t, c,
+			"FUNCTION", "LIST",
+			proto.Array(
+				proto.Array(
+					proto.String("library_name"), proto.String("tbl"),
+					proto.String("engine"), proto.String("LUA"),
+					proto.String("functions"), proto.Array(
+						proto.Array(
+							proto.String("name"), proto.String("echo"),
+							proto.String("description"), proto.Nil,
+							proto.String("flags"), proto.Strings("no-writes", "allow-stale"),
+						),
+					),
+				),
+			),
+		)
+		mustDo(t, c,
+			"FCALL", "echo", "1", "foo", "bar",
+			proto.String("foo:bar"),
+		)
+		mustDo(t, c,
+			"FCALL_RO", "echo", "1", "foo", "bar",
+			proto.String("foo:bar"),
+		)
+		mustOK(t, c, "FUNCTION", "RESTORE", dump.(string), "FLUSH")
+	})
+
+	t.Run("compressed", func(t *testing.T) {
+		// Real Redis LZF compresses longer strings.
+		prefix := "#!lua name=lib3\nredis.register_function('f3', function() return 3 end) -- a"
+		var lzf []byte
+		for p := prefix; len(p) > 0; {
+			n := len(p)
+			if n > 32 {
+				n = 32
+			}
+			lzf = append(lzf, byte(n-1))
+			lzf = append(lzf, p[:n]...)
+			p = p[n:]
+		}
+		lzf = append(lzf, 0xe0, 0x00, 0x00) // 9 more 'a's
+
+		b := []byte{rdbOpcodeFunction2, 0xc3}
+		b = rdbAppendLen(b, uint64(len(lzf)))
+		b = rdbAppendLen(b, uint64(len(prefix)+9))
+		b = append(b, lzf...)
+		mustOK(t, c, "FUNCTION", "RESTORE", string(rdbFooter(b)))
+		mustDo(t, c, "FCALL", "f3", "0", proto.Int(3))
+		mustContain(t, c,
+			"FUNCTION", "LIST", "LIBRARYNAME", "lib3", "WITHCODE",
+			"-- aaaaaaaaaa",
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"FUNCTION", "DUMP", "foo",
+			proto.Error(errWrongNumber("function|dump")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "RESTORE",
+			proto.Error(errWrongNumber("function|restore")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", "foobar",
+			proto.Error("ERR payload version or checksum are wrong"),
+		)
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", dump.(string), "MERGE",
+			proto.Error("ERR Wrong restore policy given, value should be either FLUSH, APPEND or REPLACE."),
+		)
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", dump.(string), "FLUSH", "FLUSH",
+			proto.Error(errWrongNumber("function|restore")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", string(rdbFooter([]byte{0x00})),
+			proto.Error("ERR given payload is not a valid dump"),
+		)
+
+		// an LZF string which claims to be huge
+		huge := []byte{rdbOpcodeFunction2, 0xc3, 0x05, rdbLen64, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 'a', 0xe0, 0x00, 0x00}
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", string(rdbFooter(huge)),
+			proto.Error("ERR payload version or checksum are wrong"),
+		)
+		noCRC := rdbFooter(huge)
+		copy(noCRC[len(noCRC)-8:], make([]byte, 8))
+		mustDo(t, c,
+			"FUNCTION", "RESTORE", string(noCRC),
+			proto.Error("ERR payload version or checksum are wrong"),
+		)
+		mustDo(t, c, "FCALL", "f3", "0", proto.Int(3))
+	})
+}
+
+func TestFunctionDirect(t *testing.T) {
+	s, c := runWithClient(t)
+
+	name, err := s.LoadFunction("#!lua name=lib\nredis.register_function{function_name='f', callback=function() return 1 end, flags={'no-writes'}, description='one'}")
+	ok(t, err)
+	equals(t, "lib", name)
+	mustDo(t, c, "FCALL", "f", "0", proto.Int(1))
+
+	_, err = s.LoadFunction("#!lua name=lib\nredis.register_function('g', function() end)")
+	equals(t, "ERR Library 'lib' already exists", err.Error())
+	_, err = s.LoadFunction("return 1")
+	equals(t, msgLibraryMetadata, err.Error())
+
+	equals(t,
+		[]FunctionLibrary{
+			{
+				Name: "lib",
+				Code: "#!lua name=lib\nredis.register_function{function_name='f', callback=function() return 1 end, flags={'no-writes'}, description='one'}",
+				Functions: []RedisFunction{
+					{Name: "f", Description: "one", Flags: []string{"no-writes"}},
+				},
+			},
+		},
+		s.Functions(),
+	)
+
+	mustOK(t, c, "FUNCTION", "FLUSH")
+	equals(t, []FunctionLibrary(nil), s.Functions())
+}
+
+func TestFunctionStats(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c,
+		"FUNCTION", "STATS",
+		proto.Array(
+			proto.String("running_script"), proto.Nil,
+			proto.String("engines"), proto.Array(
+				proto.String("LUA"), proto.Array(
+					proto.String("libraries_count"), proto.Int(0),
+					proto.String("functions_count"), proto.Int(0),
+				),
+			),
+		),
+	)
+	mustDo(t, c,
+		"FUNCTION", "KILL",
+		proto.Error("NOTBUSY No scripts in execution right now."),
+	)
+
+	mustDo(t, c,
+		"FUNCTION", "LOAD", `#!lua name=lib
+redis.register_function('loop', function(keys, args)
+	while true do end
+end)
+redis.register_function('other', function() return 1 end)`,
+		proto.String("lib"),
+	)
+
+	// run the function in another connection, until we kill it
+	c2, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c2.Close()
+	res := make(chan string, 1)
+	go func() {
+		r, err := c2.Do("FCALL", "loop", "1", "foo", "bar")
+		ok(t, err)
+		res <- r
+	}()
+
+	for {
+		stats, err := c.Do("FUNCTION", "STATS")
+		ok(t, err)
+		v, err := proto.Parse(stats)
+		ok(t, err)
+		if running, _ := v.([]interface{})[1].([]interface{}); running != nil {
+			// duration_ms varies
+			equals(t,
+				[]interface{}{
+					"name", "loop",
+					"command", []interface{}{"fcall", "loop", "1", "foo", "bar"},
+					"duration_ms",
+				},
+				running[:5],
+			)
+			equals(t,
+				[]interface{}{"LUA", []interface{}{"libraries_count", 1, "functions_count", 2}},
+				v.([]interface{})[3],
+			)
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mustOK(t, c, "FUNCTION", "KILL")
+	equals(t, proto.Error("ERR Script killed by user with FUNCTION KILL."), <-res)
+
+	// done running
+	mustContain(t, c, "FUNCTION", "STATS", "running_script")
+	mustDo(t, c,
+		"FUNCTION", "KILL",
+		proto.Error("NOTBUSY No scripts in execution right now."),
+	)
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"FUNCTION", "STATS", "foo",
+			proto.Error(errWrongNumber("function|stats")),
+		)
+		mustDo(t, c,
+			"FUNCTION", "KILL", "foo",
+			proto.Error(errWrongNumber("function|kill")),
+		)
+	})
+}
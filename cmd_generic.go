@@ -5,7 +5,6 @@ package miniredis
 import (
 	"errors"
 	"fmt"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -163,8 +162,13 @@ func makeCmdExpire(m *Miniredis, unix bool, d time.Duration) func(*server.Peer,
 				return
 			}
 			db.ttl[opts.key] = newTTL
-			db.incr(opts.key)
-			db.checkTTL(opts.key)
+			if newTTL <= 0 {
+				db.del(opts.key, true)
+				db.notify(notifyGeneric, "del", opts.key)
+			} else {
+				db.incr(opts.key)
+				db.notify(notifyGeneric, "expire", opts.key)
+			}
 			c.WriteInt(1)
 		})
 	}
@@ -231,7 +235,9 @@ func (m *Miniredis) cmdTouch(c *server.Peer, cmd string, args []string) {
 
 		count := 0
 		for _, key := range args {
-			if db.exists(key) {
+			if _, ok := db.keys[key]; ok {
+				// TOUCH also touches with CLIENT NO-TOUCH on
+				db.lru[key] = m.effectiveNow()
 				count++
 			}
 		}
@@ -341,6 +347,7 @@ func (m *Miniredis) cmdPersist(c *server.Peer, cmd string, args []string) {
 		}
 		delete(db.ttl, key)
 		db.incr(key)
+		db.notify(notifyGeneric, "persist", key)
 		c.WriteInt(1)
 	})
 }
@@ -367,8 +374,9 @@ func (m *Miniredis) cmdDel(c *server.Peer, cmd string, args []string) {
 		for _, key := range args {
 			if db.exists(key) {
 				count++
+				db.del(key, true) // delete expire
+				db.notify(notifyGeneric, "del", key)
 			}
-			db.del(key, true) // delete expire
 		}
 		c.WriteInt(count)
 	})
@@ -393,13 +401,12 @@ func (m *Miniredis) cmdType(c *server.Peer, cmd string, args []string) {
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
 
-		t, ok := db.keys[key]
-		if !ok {
+		if _, ok := db.keys[key]; !ok {
 			c.WriteInline("none")
 			return
 		}
 
-		c.WriteInline(t)
+		c.WriteInline(db.redisType(key))
 	})
 }
 
@@ -464,6 +471,8 @@ func (m *Miniredis) cmdMove(c *server.Peer, cmd string, args []string) {
 			c.WriteInt(0)
 			return
 		}
+		db.notify(notifyGeneric, "move_from", opts.key)
+		targetDB.notify(notifyGeneric, "move_to", opts.key)
 		c.WriteInt(1)
 	})
 }
@@ -516,14 +525,8 @@ func (m *Miniredis) cmdRandomkey(c *server.Peer, cmd string, args []string) {
 			c.WriteNull()
 			return
 		}
-		nr := m.randIntn(len(db.keys))
-		for k := range db.keys {
-			if nr == 0 {
-				c.WriteBulk(k)
-				return
-			}
-			nr--
-		}
+		keys := db.allKeys() // sorted, so Seed() works
+		c.WriteBulk(keys[m.randIntn(len(keys))])
 	})
 }
 
@@ -558,6 +561,8 @@ func (m *Miniredis) cmdRename(c *server.Peer, cmd string, args []string) {
 		}
 
 		db.rename(opts.from, opts.to)
+		db.notify(notifyGeneric, "rename_from", opts.from)
+		db.notify(notifyGeneric, "rename_to", opts.to)
 		c.WriteOK()
 	})
 }
@@ -598,12 +603,14 @@ func (m *Miniredis) cmdRenamenx(c *server.Peer, cmd string, args []string) {
 		}
 
 		db.rename(opts.from, opts.to)
+		db.notify(notifyGeneric, "rename_from", opts.from)
+		db.notify(notifyGeneric, "rename_to", opts.to)
 		c.WriteInt(1)
 	})
 }
 
 type scanOpts struct {
-	cursor    int
+	cursor    uint64
 	count     int
 	withMatch bool
 	match     string
@@ -611,11 +618,18 @@ type scanOpts struct {
 	_type     string
 }
 
+// scanDefaultCount is the COUNT SCAN uses when none is given.
+const scanDefaultCount = 10
+
 func scanParse(cmd string, args []string) (*scanOpts, error) {
-	var opts scanOpts
-	if err := optIntSimple(args[0], &opts.cursor); err != nil {
+	opts := scanOpts{
+		count: scanDefaultCount,
+	}
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
 		return nil, errors.New(msgInvalidCursor)
 	}
+	opts.cursor = cursor
 	args = args[1:]
 
 	// MATCH, COUNT and TYPE options
@@ -625,10 +639,10 @@ func scanParse(cmd string, args []string) (*scanOpts, error) {
 				return nil, errors.New(msgSyntaxError)
 			}
 			count, err := strconv.Atoi(args[1])
-			if err != nil || count < 0 {
+			if err != nil {
 				return nil, errors.New(msgInvalidInt)
 			}
-			if count == 0 {
+			if count < 1 {
 				return nil, errors.New(msgSyntaxError)
 			}
 			opts.count = count
@@ -679,48 +693,26 @@ func (m *Miniredis) cmdScan(c *server.Peer, cmd string, args []string) {
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
-		// We return _all_ (matched) keys every time.
-		var keys []string
+
+		cursor, keys := scanKeys(db.allKeys(), opts.cursor, opts.count)
 
 		if opts.withType {
-			keys = make([]string, 0)
-			for k, t := range db.keys {
+			var typed []string
+			for _, k := range keys {
 				// type must be given exactly; no pattern matching is performed
-				if t == opts._type {
-					keys = append(keys, k)
+				if db.redisType(k) == opts._type {
+					typed = append(typed, k)
 				}
 			}
-		} else {
-			keys = db.allKeys()
+			keys = typed
 		}
 
-		sort.Strings(keys) // To make things deterministic.
-
 		if opts.withMatch {
 			keys, _ = matchKeys(keys, opts.match)
 		}
 
-		low := opts.cursor
-		high := low + opts.count
-		// validate high is correct
-		if high > len(keys) || high == 0 {
-			high = len(keys)
-		}
-		if opts.cursor > high {
-			// invalid cursor
-			c.WriteLen(2)
-			c.WriteBulk("0") // no next cursor
-			c.WriteLen(0)    // no elements
-			return
-		}
-		cursorValue := low + opts.count
-		if cursorValue >= len(keys) {
-			cursorValue = 0 // no next cursor
-		}
-		keys = keys[low:high]
-
 		c.WriteLen(2)
-		c.WriteBulk(fmt.Sprintf("%d", cursorValue))
+		c.WriteBulk(strconv.FormatUint(cursor, 10))
 		c.WriteLen(len(keys))
 		for _, k := range keys {
 			c.WriteBulk(k)
@@ -808,6 +800,7 @@ func (m *Miniredis) cmdCopy(c *server.Peer, cmd string, args []string) {
 		}
 
 		m.copy(m.db(fromDB), opts.from, m.db(toDB), opts.to)
+		m.db(toDB).notify(notifyGeneric, "copy_to", opts.to)
 		c.WriteInt(1)
 	})
 }
@@ -1,7 +1,9 @@
 package miniredis
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -96,6 +98,21 @@ func TestTTL(t *testing.T) {
 		must1(t, c, "EXPIRE", "wim", "-1200")
 		equals(t, false, s.Exists("wim"))
 	}
+
+	t.Run("options", func(t *testing.T) {
+		mustDo(t, c,
+			"EXPIRE", "foo", "12", "FOO",
+			proto.Error("ERR Unsupported option FOO"),
+		)
+		mustDo(t, c,
+			"EXPIRE", "foo", "12", "NX", "XX",
+			proto.Error("ERR NX and XX, GT or LT options at the same time are not compatible"),
+		)
+		mustDo(t, c,
+			"EXPIRE", "foo", "12", "GT", "LT",
+			proto.Error("ERR GT and LT options at the same time are not compatible"),
+		)
+	})
 }
 
 func TestExpireat(t *testing.T) {
@@ -606,6 +623,32 @@ func TestRename(t *testing.T) {
 	})
 }
 
+// scanAll runs a SCAN-like command until the cursor is 0, and returns how
+// often every returned element was seen. cmd is the command with its key, if
+// any, and args go after the cursor.
+func scanAll(t *testing.T, c *proto.Client, cmd []string, args ...string) map[string]int {
+	t.Helper()
+	seen := map[string]int{}
+	cursor := "0"
+	for {
+		full := append(append(append([]string{}, cmd...), cursor), args...)
+		res, err := c.Do(full...)
+		ok(t, err)
+		parts, err := proto.ReadArray(res)
+		ok(t, err)
+		cursor, err = proto.ReadString(parts[0])
+		ok(t, err)
+		keys, err := proto.ReadStrings(parts[1])
+		ok(t, err)
+		for _, k := range keys {
+			seen[k]++
+		}
+		if cursor == "0" {
+			return seen
+		}
+	}
+}
+
 func TestScan(t *testing.T) {
 	s, c := runWithClient(t)
 
@@ -617,8 +660,6 @@ func TestScan(t *testing.T) {
 		})
 	})
 
-	// We cheat with scan. It always returns everything.
-
 	s.Set("key", "value")
 
 	t.Run("no problem", func(t *testing.T) {
@@ -633,12 +674,15 @@ func TestScan(t *testing.T) {
 		)
 	})
 
-	t.Run("invalid cursor", func(t *testing.T) {
+	t.Run("any cursor", func(t *testing.T) {
+		// cursors aren't validated, they are a position in the hash table.
 		mustDo(t, c,
 			"SCAN", "42",
 			proto.Array(
 				proto.String("0"),
-				proto.Array(),
+				proto.Array(
+					proto.String("key"),
+				),
 			),
 		)
 	})
@@ -667,64 +711,115 @@ func TestScan(t *testing.T) {
 		mustDo(t, c,
 			"SCAN", "0", "COUNT", "3",
 			proto.Array(
-				proto.String("3"),
+				proto.String("14"),
 				proto.Array(
-					proto.String("key"),
-					proto.String("v1"),
 					proto.String("v2"),
+					proto.String("v9"),
+					proto.String("v3"),
+					proto.String("v7"),
 				),
 			),
 		)
 
 		mustDo(t, c,
-			"SCAN", "3", "COUNT", "3",
+			"SCAN", "14", "COUNT", "3",
 			proto.Array(
-				proto.String("6"),
+				proto.String("7"),
 				proto.Array(
-					proto.String("v3"),
-					proto.String("v4"),
+					proto.String("key"),
 					proto.String("v5"),
+					proto.String("v6"),
 				),
 			),
 		)
+
+		seen := scanAll(t, c, []string{"SCAN"}, "COUNT", "3")
+		equals(t, 10, len(seen))
+	})
+
+	t.Run("default count", func(t *testing.T) {
+		s, c := runWithClient(t)
+		for i := 0; i < 1000; i++ {
+			s.Set(fmt.Sprintf("key:%d", i), "value")
+		}
+
+		// without COUNT it's COUNT 10
+		res, err := c.Do("SCAN", "0")
+		ok(t, err)
+		parts, err := proto.ReadArray(res)
+		ok(t, err)
+		cursor, err := proto.ReadString(parts[0])
+		ok(t, err)
+		assert(t, cursor != "0", "more to come")
+		keys, err := proto.ReadStrings(parts[1])
+		ok(t, err)
+		assert(t, len(keys) >= 10 && len(keys) < 20, "about 10 keys, got %d", len(keys))
+
+		// every call is a page
+		pages := 0
+		cursor = "0"
+		for {
+			res, err := c.Do("SCAN", cursor, "COUNT", "50")
+			ok(t, err)
+			parts, err := proto.ReadArray(res)
+			ok(t, err)
+			cursor, err = proto.ReadString(parts[0])
+			ok(t, err)
+			keys, err := proto.ReadStrings(parts[1])
+			ok(t, err)
+			assert(t, len(keys) < 60, "page too big: %d", len(keys))
+			pages++
+			if cursor == "0" {
+				break
+			}
+		}
+		assert(t, pages >= 1000/60, "pages: %d", pages)
+
+		equals(t, 1000, len(scanAll(t, c, []string{"SCAN"})))
 	})
 
 	t.Run("match", func(t *testing.T) {
 		s.Set("aap", "noot")
 		s.Set("mies", "wim")
 
-		mustDo(t, c,
-			"SCAN", "0", "MATCH", "mi*",
-			proto.Array(
-				proto.String("0"),
-				proto.Array(
-					proto.String("mies"),
-				),
-			),
-		)
+		seen := scanAll(t, c, []string{"SCAN"}, "MATCH", "mi*")
+		equals(t, map[string]int{"mies": 1}, seen)
 	})
 
 	t.Run("type", func(t *testing.T) {
 		s.SAdd("typetest", "value")
 
-		mustDo(t, c,
-			"SCAN", "0", "TYPE", "set",
-			proto.Array(
-				proto.String("0"),
-				proto.Array(
-					proto.String("typetest"),
-				),
-			),
-		)
-
-		// types aren't checked, they just return an empty array
-		mustDo(t, c,
-			"SCAN", "0", "TYPE", "not-a-type",
-			proto.Array(
-				proto.String("0"),
-				proto.Array(),
-			),
-		)
+		seen := scanAll(t, c, []string{"SCAN"}, "TYPE", "set")
+		equals(t, map[string]int{"typetest": 1}, seen)
+
+		// types aren't checked, they just return nothing
+		seen = scanAll(t, c, []string{"SCAN"}, "TYPE", "not-a-type")
+		equals(t, map[string]int{}, seen)
+
+		for i := 0; i < 20; i++ {
+			s.ZAdd(fmt.Sprintf("typez:%d", i), 1, "m")
+			s.HSet(fmt.Sprintf("typeh:%d", i), "f", "v")
+		}
+		s.Lpush("typel", "v")
+		s.XAdd("types", "0-1", []string{"f", "v"})
+
+		seen = scanAll(t, c, []string{"SCAN"}, "TYPE", "ZSET", "COUNT", "5")
+		equals(t, 20, len(seen))
+		for k := range seen {
+			assert(t, strings.HasPrefix(k, "typez:"), "unexpected key %q", k)
+		}
+
+		seen = scanAll(t, c, []string{"SCAN"}, "TYPE", "hash", "MATCH", "typeh:1*", "COUNT", "3")
+		equals(t, 11, len(seen)) // 1, and 10 to 19
+
+		for typ, key := range map[string]string{
+			"list":   "typel",
+			"stream": "types",
+			"set":    "typetest",
+		} {
+			seen = scanAll(t, c, []string{"SCAN"}, "TYPE", typ, "MATCH", "type*")
+			equals(t, map[string]int{key: 1}, seen)
+		}
 	})
 
 	t.Run("errors", func(t *testing.T) {
@@ -754,7 +849,7 @@ func TestScan(t *testing.T) {
 		)
 		mustDo(t, c,
 			"SCAN", "0", "COUNT", "-1",
-			proto.Error("ERR value is not an integer or out of range"),
+			proto.Error("ERR syntax error"),
 		)
 		mustDo(t, c,
 			"SCAN", "1", "TYPE",
@@ -767,6 +862,45 @@ func TestScan(t *testing.T) {
 	})
 }
 
+// SCAN while keys are added and removed.
+func TestScanConcurrent(t *testing.T) {
+	s, c := runWithClient(t)
+
+	for i := 0; i < 200; i++ {
+		s.Set(fmt.Sprintf("stable:%d", i), "value")
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			// grow and shrink the keyspace
+			key := fmt.Sprintf("churn:%d", i%500)
+			if i%1000 < 500 {
+				s.Set(key, "value")
+			} else {
+				s.Del(key)
+			}
+			time.Sleep(10 * time.Microsecond)
+		}
+	}()
+
+	seen := scanAll(t, c, []string{"SCAN"}, "COUNT", "2")
+	close(done)
+	<-stopped
+
+	for i := 0; i < 200; i++ {
+		k := fmt.Sprintf("stable:%d", i)
+		assert(t, seen[k] > 0, "key %q not returned", k)
+	}
+}
+
 func TestRenamenx(t *testing.T) {
 	s, c := runWithClient(t)
 
@@ -877,6 +1011,43 @@ func TestCopy(t *testing.T) {
 		equals(t, "string", s.Type("rkey2"))
 	})
 
+	t.Run("replace other type", func(t *testing.T) {
+		s.Set("rs", "value")
+		s.HSet("rh", "field", "value")
+		s.SetTTL("rh", time.Minute)
+		must1(t, c, "COPY", "rs", "rh", "REPLACE")
+		equals(t, "string", s.Type("rh"))
+		s.CheckGet(t, "rh", "value")
+		equals(t, time.Duration(0), s.TTL("rh"))
+		mustDo(t, c, "HGET", "rh", "field", proto.Error(msgWrongType))
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		s.Set("t1", "value")
+		s.SetTTL("t1", time.Minute)
+		must1(t, c, "COPY", "t1", "t2", "DB", "3")
+		equals(t, time.Minute, s.DB(3).TTL("t2"))
+		s.FastForward(time.Minute)
+		equals(t, false, s.DB(3).Exists("t2"))
+	})
+
+	t.Run("deep copy", func(t *testing.T) {
+		s.HSet("h1", "f", "v")
+		must1(t, c, "COPY", "h1", "h2")
+		s.HSet("h1", "f", "changed")
+		equals(t, "v", s.HGet("h2", "f"))
+
+		s.SetAdd("s1", "a")
+		must1(t, c, "COPY", "s1", "s2")
+		s.SetAdd("s1", "b")
+		s.CheckSet(t, "s2", "a")
+
+		s.ZAdd("z1", 1, "a")
+		must1(t, c, "COPY", "z1", "z2")
+		s.ZAdd("z1", 2, "a")
+		mustDo(t, c, "ZSCORE", "z2", "a", proto.String("1"))
+	})
+
 	t.Run("direct", func(t *testing.T) {
 		s.Set("d1", "value")
 		ok(t, s.Copy(0, "d1", 0, "d2"))
@@ -894,5 +1065,16 @@ func TestCopy(t *testing.T) {
 		mustDo(t, c, "COPY", "foo", "bar", "baz",
 			proto.Error(msgSyntaxError),
 		)
+		s.Set("same", "value")
+		mustDo(t, c, "COPY", "same", "same",
+			proto.Error("ERR source and destination objects are the same"),
+		)
+		must1(t, c, "COPY", "same", "same", "DB", "4")
+		mustDo(t, c, "COPY", "same", "other", "DB", "-1",
+			proto.Error(msgDBIndexOutOfRange),
+		)
+		mustDo(t, c, "COPY", "same", "other", "DB", "foo",
+			proto.Error(msgInvalidInt),
+		)
 	})
 }
@@ -20,6 +20,7 @@ func commandsGeo(m *Miniredis) {
 	m.srv.Register("GEORADIUS_RO", m.cmdGeoradius)
 	m.srv.Register("GEORADIUSBYMEMBER", m.cmdGeoradiusbymember)
 	m.srv.Register("GEORADIUSBYMEMBER_RO", m.cmdGeoradiusbymember)
+	m.srv.Register("GEOSEARCH", m.cmdGeosearch)
 }
 
 // GEOADD
@@ -326,20 +327,14 @@ func (m *Miniredis) cmdGeoradius(c *server.Peer, cmd string, args []string) {
 
 		// deal with "STORE x"
 		if opts.withStore {
-			db.del(opts.storeKey, true)
-			for _, member := range matches {
-				db.ssetAdd(opts.storeKey, member.Score, member.Name)
-			}
+			storeGeo(db, opts.storeKey, matches, func(member geoDistance) float64 { return member.Score })
 			c.WriteInt(len(matches))
 			return
 		}
 
 		// deal with "STOREDIST x"
 		if opts.withStoredist {
-			db.del(opts.storedistKey, true)
-			for _, member := range matches {
-				db.ssetAdd(opts.storedistKey, member.Distance/toMeter, member.Name)
-			}
+			storeGeo(db, opts.storedistKey, matches, func(member geoDistance) float64 { return member.Distance / toMeter })
 			c.WriteInt(len(matches))
 			return
 		}
@@ -528,20 +523,14 @@ func (m *Miniredis) cmdGeoradiusbymember(c *server.Peer, cmd string, args []stri
 
 		// deal with "STORE x"
 		if opts.withStore {
-			db.del(opts.storeKey, true)
-			for _, member := range matches {
-				db.ssetAdd(opts.storeKey, member.Score, member.Name)
-			}
+			storeGeo(db, opts.storeKey, matches, func(member geoDistance) float64 { return member.Score })
 			c.WriteInt(len(matches))
 			return
 		}
 
 		// deal with "STOREDIST x"
 		if opts.withStoredist {
-			db.del(opts.storedistKey, true)
-			for _, member := range matches {
-				db.ssetAdd(opts.storedistKey, member.Distance/opts.toMeter, member.Name)
-			}
+			storeGeo(db, opts.storedistKey, matches, func(member geoDistance) float64 { return member.Distance / opts.toMeter })
 			c.WriteInt(len(matches))
 			return
 		}
@@ -574,6 +563,268 @@ func (m *Miniredis) cmdGeoradiusbymember(c *server.Peer, cmd string, args []stri
 	})
 }
 
+// GEOSEARCH
+func (m *Miniredis) cmdGeosearch(c *server.Peer, cmd string, args []string) {
+	if len(args) < 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		key        string
+		fromMember bool
+		member     string
+		longitude  float64
+		latitude   float64
+		byRadius   bool
+		radius     float64
+		width      float64
+		height     float64
+		toMeter    float64
+		withDist   bool
+		withCoord  bool
+		withHash   bool
+		direction  direction // unsorted
+		count      int
+		any        bool
+		froms, bys int
+	}
+	opts.key, args = args[0], args[1:]
+
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+		switch strings.ToUpper(arg) {
+		case "FROMMEMBER":
+			if len(args) < 1 {
+				syntaxError(c)
+				return
+			}
+			opts.fromMember = true
+			opts.froms++
+			opts.member, args = args[0], args[1:]
+		case "FROMLONLAT":
+			if len(args) < 2 {
+				syntaxError(c)
+				return
+			}
+			longitude, err := strconv.ParseFloat(args[0], 64)
+			if err != nil {
+				setDirty(c)
+				c.WriteError(msgInvalidFloat)
+				return
+			}
+			latitude, err := strconv.ParseFloat(args[1], 64)
+			if err != nil {
+				setDirty(c)
+				c.WriteError(msgInvalidFloat)
+				return
+			}
+			if latitude < -85.05112878 ||
+				latitude > 85.05112878 ||
+				longitude < -180 ||
+				longitude > 180 {
+				setDirty(c)
+				c.WriteError(fmt.Sprintf("ERR invalid longitude,latitude pair %.6f,%.6f", longitude, latitude))
+				return
+			}
+			opts.froms++
+			opts.longitude, opts.latitude = longitude, latitude
+			args = args[2:]
+		case "BYRADIUS":
+			if len(args) < 2 {
+				syntaxError(c)
+				return
+			}
+			r, err := strconv.ParseFloat(args[0], 64)
+			if err != nil {
+				setDirty(c)
+				c.WriteError("ERR need numeric radius")
+				return
+			}
+			if r < 0 {
+				setDirty(c)
+				c.WriteError("ERR radius cannot be negative")
+				return
+			}
+			toMeter := parseUnit(args[1])
+			if toMeter == 0 {
+				setDirty(c)
+				c.WriteError(msgUnsupportedUnit)
+				return
+			}
+			opts.byRadius = true
+			opts.bys++
+			opts.radius, opts.toMeter = r, toMeter
+			args = args[2:]
+		case "BYBOX":
+			if len(args) < 3 {
+				syntaxError(c)
+				return
+			}
+			w, err := strconv.ParseFloat(args[0], 64)
+			if err != nil {
+				setDirty(c)
+				c.WriteError("ERR need numeric width")
+				return
+			}
+			h, err := strconv.ParseFloat(args[1], 64)
+			if err != nil {
+				setDirty(c)
+				c.WriteError("ERR need numeric height")
+				return
+			}
+			if w < 0 || h < 0 {
+				setDirty(c)
+				c.WriteError("ERR height or width cannot be negative")
+				return
+			}
+			toMeter := parseUnit(args[2])
+			if toMeter == 0 {
+				setDirty(c)
+				c.WriteError(msgUnsupportedUnit)
+				return
+			}
+			opts.bys++
+			opts.width, opts.height, opts.toMeter = w, h, toMeter
+			args = args[3:]
+		case "WITHCOORD":
+			opts.withCoord = true
+		case "WITHDIST":
+			opts.withDist = true
+		case "WITHHASH":
+			opts.withHash = true
+		case "ASC":
+			opts.direction = asc
+		case "DESC":
+			opts.direction = desc
+		case "COUNT":
+			if len(args) == 0 {
+				syntaxError(c)
+				return
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				setDirty(c)
+				c.WriteError(msgInvalidInt)
+				return
+			}
+			if n <= 0 {
+				setDirty(c)
+				c.WriteError("ERR COUNT must be > 0")
+				return
+			}
+			args = args[1:]
+			opts.count = n
+		case "ANY":
+			opts.any = true
+		default:
+			syntaxError(c)
+			return
+		}
+	}
+
+	if opts.froms != 1 {
+		setDirty(c)
+		c.WriteError("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH")
+		return
+	}
+	if opts.bys != 1 {
+		setDirty(c)
+		c.WriteError("ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH")
+		return
+	}
+	if opts.any && opts.count == 0 {
+		setDirty(c)
+		c.WriteError(msgAnyWithoutCount)
+		return
+	}
+	// COUNT without ANY gives the closest matches
+	if opts.count > 0 && !opts.any && opts.direction == unsorted {
+		opts.direction = asc
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+		if !db.exists(opts.key) {
+			c.WriteLen(0)
+			return
+		}
+		if db.t(opts.key) != "zset" {
+			c.WriteError(ErrWrongType.Error())
+			return
+		}
+
+		longitude, latitude := opts.longitude, opts.latitude
+		if opts.fromMember {
+			if !db.ssetExists(opts.key, opts.member) {
+				c.WriteError("ERR could not decode requested zset member")
+				return
+			}
+			longitude, latitude = fromGeohash(uint64(db.ssetScore(opts.key, opts.member)))
+		}
+
+		members := db.ssetElements(opts.key)
+		var matches []geoDistance
+		if opts.byRadius {
+			matches = withinRadius(members, longitude, latitude, opts.radius*opts.toMeter)
+		} else {
+			matches = withinBox(members, longitude, latitude, opts.width*opts.toMeter, opts.height*opts.toMeter)
+		}
+
+		if opts.direction != unsorted {
+			sort.SliceStable(matches, func(i, j int) bool {
+				if opts.direction == desc {
+					return matches[i].Distance > matches[j].Distance
+				}
+				return matches[i].Distance < matches[j].Distance
+			})
+		}
+		if opts.count > 0 && len(matches) > opts.count {
+			matches = matches[:opts.count]
+		}
+
+		c.WriteLen(len(matches))
+		for _, member := range matches {
+			if !opts.withDist && !opts.withCoord && !opts.withHash {
+				c.WriteBulk(member.Name)
+				continue
+			}
+
+			len := 1
+			if opts.withDist {
+				len++
+			}
+			if opts.withHash {
+				len++
+			}
+			if opts.withCoord {
+				len++
+			}
+			c.WriteLen(len)
+			c.WriteBulk(member.Name)
+			if opts.withDist {
+				c.WriteBulk(fmt.Sprintf("%.4f", member.Distance/opts.toMeter))
+			}
+			if opts.withHash {
+				c.WriteInt(int(member.Score))
+			}
+			if opts.withCoord {
+				c.WriteLen(2)
+				c.WriteBulk(fmt.Sprintf("%f", member.Longitude))
+				c.WriteBulk(fmt.Sprintf("%f", member.Latitude))
+			}
+		}
+	})
+}
+
 func withinRadius(members []ssElem, longitude, latitude, radius float64) []geoDistance {
 	matches := []geoDistance{}
 	for _, el := range members {
@@ -593,6 +844,45 @@ func withinRadius(members []ssElem, longitude, latitude, radius float64) []geoDi
 	return matches
 }
 
+// withinBox gives the members inside a width x height (in meters) box,
+// centered on longitude, latitude.
+func withinBox(members []ssElem, longitude, latitude, width, height float64) []geoDistance {
+	matches := []geoDistance{}
+	for _, el := range members {
+		elLo, elLat := fromGeohash(uint64(el.score))
+		// same checks as geohashGetDistanceIfInRectangle() in Redis
+		if distance(latitude, elLo, elLat, elLo) > height/2 {
+			continue
+		}
+		if distance(elLat, longitude, elLat, elLo) > width/2 {
+			continue
+		}
+		matches = append(matches, geoDistance{
+			Name:      el.member,
+			Score:     el.score,
+			Distance:  distance(latitude, longitude, elLat, elLo),
+			Longitude: elLo,
+			Latitude:  elLat,
+		})
+	}
+	return matches
+}
+
+// storeGeo replaces key with a sorted set of the matches, or deletes it if
+// there are none.
+func storeGeo(db *RedisDB, key string, matches []geoDistance, score func(geoDistance) float64) {
+	if len(matches) == 0 {
+		db.del(key, true)
+		return
+	}
+	sset := newSortedSet()
+	for _, member := range matches {
+		sset[member.Name] = score(member)
+	}
+	db.unset(key, true)
+	db.ssetSet(key, sset)
+}
+
 func parseUnit(u string) float64 {
 	switch strings.ToLower(u) {
 	case "m":
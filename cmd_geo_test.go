@@ -419,3 +419,160 @@ func TestGeobymember(t *testing.T) {
 		)
 	})
 }
+
+func TestGeosearch(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"GEOADD", "Sicily",
+		"13.361389", "38.115556", "Palermo",
+		"15.087269", "37.502669", "Catania",
+		"12.758489", "38.788135", "edge1",
+		"17.241510", "38.788135", "edge2",
+		proto.Int(4),
+	)
+
+	t.Run("BYRADIUS", func(t *testing.T) {
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC",
+			proto.Strings("Catania", "Palermo"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "100", "mi", "DESC", "WITHDIST",
+			proto.Array(
+				proto.Array(proto.String("edge1"), proto.String("56.7939")),
+				proto.Array(proto.String("Palermo"), proto.String("0.0000")),
+			),
+		)
+	})
+
+	t.Run("BYBOX", func(t *testing.T) {
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "ASC", "WITHCOORD", "WITHDIST", "WITHHASH",
+			proto.Array(
+				proto.Array(
+					proto.String("Catania"),
+					proto.String("56.4413"),
+					proto.Int(3479447370796909),
+					proto.Strings("15.087267", "37.502668"),
+				),
+				proto.Array(
+					proto.String("Palermo"),
+					proto.String("190.4424"),
+					proto.Int(3479099956230698),
+					proto.Strings("13.361389", "38.115556"),
+				),
+				proto.Array(
+					proto.String("edge2"),
+					proto.String("279.7403"),
+					proto.Int(3481342659049484),
+					proto.Strings("17.241510", "38.788135"),
+				),
+				proto.Array(
+					proto.String("edge1"),
+					proto.String("279.7405"),
+					proto.Int(3479273021651468),
+					proto.Strings("12.758488", "38.788135"),
+				),
+			),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "200", "200", "km", "ASC",
+			proto.Strings("Catania"),
+		)
+	})
+
+	t.Run("COUNT", func(t *testing.T) {
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "500", "km", "COUNT", "2",
+			proto.Strings("Catania", "Palermo"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "500", "km", "COUNT", "1", "DESC",
+			proto.Strings("edge1"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "500", "km", "COUNT", "1", "ANY",
+			proto.Strings("Palermo"),
+		)
+	})
+
+	t.Run("no such key", func(t *testing.T) {
+		mustDo(t, c,
+			"GEOSEARCH", "nosuch", "FROMMEMBER", "foo", "BYRADIUS", "1", "m",
+			proto.Strings(),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"GEOSEARCH",
+			proto.Error(errWrongNumber("geosearch")),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "BYRADIUS", "1", "m",
+			proto.Error("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "FROMLONLAT", "15", "37", "BYRADIUS", "1", "m",
+			proto.Error("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo",
+			proto.Error("ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "BYBOX", "1", "1", "m",
+			proto.Error("ERR exactly one of BYRADIUS and BYBOX can be specified for GEOSEARCH"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "nosuch", "BYRADIUS", "1", "m",
+			proto.Error("ERR could not decode requested zset member"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "foo", "37", "BYRADIUS", "1", "m",
+			proto.Error(msgInvalidFloat),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMLONLAT", "15", "90", "BYRADIUS", "1", "m",
+			proto.Error("ERR invalid longitude,latitude pair 15.000000,90.000000"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "-1", "m",
+			proto.Error("ERR radius cannot be negative"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "parsec",
+			proto.Error(msgUnsupportedUnit),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYBOX", "1", "-1", "m",
+			proto.Error("ERR height or width cannot be negative"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "COUNT", "0",
+			proto.Error("ERR COUNT must be > 0"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "ANY",
+			proto.Error(msgAnyWithoutCount),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "ANY", "COUNT", "1",
+			proto.Strings("Palermo"),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "FOO",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1",
+			proto.Error(msgSyntaxError),
+		)
+		mustOK(t, c, "SET", "str", "value")
+		mustDo(t, c,
+			"GEOSEARCH", "str", "FROMLONLAT", "15", "37", "BYRADIUS", "1", "m",
+			proto.Error(msgWrongType),
+		)
+	})
+}
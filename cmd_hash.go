@@ -236,6 +236,8 @@ func (m *Miniredis) cmdHdel(c *server.Peer, cmd string, args []string) {
 		// Nothing left. Remove the whole key.
 		if len(db.hashKeys[opts.key]) == 0 {
 			db.del(opts.key, true)
+		} else if deleted > 0 {
+			db.incr(opts.key)
 		}
 	})
 }
@@ -605,34 +607,44 @@ func (m *Miniredis) cmdHscan(c *server.Peer, cmd string, args []string) {
 		return
 	}
 
-	opts := struct {
+	var opts struct {
 		key       string
-		cursor    int
+		cursor    uint64
+		count     int
 		withMatch bool
 		match     string
-	}{
-		key: args[0],
 	}
-	if ok := optIntErr(c, args[1], &opts.cursor, msgInvalidCursor); !ok {
+
+	opts.key = args[0]
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		setDirty(c)
+		c.WriteError(msgInvalidCursor)
 		return
 	}
+	opts.cursor = cursor
 	args = args[2:]
 
 	// MATCH and COUNT options
 	for len(args) > 0 {
 		if strings.ToLower(args[0]) == "count" {
-			// we do nothing with count
 			if len(args) < 2 {
 				setDirty(c)
 				c.WriteError(msgSyntaxError)
 				return
 			}
-			_, err := strconv.Atoi(args[1])
+			count, err := strconv.Atoi(args[1])
 			if err != nil {
 				setDirty(c)
 				c.WriteError(msgInvalidInt)
 				return
 			}
+			if count <= 0 {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			opts.count = count
 			args = args[2:]
 			continue
 		}
@@ -653,27 +665,18 @@ func (m *Miniredis) cmdHscan(c *server.Peer, cmd string, args []string) {
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
-		// return _all_ (matched) keys every time
-
-		if opts.cursor != 0 {
-			// Invalid cursor.
-			c.WriteLen(2)
-			c.WriteBulk("0") // no next cursor
-			c.WriteLen(0)    // no elements
-			return
-		}
 		if db.exists(opts.key) && db.t(opts.key) != "hash" {
 			c.WriteError(ErrWrongType.Error())
 			return
 		}
 
-		members := db.hashFields(opts.key)
+		cursor, members := scanMembers(db.hashFields(opts.key), opts.cursor, opts.count)
 		if opts.withMatch {
 			members, _ = matchKeys(members, opts.match)
 		}
 
 		c.WriteLen(2)
-		c.WriteBulk("0") // no next cursor
+		c.WriteBulk(strconv.FormatUint(cursor, 10))
 		// HSCAN gives key, values.
 		c.WriteLen(len(members) * 2)
 		for _, k := range members {
@@ -755,7 +758,17 @@ func (m *Miniredis) cmdHrandfield(c *server.Peer, cmd string, args []string) {
 		}
 
 		if opts.withValues {
-			peer.WriteMapLen(len(members))
+			if peer.Resp3 {
+				// not a map, since there can be duplicates
+				peer.WriteLen(len(members))
+				for _, m := range members {
+					peer.WriteLen(2)
+					peer.WriteBulk(m)
+					peer.WriteBulk(db.hashGet(opts.key, m))
+				}
+				return
+			}
+			peer.WriteLen(len(members) * 2)
 			for _, m := range members {
 				peer.WriteBulk(m)
 				peer.WriteBulk(db.hashGet(opts.key, m))
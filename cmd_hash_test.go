@@ -1,6 +1,7 @@
 package miniredis
 
 import (
+	"fmt"
 	"sort"
 	"testing"
 	"time"
@@ -273,12 +274,13 @@ func TestHashValues(t *testing.T) {
 	s.HSet("wim", "teun", "vuur")
 	s.HSet("wim", "gijs", "lam")
 	s.HSet("wim", "kees", "bok")
+	// in field order, same as HKEYS
 	mustDo(t, c, "HVALS", "wim",
 		proto.Strings(
-			"bok",
-			"jet",
 			"lam",
+			"bok",
 			"vuur",
+			"jet",
 		),
 	)
 
@@ -470,16 +472,7 @@ func TestHscan(t *testing.T) {
 		),
 	)
 
-	// Invalid cursor
-	mustDo(t, c,
-		"HSCAN", "h", "42",
-		proto.Array(
-			proto.String("0"),
-			proto.Array(),
-		),
-	)
-
-	// COUNT (ignored)
+	// COUNT bigger than the hash
 	mustDo(t, c,
 		"HSCAN", "h", "0", "COUNT", "200",
 		proto.Array(
@@ -508,6 +501,17 @@ func TestHscan(t *testing.T) {
 		),
 	)
 
+	t.Run("cursor", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			s.HSet("bighash", fmt.Sprintf("f%d", i), fmt.Sprintf("v%d", i))
+		}
+		seen := scanAll(t, c, []string{"HSCAN", "bighash"}, "COUNT", "10")
+		equals(t, 200, len(seen)) // fields and values
+		for i := 0; i < 100; i++ {
+			equals(t, 1, seen[fmt.Sprintf("f%d", i)])
+		}
+	})
+
 	t.Run("errors", func(t *testing.T) {
 		mustDo(t, c,
 			"HSCAN",
@@ -683,4 +687,22 @@ func TestHashRandField(t *testing.T) {
 		"HRANDFIELD", "wim", "zus",
 		proto.Error(msgInvalidInt),
 	)
+
+	t.Run("RESP3", func(t *testing.T) {
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c.Close()
+		useRESP3(t, c)
+
+		s.HSet("one", "aap", "noot")
+		mustDo(t, c,
+			"HRANDFIELD", "one", "1", "WITHVALUES",
+			proto.Array(proto.Strings("aap", "noot")),
+		)
+		// duplicates, so no map
+		mustDo(t, c,
+			"HRANDFIELD", "one", "-2", "WITHVALUES",
+			proto.Array(proto.Strings("aap", "noot"), proto.Strings("aap", "noot")),
+		)
+	})
 }
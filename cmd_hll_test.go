@@ -1,6 +1,7 @@
 package miniredis
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2/proto"
@@ -20,10 +21,18 @@ func TestPfadd(t *testing.T) {
 		proto.Int(0),
 	)
 
+	// HyperLogLogs are strings in Redis
 	mustDo(t, c,
 		"TYPE", "h",
-		proto.Inline("hll"),
+		proto.Inline("string"),
 	)
+	mustDo(t, c,
+		"SCAN", "0", "TYPE", "string",
+		proto.Array(proto.String("0"), proto.Strings("h")),
+	)
+	v, err := c.Do("GET", "h")
+	ok(t, err)
+	assert(t, strings.HasPrefix(v, "$") && len(v) > 10, "GET gives the raw value")
 
 	t.Run("direct usage", func(t *testing.T) {
 		added, err := s.SetAdd("s1", "aap")
@@ -90,7 +99,7 @@ func TestPfcount(t *testing.T) {
 		proto.Int(3),
 	)
 
-	// Several hlls are involved - a sum of all the counts is returned
+	// Several hlls are involved - the size of their union is returned
 	mustDo(t, c,
 		"PFCOUNT",
 		"h1", // has 101 unique values
@@ -200,7 +209,7 @@ func TestPfmerge(t *testing.T) {
 
 		sum, err := s.PfCount("h5", "h6", "h7") // h7 is empty
 		ok(t, err)
-		equals(t, sum, 8)
+		equals(t, 7, sum) // common elem is counted once
 
 		s.PfMerge("h8", "h5", "h6")
 		sum, err = s.PfCount("h8")
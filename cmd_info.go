@@ -2,6 +2,7 @@ package miniredis
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/alicebob/miniredis/v2/server"
 )
@@ -22,19 +23,77 @@ func (m *Miniredis) cmdInfo(c *server.Peer, cmd string, args []string) {
 		const (
 			clientsSectionName    = "clients"
 			clientsSectionContent = "# Clients\nconnected_clients:%d\r\n"
+			memorySectionName     = "memory"
+			memorySectionContent  = "# Memory\n" +
+				"used_memory:%d\r\n" +
+				"maxmemory:%d\r\n" +
+				"maxmemory_policy:%s\r\n"
+			persistenceSectionName    = "persistence"
+			persistenceSectionContent = "# Persistence\n" +
+				"loading:0\r\n" +
+				"rdb_changes_since_last_save:%d\r\n" +
+				"rdb_bgsave_in_progress:0\r\n" +
+				"rdb_last_save_time:%d\r\n" +
+				"rdb_last_bgsave_status:ok\r\n" +
+				"aof_enabled:0\r\n" +
+				"aof_rewrite_in_progress:0\r\n"
+			replicationSectionName = "replication"
+			masterSectionContent   = "# Replication\n" +
+				"role:master\r\n" +
+				"connected_slaves:%d\r\n"
+			slaveSectionContent = "# Replication\n" +
+				"role:slave\r\n" +
+				"master_host:%s\r\n" +
+				"master_port:%d\r\n" +
+				"master_link_status:up\r\n"
+			statsSectionName    = "stats"
+			statsSectionContent = "# Stats\n" +
+				"evicted_keys:%d\r\n" +
+				"acl_access_denied_auth:%d\r\n"
 		)
 
-		var result string
+		clients := func() string {
+			return fmt.Sprintf(clientsSectionContent, m.Server().ClientsLen())
+		}
+		memory := func() string {
+			return fmt.Sprintf(memorySectionContent, m.usedMemory(), m.configInt("maxmemory"), m.config["maxmemory-policy"])
+		}
+		persistence := func() string {
+			return fmt.Sprintf(persistenceSectionContent, m.dirty, m.lastSave.Unix())
+		}
+		replication := func() string {
+			if m.currentRole() == roleSlave {
+				return fmt.Sprintf(slaveSectionContent, m.masterHost, m.masterPort)
+			}
+			return fmt.Sprintf(masterSectionContent, m.replicas)
+		}
+		stats := func() string {
+			return fmt.Sprintf(statsSectionContent, m.evictedKeys, m.authFailures)
+		}
 
+		var result string
+		if len(args) == 0 {
+			result = clients() + "\r\n" + memory() + "\r\n" + persistence() + "\r\n" + stats() + "\r\n" + replication()
+		}
 		for _, key := range args {
-			if key != clientsSectionName {
+			switch strings.ToLower(key) {
+			case clientsSectionName:
+				result = clients()
+			case memorySectionName:
+				result = memory()
+			case persistenceSectionName:
+				result = persistence()
+			case statsSectionName:
+				result = stats()
+			case replicationSectionName:
+				result = replication()
+			default:
 				setDirty(c)
 				c.WriteError(fmt.Sprintf("section (%s) is not supported", key))
 				return
 			}
 		}
-		result = fmt.Sprintf(clientsSectionContent, m.Server().ClientsLen())
 
-		c.WriteBulk(result)
+		c.WriteVerbatim("txt", result)
 	})
 }
@@ -1,6 +1,9 @@
 package miniredis
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,7 +23,31 @@ func TestMiniredis_cmdInfo(t *testing.T) {
 	t.Run("No section name in args", func(t *testing.T) {
 		mustDo(t, c,
 			"INFO",
-			proto.String("# Clients\nconnected_clients:1\r\n"),
+			proto.String(
+				"# Clients\nconnected_clients:1\r\n"+
+					"\r\n"+
+					"# Memory\n"+
+					"used_memory:0\r\n"+
+					"maxmemory:0\r\n"+
+					"maxmemory_policy:noeviction\r\n"+
+					"\r\n"+
+					"# Persistence\n"+
+					"loading:0\r\n"+
+					"rdb_changes_since_last_save:0\r\n"+
+					"rdb_bgsave_in_progress:0\r\n"+
+					fmt.Sprintf("rdb_last_save_time:%d\r\n", s.lastSave.Unix())+
+					"rdb_last_bgsave_status:ok\r\n"+
+					"aof_enabled:0\r\n"+
+					"aof_rewrite_in_progress:0\r\n"+
+					"\r\n"+
+					"# Stats\n"+
+					"evicted_keys:0\r\n"+
+					"acl_access_denied_auth:0\r\n"+
+					"\r\n"+
+					"# Replication\n"+
+					"role:master\r\n"+
+					"connected_slaves:0\r\n",
+			),
 		)
 	})
 
@@ -48,4 +75,83 @@ func TestMiniredis_cmdInfo(t *testing.T) {
 			proto.String("# Clients\nconnected_clients:2\r\n"),
 		)
 	})
+
+	t.Run("RESP3", func(t *testing.T) {
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c.Close()
+		useRESP3(t, c)
+
+		mustDo(t, c,
+			"INFO", "stats",
+			proto.Verbatim("txt", "# Stats\nevicted_keys:0\r\nacl_access_denied_auth:0\r\n"),
+		)
+	})
+}
+
+func TestInfoPersistence(t *testing.T) {
+	s, c := runWithClient(t)
+
+	changes := func() int {
+		t.Helper()
+		res, err := c.Do("INFO", "persistence")
+		ok(t, err)
+		info, err := proto.ReadString(res)
+		ok(t, err)
+		for _, line := range strings.Split(info, "\r\n") {
+			if v := strings.TrimPrefix(line, "rdb_changes_since_last_save:"); v != line {
+				n, err := strconv.Atoi(v)
+				ok(t, err)
+				return n
+			}
+		}
+		t.Fatalf("no rdb_changes_since_last_save in %q", info)
+		return 0
+	}
+
+	// Every changed key counts once.
+	equals(t, 0, changes())
+	mustOK(t, c, "SET", "foo", "bar")
+	equals(t, 1, changes())
+	mustOK(t, c, "SET", "foo", "baz")
+	equals(t, 2, changes())
+	mustDo(t, c, "RPUSH", "l", "a", "b", proto.Int(2))
+	equals(t, 3, changes())
+	mustDo(t, c, "DEL", "foo", proto.Int(1))
+	equals(t, 4, changes())
+
+	// reads don't count
+	mustNil(t, c, "GET", "foo")
+	mustDo(t, c, "LLEN", "l", proto.Int(2))
+	equals(t, 4, changes())
+
+	// nor do writes which don't change anything
+	must0(t, c, "DEL", "foo")
+	equals(t, 4, changes())
+
+	s.SetTime(time.Unix(1700000000, 0))
+	mustOK(t, c, "SAVE")
+	equals(t, 0, changes())
+	mustDo(t, c, "LASTSAVE", proto.Int(1700000000))
+	mustContain(t, c, "INFO", "persistence", "rdb_last_save_time:1700000000\r\n")
+
+	mustOK(t, c, "SET", "foo", "bar")
+	equals(t, 1, changes())
+	mustDo(t, c, "BGSAVE", proto.Inline("Background saving started"))
+	equals(t, 0, changes())
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"SAVE", "foo",
+			proto.Error(errWrongNumber("save")),
+		)
+		mustDo(t, c,
+			"BGSAVE", "foo",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"LASTSAVE", "foo",
+			proto.Error(errWrongNumber("lastsave")),
+		)
+	})
 }
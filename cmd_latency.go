@@ -0,0 +1,166 @@
+// Commands from https://redis.io/commands#server
+
+package miniredis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+const latencyMaxSamples = 160 // samples kept per event
+
+// latencyEvent is the LATENCY history of a single event.
+type latencyEvent struct {
+	samples []latencySample // oldest first
+	max     time.Duration
+}
+
+type latencySample struct {
+	time    time.Time // truncated to the second
+	latency time.Duration
+}
+
+// commandsLatency handles LATENCY operations.
+func commandsLatency(m *Miniredis) {
+	m.srv.Register("LATENCY", m.cmdLatency)
+}
+
+// LATENCY
+func (m *Miniredis) cmdLatency(c *server.Peer, cmd string, args []string) {
+	if len(args) == 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	switch sub := strings.ToUpper(args[0]); sub {
+	case "LATEST":
+		m.cmdLatencyLatest(c, args[1:])
+	case "HISTORY":
+		m.cmdLatencyHistory(c, args[1:])
+	case "RESET":
+		m.cmdLatencyReset(c, args[1:])
+	default:
+		setDirty(c)
+		c.WriteError(fmt.Sprintf("ERR unknown subcommand '%s'. Try LATENCY HELP.", args[0]))
+	}
+}
+
+// LATENCY LATEST
+func (m *Miniredis) cmdLatencyLatest(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("latency|latest"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		names := make([]string, 0, len(m.latencyEvents))
+		for n := range m.latencyEvents {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		c.WriteLen(len(names))
+		for _, n := range names {
+			ev := m.latencyEvents[n]
+			last := ev.samples[len(ev.samples)-1]
+			c.WriteLen(4)
+			c.WriteBulk(n)
+			c.WriteInt(int(last.time.Unix()))
+			c.WriteInt(int(last.latency.Milliseconds()))
+			c.WriteInt(int(ev.max.Milliseconds()))
+		}
+	})
+}
+
+// LATENCY HISTORY
+func (m *Miniredis) cmdLatencyHistory(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("latency|history"))
+		return
+	}
+	name := args[0]
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		ev, ok := m.latencyEvents[name]
+		if !ok {
+			c.WriteLen(0)
+			return
+		}
+		c.WriteLen(len(ev.samples))
+		for _, s := range ev.samples {
+			c.WriteLen(2)
+			c.WriteInt(int(s.time.Unix()))
+			c.WriteInt(int(s.latency.Milliseconds()))
+		}
+	})
+}
+
+// LATENCY RESET
+func (m *Miniredis) cmdLatencyReset(c *server.Peer, args []string) {
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		if len(args) == 0 {
+			n := len(m.latencyEvents)
+			m.latencyEvents = nil
+			c.WriteInt(n)
+			return
+		}
+		n := 0
+		for _, name := range args {
+			if _, ok := m.latencyEvents[name]; ok {
+				delete(m.latencyEvents, name)
+				n++
+			}
+		}
+		c.WriteInt(n)
+	})
+}
+
+// logLatency adds a "command" latency sample, if it took long enough. Needs
+// the lock.
+func (m *Miniredis) logLatency(d time.Duration) {
+	threshold := m.configInt("latency-monitor-threshold")
+	if threshold <= 0 || d.Milliseconds() < int64(threshold) {
+		return
+	}
+	m.addLatencySample("command", d)
+}
+
+func (m *Miniredis) addLatencySample(name string, d time.Duration) {
+	if m.latencyEvents == nil {
+		m.latencyEvents = map[string]*latencyEvent{}
+	}
+	ev, ok := m.latencyEvents[name]
+	if !ok {
+		ev = &latencyEvent{}
+		m.latencyEvents[name] = ev
+	}
+	if d > ev.max {
+		ev.max = d
+	}
+
+	now := m.effectiveNow().Truncate(time.Second)
+	// one sample per second, with the highest latency
+	if l := len(ev.samples); l > 0 && ev.samples[l-1].time.Equal(now) {
+		if d > ev.samples[l-1].latency {
+			ev.samples[l-1].latency = d
+		}
+		return
+	}
+	ev.samples = append(ev.samples, latencySample{time: now, latency: d})
+	if len(ev.samples) > latencyMaxSamples {
+		ev.samples = ev.samples[1:]
+	}
+}
@@ -0,0 +1,87 @@
+package miniredis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+func TestLatency(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c, "LATENCY", "LATEST", proto.Array())
+
+	mustOK(t, c, "CONFIG", "SET", "latency-monitor-threshold", "5")
+	s.SetTime(time.Unix(1700000000, 0))
+	s.SetCommandLatency("GET", 10*time.Millisecond)
+
+	mustNil(t, c, "GET", "foo")
+	mustOK(t, c, "SET", "foo", "bar")
+
+	res, err := c.Do("LATENCY", "LATEST")
+	ok(t, err)
+	latest, err := proto.Parse(res)
+	ok(t, err)
+	equals(t, 1, len(latest.([]interface{})))
+	ev := latest.([]interface{})[0].([]interface{})
+	equals(t, "command", ev[0])
+	equals(t, 1700000000, ev[1])
+	assert(t, ev[2].(int) >= 10, "latest latency")
+	assert(t, ev[3].(int) >= 10, "max latency")
+
+	// another second, another sample
+	s.SetTime(time.Unix(1700000001, 0))
+	mustNil(t, c, "GET", "foo2")
+	res, err = c.Do("LATENCY", "HISTORY", "command")
+	ok(t, err)
+	history, err := proto.Parse(res)
+	ok(t, err)
+	equals(t, 2, len(history.([]interface{})))
+	equals(t, 1700000001, history.([]interface{})[1].([]interface{})[0])
+
+	mustDo(t, c, "LATENCY", "HISTORY", "nosuch", proto.Array())
+
+	mustDo(t, c, "LATENCY", "RESET", "nosuch", proto.Int(0))
+	mustDo(t, c, "LATENCY", "RESET", proto.Int(1))
+	mustDo(t, c, "LATENCY", "LATEST", proto.Array())
+
+	t.Run("blocking", func(t *testing.T) {
+		// time spent blocked or paused is not a latency spike
+		mustOK(t, c, "CONFIG", "SET", "latency-monitor-threshold", "50")
+		mustDo(t, c, "BLPOP", "nosuch", "0.1", proto.NilList)
+		mustDo(t, c, "LATENCY", "LATEST", proto.Array())
+
+		c2, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c2.Close()
+		mustOK(t, c2, "CLIENT", "PAUSE", "100")
+		mustNil(t, c, "GET", "nosuch")
+		mustDo(t, c, "LATENCY", "LATEST", proto.Array())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mustOK(t, c, "CONFIG", "SET", "latency-monitor-threshold", "0")
+		mustNil(t, c, "GET", "nosuch")
+		mustDo(t, c, "LATENCY", "LATEST", proto.Array())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"LATENCY",
+			proto.Error(errWrongNumber("latency")),
+		)
+		mustDo(t, c,
+			"LATENCY", "FOO",
+			proto.Error("ERR unknown subcommand 'FOO'. Try LATENCY HELP."),
+		)
+		mustDo(t, c,
+			"LATENCY", "HISTORY",
+			proto.Error(errWrongNumber("latency|history")),
+		)
+		mustDo(t, c,
+			"LATENCY", "LATEST", "foo",
+			proto.Error(errWrongNumber("latency|latest")),
+		)
+	})
+}
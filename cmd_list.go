@@ -39,6 +39,8 @@ func commandsList(m *Miniredis) {
 	m.srv.Register("RPUSHX", m.cmdRpushx)
 	m.srv.Register("LMOVE", m.cmdLmove)
 	m.srv.Register("BLMOVE", m.cmdBlmove)
+	m.srv.Register("LMPOP", m.cmdLmpop)
+	m.srv.Register("BLMPOP", m.cmdBlmpop)
 }
 
 // BLPOP
@@ -77,6 +79,7 @@ func (m *Miniredis) cmdBXpop(c *server.Peer, cmd string, args []string, lr leftr
 	blocking(
 		m,
 		c,
+		cmd,
 		opts.timeout,
 		func(c *server.Peer, ctx *connCtx) bool {
 			db := m.db(ctx.selectedDB)
@@ -171,7 +174,7 @@ func (m *Miniredis) cmdLpos(c *server.Peer, cmd string, args []string) {
 		return
 	}
 
-	if len(args) == 1 {
+	if len(args) < 2 {
 		setDirty(c)
 		c.WriteError(errWrongNumber(cmd))
 		return
@@ -236,6 +239,10 @@ func (m *Miniredis) cmdLpos(c *server.Peer, cmd string, args []string) {
 		t, ok := db.keys[key]
 		if !ok {
 			// No such key
+			if countSpecified {
+				c.WriteLen(0)
+				return
+			}
 			c.WriteNull()
 			return
 		}
@@ -536,13 +543,11 @@ func (m *Miniredis) cmdXpush(c *server.Peer, cmd string, args []string, lr leftr
 		}
 
 		var newLen int
-		for _, value := range args {
-			switch lr {
-			case left:
-				newLen = db.listLpush(key, value)
-			case right:
-				newLen = db.listPush(key, value)
-			}
+		switch lr {
+		case left:
+			newLen = db.listLpush(key, args...)
+		case right:
+			newLen = db.listPush(key, args...)
 		}
 		c.WriteInt(newLen)
 	})
@@ -586,13 +591,11 @@ func (m *Miniredis) cmdXpushx(c *server.Peer, cmd string, args []string, lr left
 		}
 
 		var newLen int
-		for _, value := range args {
-			switch lr {
-			case left:
-				newLen = db.listLpush(key, value)
-			case right:
-				newLen = db.listPush(key, value)
-			}
+		switch lr {
+		case left:
+			newLen = db.listLpush(key, args...)
+		case right:
+			newLen = db.listPush(key, args...)
 		}
 		c.WriteInt(newLen)
 	})
@@ -890,6 +893,7 @@ func (m *Miniredis) cmdBrpoplpush(c *server.Peer, cmd string, args []string) {
 	blocking(
 		m,
 		c,
+		cmd,
 		opts.timeout,
 		func(c *server.Peer, ctx *connCtx) bool {
 			db := m.db(ctx.selectedDB)
@@ -1010,6 +1014,7 @@ func (m *Miniredis) cmdBlmove(c *server.Peer, cmd string, args []string) {
 	blocking(
 		m,
 		c,
+		cmd,
 		opts.timeout,
 		func(c *server.Peer, ctx *connCtx) bool {
 			db := m.db(ctx.selectedDB)
@@ -1052,3 +1057,115 @@ func (m *Miniredis) cmdBlmove(c *server.Peer, cmd string, args []string) {
 		},
 	)
 }
+
+// LMPOP
+func (m *Miniredis) cmdLmpop(c *server.Peer, cmd string, args []string) {
+	if len(args) < 3 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		keys  []string
+		where int
+		count int
+	}
+	if ok := optMpop(c, args, [2]string{"LEFT", "RIGHT"}, &opts.keys, &opts.where, &opts.count); !ok {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		if !lmpop(c, db, opts.keys, leftright(opts.where), opts.count) {
+			if c.Resp3 {
+				c.WriteNull()
+				return
+			}
+			c.WriteLen(-1)
+		}
+	})
+}
+
+// BLMPOP
+func (m *Miniredis) cmdBlmpop(c *server.Peer, cmd string, args []string) {
+	if len(args) < 4 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		timeout time.Duration
+		keys    []string
+		where   int
+		count   int
+	}
+	if ok := optDuration(c, args[0], &opts.timeout); !ok {
+		return
+	}
+	if ok := optMpop(c, args[1:], [2]string{"LEFT", "RIGHT"}, &opts.keys, &opts.where, &opts.count); !ok {
+		return
+	}
+
+	blocking(
+		m,
+		c,
+		cmd,
+		opts.timeout,
+		func(c *server.Peer, ctx *connCtx) bool {
+			db := m.db(ctx.selectedDB)
+			return lmpop(c, db, opts.keys, leftright(opts.where), opts.count)
+		},
+		func(c *server.Peer) {
+			// timeout
+			c.WriteLen(-1)
+		},
+	)
+}
+
+// lmpop pops up to count elements from the first non-empty list, and writes
+// the [key, [elements]] reply. Returns false, and writes nothing, if all
+// lists are empty.
+func lmpop(c *server.Peer, db *RedisDB, keys []string, lr leftright, count int) bool {
+	for _, key := range keys {
+		if !db.exists(key) {
+			continue
+		}
+		if db.t(key) != "list" {
+			c.WriteError(msgWrongType)
+			return true
+		}
+		if len(db.listKeys[key]) == 0 {
+			continue
+		}
+
+		var popped []string
+		for len(popped) < count && len(db.listKeys[key]) > 0 {
+			switch lr {
+			case left:
+				popped = append(popped, db.listLpop(key))
+			case right:
+				popped = append(popped, db.listPop(key))
+			}
+		}
+		c.WriteLen(2)
+		c.WriteBulk(key)
+		c.WriteStrings(popped)
+		return true
+	}
+	return false
+}
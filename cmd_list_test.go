@@ -354,6 +354,48 @@ func TestRpop(t *testing.T) {
 		// Can pop non-existing keys just fine.
 		mustNil(t, c, "RPOP", "l")
 	}
+
+	t.Run("with count", func(t *testing.T) {
+		s.Push("l2", "aap", "noot", "mies")
+
+		mustDo(t, c,
+			"RPOP", "l2", "2",
+			proto.Strings("mies", "noot"),
+		)
+
+		s.Push("l2", "vuur", "wim")
+		// more than there are
+		mustDo(t, c,
+			"RPOP", "l2", "99",
+			proto.Strings("wim", "vuur", "aap"),
+		)
+		must0(t, c, "EXISTS", "l2")
+
+		mustDo(t, c,
+			"RPOP", "l2", "2",
+			proto.NilList,
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		s.Push("l3", "aap")
+		mustDo(t, c,
+			"RPOP", "l3", "-1",
+			proto.Error(msgOutOfRange),
+		)
+		mustDo(t, c,
+			"RPOP", "l3", "noint",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"RPOP", "l3", "1", "2",
+			proto.Error(errWrongNumber("rpop")),
+		)
+		mustDo(t, c,
+			"RPOP",
+			proto.Error(errWrongNumber("rpop")),
+		)
+	})
 }
 
 func TestLindex(t *testing.T) {
@@ -662,6 +704,19 @@ func TestLpos(t *testing.T) {
 		mustDo(t, c,
 			"LPOS", "l", "aap", "COUNT", "-1", "MAXLEN", "-1", "RANK", "not_an_int",
 			proto.Error("ERR COUNT can't be negative"))
+
+		mustDo(t, c,
+			"LPOS",
+			proto.Error("ERR wrong number of arguments for 'lpos' command"))
+	})
+
+	t.Run("no such key", func(t *testing.T) {
+		mustNil(t, c, "LPOS", "nosuch", "aap")
+		mustNil(t, c, "LPOS", "nosuch", "aap", "RANK", "-1")
+		mustDo(t, c,
+			"LPOS", "nosuch", "aap", "COUNT", "0",
+			proto.Strings(),
+		)
 	})
 }
 
@@ -1704,3 +1759,137 @@ func TestBlmove(t *testing.T) {
 		}
 	})
 }
+
+func TestLmpop(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("basic", func(t *testing.T) {
+		s.Push("l1", "aap", "noot", "mies")
+		s.Push("l2", "vuur")
+
+		mustDo(t, c,
+			"LMPOP", "3", "nosuch", "l1", "l2", "LEFT",
+			proto.Array(proto.String("l1"), proto.Strings("aap")),
+		)
+		mustDo(t, c,
+			"LMPOP", "2", "l1", "l2", "right", "COUNT", "10",
+			proto.Array(proto.String("l1"), proto.Strings("mies", "noot")),
+		)
+		must0(t, c, "EXISTS", "l1")
+		mustDo(t, c,
+			"LMPOP", "2", "l1", "l2", "RIGHT", "COUNT", "10",
+			proto.Array(proto.String("l2"), proto.Strings("vuur")),
+		)
+		mustNilList(t, c,
+			"LMPOP", "2", "l1", "l2", "LEFT",
+		)
+	})
+
+	t.Run("RESP3", func(t *testing.T) {
+		_, c := runWithClient(t)
+		useRESP3(t, c)
+		mustDo(t, c,
+			"LMPOP", "1", "nosuch", "LEFT",
+			proto.NilResp3,
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"LMPOP", "1", "l",
+			proto.Error(errWrongNumber("lmpop")),
+		)
+		mustDo(t, c,
+			"LMPOP", "foo", "l", "LEFT",
+			proto.Error(msgNumkeysPositive),
+		)
+		mustDo(t, c,
+			"LMPOP", "0", "l", "LEFT",
+			proto.Error(msgNumkeysPositive),
+		)
+		mustDo(t, c,
+			"LMPOP", "2", "l", "LEFT",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"LMPOP", "1", "l", "MIDDLE",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"LMPOP", "1", "l", "LEFT", "COUNT", "0",
+			proto.Error(msgCountPositive),
+		)
+		mustDo(t, c,
+			"LMPOP", "1", "l", "LEFT", "COUNT", "foo",
+			proto.Error(msgCountPositive),
+		)
+		mustDo(t, c,
+			"LMPOP", "1", "l", "LEFT", "COUNT",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"LMPOP", "1", "l", "LEFT", "COUNT", "1", "COUNT", "1",
+			proto.Error(msgSyntaxError),
+		)
+
+		s.Set("str", "value")
+		mustDo(t, c,
+			"LMPOP", "2", "nosuch", "str", "LEFT",
+			proto.Error(msgWrongType),
+		)
+	})
+}
+
+func TestBlmpop(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("basic", func(t *testing.T) {
+		s.Push("l", "aap", "noot", "mies")
+		mustDo(t, c,
+			"BLMPOP", "1", "2", "nosuch", "l", "LEFT", "COUNT", "2",
+			proto.Array(proto.String("l"), proto.Strings("aap", "noot")),
+		)
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		got := goStrings(t, s, "BLMPOP", "0", "1", "lb", "RIGHT")
+		time.Sleep(30 * time.Millisecond)
+
+		mustDo(t, c,
+			"RPUSH", "lb", "aap",
+			proto.Int(1),
+		)
+		select {
+		case have := <-got:
+			equals(t, proto.Array(proto.String("lb"), proto.Strings("aap")), have)
+		case <-time.After(500 * time.Millisecond):
+			t.Error("BLMPOP took too long")
+		}
+		must0(t, c, "EXISTS", "lb")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		got := goStrings(t, s, "BLMPOP", "0.1", "1", "lb", "RIGHT")
+		select {
+		case have := <-got:
+			equals(t, proto.NilList, have)
+		case <-time.After(500 * time.Millisecond):
+			t.Error("BLMPOP took too long")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"BLMPOP", "0", "1", "l",
+			proto.Error(errWrongNumber("blmpop")),
+		)
+		mustDo(t, c,
+			"BLMPOP", "-1", "1", "l", "LEFT",
+			proto.Error(msgTimeoutNegative),
+		)
+		mustDo(t, c,
+			"BLMPOP", "0", "0", "l", "LEFT",
+			proto.Error(msgNumkeysPositive),
+		)
+	})
+}
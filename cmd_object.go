@@ -29,6 +29,12 @@ func (m *Miniredis) cmdObject(c *server.Peer, cmd string, args []string) {
 	switch sub := strings.ToLower(args[0]); sub {
 	case "idletime":
 		m.cmdObjectIdletime(c, args[1:])
+	case "encoding":
+		m.cmdObjectEncoding(c, args[1:])
+	case "freq":
+		m.cmdObjectFreq(c, args[1:])
+	case "refcount":
+		m.cmdObjectRefcount(c, args[1:])
 	default:
 		setDirty(c)
 		c.WriteError(fmt.Sprintf(msgFObjectUsage, sub))
@@ -52,7 +58,85 @@ func (m *Miniredis) cmdObjectIdletime(c *server.Peer, args []string) {
 			c.WriteNull()
 			return
 		}
+		if m.lfuPolicy() {
+			c.WriteError(msgIdletimeLFU)
+			return
+		}
 
 		c.WriteInt(int(db.master.effectiveNow().Sub(t).Seconds()))
 	})
 }
+
+// OBJECT FREQ
+func (m *Miniredis) cmdObjectFreq(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("object|freq"))
+		return
+	}
+	key := args[0]
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		t, ok := db.lru[key]
+		if !ok {
+			c.WriteNull()
+			return
+		}
+		if !m.lfuPolicy() {
+			c.WriteError(msgFreqNoLFU)
+			return
+		}
+
+		// There are no access counters. This is the counter Redis gives a
+		// new key, which goes down by one for every idle minute.
+		freq := lfuInitVal - int(db.master.effectiveNow().Sub(t).Minutes())
+		if freq < 0 {
+			freq = 0
+		}
+		c.WriteInt(freq)
+	})
+}
+
+// OBJECT REFCOUNT
+func (m *Miniredis) cmdObjectRefcount(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("object|refcount"))
+		return
+	}
+	key := args[0]
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		if _, ok := db.keys[key]; !ok {
+			c.WriteNull()
+			return
+		}
+
+		c.WriteInt(db.refcount(key))
+	})
+}
+
+// OBJECT ENCODING
+func (m *Miniredis) cmdObjectEncoding(c *server.Peer, args []string) {
+	if len(args) != 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("object|encoding"))
+		return
+	}
+	key := args[0]
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		if _, ok := db.keys[key]; !ok {
+			c.WriteNull()
+			return
+		}
+
+		c.WriteBulk(db.encoding(key))
+	})
+}
@@ -1,6 +1,9 @@
 package miniredis
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -43,3 +46,407 @@ func TestObjectIdletime(t *testing.T) {
 		)
 	}
 }
+
+// Test OBJECT FREQ, and how maxmemory-policy affects OBJECT FREQ and IDLETIME.
+func TestObjectFreq(t *testing.T) {
+	s, c := runWithClient(t)
+
+	start := time.Now()
+	s.SetTime(start)
+	mustOK(t, c, "SET", "foo", "bar")
+
+	mustDo(t, c,
+		"OBJECT", "FREQ", "foo",
+		proto.Error(msgFreqNoLFU),
+	)
+	mustNil(t, c, "OBJECT", "FREQ", "nosuch")
+
+	mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", "allkeys-lfu")
+	mustDo(t, c,
+		"OBJECT", "FREQ", "foo",
+		proto.Int(5),
+	)
+	mustDo(t, c,
+		"OBJECT", "IDLETIME", "foo",
+		proto.Error(msgIdletimeLFU),
+	)
+	mustNil(t, c, "OBJECT", "IDLETIME", "nosuch")
+
+	s.SetTime(start.Add(3 * time.Minute))
+	mustDo(t, c,
+		"OBJECT", "FREQ", "foo",
+		proto.Int(2),
+	)
+	s.SetTime(start.Add(time.Hour))
+	mustDo(t, c,
+		"OBJECT", "FREQ", "foo",
+		proto.Int(0),
+	)
+
+	mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", "volatile-lru")
+	mustDo(t, c,
+		"OBJECT", "IDLETIME", "foo",
+		proto.Int(3600),
+	)
+	mustDo(t, c,
+		"OBJECT", "FREQ", "foo",
+		proto.Error(msgFreqNoLFU),
+	)
+
+	mustDo(t, c,
+		"OBJECT", "FREQ",
+		proto.Error("ERR wrong number of arguments for 'object|freq' command"),
+	)
+}
+
+// Test OBJECT ENCODING.
+func TestObjectEncoding(t *testing.T) {
+	t.Run("set", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c, "SADD", "s", "1", "2", "3", proto.Int(3))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("intset"))
+
+		mustDo(t, c, "SADD", "s", "foo", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("listpack"))
+
+		mustDo(t, c, "SADD", "s", strings.Repeat("x", 65), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("hashtable"))
+
+		mustDo(t, c, "SADD", "s2", "007", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "s2", proto.String("listpack"))
+
+		mustNil(t, c, "OBJECT", "ENCODING", "nosuch")
+	})
+
+	t.Run("set thresholds", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustOK(t, c, "CONFIG", "SET", "set-max-intset-entries", "2")
+		mustDo(t, c, "SADD", "s", "1", "2", proto.Int(2))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("intset"))
+		mustDo(t, c, "SADD", "s", "3", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("hashtable"))
+
+		mustOK(t, c, "CONFIG", "SET", "set-max-listpack-entries", "2")
+		mustDo(t, c, "SADD", "l", "a", "b", proto.Int(2))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+		mustDo(t, c, "SADD", "l", "c", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("hashtable"))
+	})
+
+	t.Run("zset", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c, "ZADD", "z", "1", "one", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z", proto.String("listpack"))
+
+		for i := 0; i < 127; i++ {
+			mustDo(t, c, "ZADD", "z", strconv.Itoa(i), fmt.Sprintf("m%d", i), proto.Int(1))
+		}
+		mustDo(t, c, "OBJECT", "ENCODING", "z", proto.String("listpack"))
+		mustDo(t, c, "ZADD", "z", "200", "last", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z", proto.String("skiplist"))
+
+		mustDo(t, c, "ZADD", "z2", "1", strings.Repeat("x", 64), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z2", proto.String("listpack"))
+		mustDo(t, c, "ZADD", "z2", "2", strings.Repeat("x", 65), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z2", proto.String("skiplist"))
+	})
+
+	t.Run("zset thresholds", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustOK(t, c, "CONFIG", "SET", "zset-max-listpack-entries", "2")
+		mustDo(t, c, "ZADD", "z", "1", "a", "2", "b", proto.Int(2))
+		mustDo(t, c, "OBJECT", "ENCODING", "z", proto.String("listpack"))
+		mustDo(t, c, "ZADD", "z", "3", "c", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z", proto.String("skiplist"))
+
+		mustOK(t, c, "CONFIG", "SET", "zset-max-listpack-value", "3")
+		mustDo(t, c, "ZADD", "z2", "1", "abc", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z2", proto.String("listpack"))
+		mustDo(t, c, "ZADD", "z2", "1", "abcd", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "z2", proto.String("skiplist"))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustOK(t, c, "SET", "s", "12345")
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("int"))
+		mustOK(t, c, "SET", "s", "-9223372036854775808")
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("int"))
+		mustOK(t, c, "SET", "s", "007")
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("embstr"))
+		mustOK(t, c, "SET", "s", strings.Repeat("x", 44))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("embstr"))
+		mustOK(t, c, "SET", "s", strings.Repeat("x", 45))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("raw"))
+
+		mustDo(t, c, "PFADD", "h", "a", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h", proto.String("raw"))
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c, "HSET", "h", "a", "1", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h", proto.String("listpack"))
+		mustDo(t, c, "HSET", "h", "b", strings.Repeat("x", 65), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h", proto.String("hashtable"))
+
+		mustDo(t, c, "HSET", "h2", strings.Repeat("x", 65), "1", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h2", proto.String("hashtable"))
+
+		for i := 0; i < 128; i++ {
+			mustDo(t, c, "HSET", "h3", strconv.Itoa(i), "v", proto.Int(1))
+		}
+		mustDo(t, c, "OBJECT", "ENCODING", "h3", proto.String("listpack"))
+		mustDo(t, c, "HSET", "h3", "last", "v", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h3", proto.String("hashtable"))
+	})
+
+	t.Run("hash thresholds", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustOK(t, c, "CONFIG", "SET", "hash-max-listpack-entries", "1")
+		mustDo(t, c, "HSET", "h", "a", "1", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h", proto.String("listpack"))
+		mustDo(t, c, "HSET", "h", "b", "2", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h", proto.String("hashtable"))
+
+		mustOK(t, c, "CONFIG", "SET", "hash-max-listpack-value", "3")
+		mustDo(t, c, "HSET", "h2", "a", "abcd", proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "h2", proto.String("hashtable"))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c, "RPUSH", "l", "a", "1", proto.Int(2))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+
+		// default is 8kb
+		mustDo(t, c, "RPUSH", "l", strings.Repeat("x", 8000), proto.Int(3))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+		mustDo(t, c, "RPUSH", "l", strings.Repeat("x", 200), proto.Int(4))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("quicklist"))
+	})
+
+	t.Run("list thresholds", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustOK(t, c, "CONFIG", "SET", "list-max-listpack-size", "2")
+		mustDo(t, c, "RPUSH", "l", "a", "b", proto.Int(2))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("listpack"))
+		mustDo(t, c, "RPUSH", "l", "c", proto.Int(3))
+		mustDo(t, c, "OBJECT", "ENCODING", "l", proto.String("quicklist"))
+
+		mustOK(t, c, "CONFIG", "SET", "list-max-listpack-size", "-1")
+		mustDo(t, c, "RPUSH", "l2", strings.Repeat("x", 4000), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "l2", proto.String("listpack"))
+		mustDo(t, c, "RPUSH", "l2", strings.Repeat("x", 100), proto.Int(2))
+		mustDo(t, c, "OBJECT", "ENCODING", "l2", proto.String("quicklist"))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c, "XADD", "s", "0-1", "a", "b", proto.String("0-1"))
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("stream"))
+
+		// empty streams exist
+		must1(t, c, "XDEL", "s", "0-1")
+		mustDo(t, c, "OBJECT", "ENCODING", "s", proto.String("stream"))
+		mustOK(t, c, "XGROUP", "CREATE", "s2", "grp", "$", "MKSTREAM")
+		mustDo(t, c, "OBJECT", "ENCODING", "s2", proto.String("stream"))
+
+		// empty lists, sets, hashes, and sorted sets don't
+		mustDo(t, c, "RPUSH", "l", "a", proto.Int(1))
+		mustDo(t, c, "LPOP", "l", proto.String("a"))
+		mustNil(t, c, "OBJECT", "ENCODING", "l")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, c := runWithClient(t)
+
+		mustDo(t, c,
+			"OBJECT", "ENCODING",
+			proto.Error("ERR wrong number of arguments for 'object|encoding' command"),
+		)
+	})
+}
+
+// Test OBJECT REFCOUNT.
+func TestObjectRefcount(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustOK(t, c, "SET", "shared", "9999")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "shared", proto.Int(2147483647))
+	mustOK(t, c, "SET", "zero", "0")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "zero", proto.Int(2147483647))
+
+	mustOK(t, c, "SET", "big", "10000")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "big", proto.Int(1))
+	mustOK(t, c, "SET", "neg", "-1")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "neg", proto.Int(1))
+	mustOK(t, c, "SET", "padded", "007")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "padded", proto.Int(1))
+	mustOK(t, c, "SET", "str", "foo")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "str", proto.Int(1))
+	mustDo(t, c, "RPUSH", "l", "1", proto.Int(1))
+	mustDo(t, c, "OBJECT", "REFCOUNT", "l", proto.Int(1))
+	mustNil(t, c, "OBJECT", "REFCOUNT", "nosuch")
+
+	s.SetMaxMemory(1 << 20)
+	mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", "allkeys-lru")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "shared", proto.Int(1))
+	mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", "allkeys-random")
+	mustDo(t, c, "OBJECT", "REFCOUNT", "shared", proto.Int(2147483647))
+
+	mustDo(t, c,
+		"OBJECT", "REFCOUNT",
+		proto.Error("ERR wrong number of arguments for 'object|refcount' command"),
+	)
+}
+
+// Test OBJECT ENCODING just below, at, and just over every limit.
+func TestObjectEncodingBoundaries(t *testing.T) {
+	// n elements, from element(0) to element(n-1)
+	elements := func(n int, element func(i int) string) []string {
+		var res []string
+		for i := 0; i < n; i++ {
+			res = append(res, element(i))
+		}
+		return res
+	}
+	member := func(i int) string { return fmt.Sprintf("m%d", i) }
+	number := func(i int) string { return strconv.Itoa(i) }
+	pairs := func(n int) []string {
+		var res []string
+		for i := 0; i < n; i++ {
+			res = append(res, strconv.Itoa(i), member(i))
+		}
+		return res
+	}
+
+	type cas struct {
+		config    []string // CONFIG SET pairs
+		create    func(n int) []string
+		threshold int
+		below, at string // OBJECT ENCODING at threshold-1 and threshold
+		over      string // OBJECT ENCODING at threshold+1
+	}
+	for name, cas := range map[string]cas{
+		"set intset entries": {
+			config:    []string{"set-max-intset-entries", "10"},
+			create:    func(n int) []string { return append([]string{"SADD", "k"}, elements(n, number)...) },
+			threshold: 10,
+			below:     "intset", at: "intset", over: "hashtable",
+		},
+		"set listpack entries": {
+			config:    []string{"set-max-listpack-entries", "10"},
+			create:    func(n int) []string { return append([]string{"SADD", "k"}, elements(n, member)...) },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "hashtable",
+		},
+		"set listpack value": {
+			config:    []string{"set-max-listpack-value", "10"},
+			create:    func(n int) []string { return []string{"SADD", "k", "a", strings.Repeat("x", n)} },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "hashtable",
+		},
+		"hash entries": {
+			config:    []string{"hash-max-listpack-entries", "10"},
+			create:    func(n int) []string { return append([]string{"HSET", "k"}, pairs(n)...) },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "hashtable",
+		},
+		"hash value": {
+			config:    []string{"hash-max-listpack-value", "10"},
+			create:    func(n int) []string { return []string{"HSET", "k", "f", strings.Repeat("x", n)} },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "hashtable",
+		},
+		"hash field": {
+			config:    []string{"hash-max-listpack-value", "10"},
+			create:    func(n int) []string { return []string{"HSET", "k", strings.Repeat("x", n), "v"} },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "hashtable",
+		},
+		"zset entries": {
+			config:    []string{"zset-max-listpack-entries", "10"},
+			create:    func(n int) []string { return append([]string{"ZADD", "k"}, pairs(n)...) },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "skiplist",
+		},
+		"zset value": {
+			config:    []string{"zset-max-listpack-value", "10"},
+			create:    func(n int) []string { return []string{"ZADD", "k", "1", strings.Repeat("x", n)} },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "skiplist",
+		},
+		"list entries": {
+			config:    []string{"list-max-listpack-size", "10"},
+			create:    func(n int) []string { return append([]string{"RPUSH", "k"}, elements(n, member)...) },
+			threshold: 10,
+			below:     "listpack", at: "listpack", over: "quicklist",
+		},
+		"list entries, zero": {
+			// a node always fits a single element
+			config:    []string{"list-max-listpack-size", "0"},
+			create:    func(n int) []string { return append([]string{"RPUSH", "k"}, elements(n, member)...) },
+			threshold: 1,
+			below:     "", at: "listpack", over: "quicklist",
+		},
+		"string": {
+			create:    func(n int) []string { return []string{"SET", "k", strings.Repeat("x", n)} },
+			threshold: 44,
+			below:     "embstr", at: "embstr", over: "raw",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, n := range []int{cas.threshold - 1, cas.threshold, cas.threshold + 1} {
+				_, c := runWithClient(t)
+				if cas.config != nil {
+					mustOK(t, c, append([]string{"CONFIG", "SET"}, cas.config...)...)
+				}
+				want := cas.over
+				switch n {
+				case cas.threshold - 1:
+					want = cas.below
+				case cas.threshold:
+					want = cas.at
+				}
+				if want == "" {
+					continue
+				}
+				_, err := c.Do(cas.create(n)...)
+				ok(t, err)
+				mustDo(t, c, "OBJECT", "ENCODING", "k", proto.String(want))
+			}
+		})
+	}
+
+	t.Run("packed threshold", func(t *testing.T) {
+		_, c := runWithClient(t)
+		mustOK(t, c, "DEBUG", "QUICKLIST-PACKED-THRESHOLD", "100")
+		mustDo(t, c, "RPUSH", "l99", strings.Repeat("x", 99), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "l99", proto.String("listpack"))
+		mustDo(t, c, "RPUSH", "l100", strings.Repeat("x", 100), proto.Int(1))
+		mustDo(t, c, "OBJECT", "ENCODING", "l100", proto.String("quicklist"))
+	})
+
+	t.Run("int strings", func(t *testing.T) {
+		_, c := runWithClient(t)
+		mustOK(t, c, "SET", "k", "9223372036854775807")
+		mustDo(t, c, "OBJECT", "ENCODING", "k", proto.String("int"))
+		mustOK(t, c, "SET", "k", "-9223372036854775808")
+		mustDo(t, c, "OBJECT", "ENCODING", "k", proto.String("int"))
+		mustOK(t, c, "SET", "k", "9223372036854775808")
+		mustDo(t, c, "OBJECT", "ENCODING", "k", proto.String("embstr"))
+		mustOK(t, c, "SET", "k", "-0")
+		mustDo(t, c, "OBJECT", "ENCODING", "k", proto.String("embstr"))
+	})
+}
@@ -366,6 +366,72 @@ func TestPublish(t *testing.T) {
 	)
 }
 
+func TestPublishCount(t *testing.T) {
+	s := RunT(t)
+	c1, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c1.Close()
+	c2, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c2.Close()
+
+	mustDo(t, c1,
+		"SUBSCRIBE", "event1",
+		proto.Array(proto.String("subscribe"), proto.String("event1"), proto.Int(1)),
+	)
+	mustDo(t, c2,
+		"PSUBSCRIBE", "event*",
+		proto.Array(proto.String("psubscribe"), proto.String("event*"), proto.Int(1)),
+	)
+	mustDo(t, c2,
+		"PSUBSCRIBE", "e*",
+		proto.Array(proto.String("psubscribe"), proto.String("e*"), proto.Int(2)),
+	)
+
+	// one for the channel, one for every matching pattern
+	equals(t, 3, s.Publish("event1", "hello"))
+	mustRead(t, c1,
+		proto.Strings("message", "event1", "hello"),
+	)
+	mustRead(t, c2,
+		proto.Strings("pmessage", "e*", "event1", "hello"),
+	)
+	mustRead(t, c2,
+		proto.Strings("pmessage", "event*", "event1", "hello"),
+	)
+
+	equals(t, 1, s.Publish("e2", "hi"))
+	mustRead(t, c2,
+		proto.Strings("pmessage", "e*", "e2", "hi"),
+	)
+
+	equals(t, 0, s.Publish("nosuch", "hi"))
+}
+
+func TestSubscribeDirect(t *testing.T) {
+	s, c := runWithClient(t)
+
+	msgs, cancel := s.Subscribe("event1")
+
+	must1(t, c, "PUBLISH", "event1", "message1")
+	must0(t, c, "PUBLISH", "event2", "nosuch")
+	must1(t, c, "PUBLISH", "event1", "message2")
+	equals(t, 1, s.Publish("event1", "message3"))
+	mustDo(t, c,
+		"PUBSUB", "NUMSUB", "event1",
+		proto.Array(proto.String("event1"), proto.Int(1)),
+	)
+
+	equals(t, PubsubMessage{"event1", "message1"}, <-msgs)
+	equals(t, PubsubMessage{"event1", "message2"}, <-msgs)
+	equals(t, PubsubMessage{"event1", "message3"}, <-msgs)
+
+	cancel()
+	_, open := <-msgs
+	equals(t, false, open)
+	must0(t, c, "PUBLISH", "event1", "message4")
+}
+
 func TestPublishMix(t *testing.T) {
 	// SUBSCRIBE and PSUBSCRIBE
 	_, c := runWithClient(t)
@@ -17,8 +17,10 @@ import (
 )
 
 func commandsScripting(m *Miniredis) {
-	m.srv.Register("EVAL", m.cmdEval)
-	m.srv.Register("EVALSHA", m.cmdEvalsha)
+	m.srv.Register("EVAL", m.makeCmdEval(false))
+	m.srv.Register("EVAL_RO", m.makeCmdEval(true))
+	m.srv.Register("EVALSHA", m.makeCmdEvalsha(false))
+	m.srv.Register("EVALSHA_RO", m.makeCmdEvalsha(true))
 	m.srv.Register("SCRIPT", m.cmdScript)
 }
 
@@ -27,36 +29,12 @@ var (
 )
 
 // Execute lua. Needs to run m.Lock()ed, from within withTx().
-// Returns true if the lua was OK (and hence should be cached).
-func (m *Miniredis) runLuaScript(c *server.Peer, sha, script string, args []string) bool {
-	l := lua.NewState(lua.Options{SkipOpenLibs: true})
+// Returns true if the lua was OK (and hence should be cached). With readOnly
+// set the script can't use write commands.
+func (m *Miniredis) runLuaScript(c *server.Peer, sha, script string, readOnly bool, args []string) bool {
+	l := newLuaState()
 	defer l.Close()
 
-	// Taken from the go-lua manual
-	for _, pair := range []struct {
-		n string
-		f lua.LGFunction
-	}{
-		{lua.LoadLibName, lua.OpenPackage},
-		{lua.BaseLibName, lua.OpenBase},
-		{lua.CoroutineLibName, lua.OpenCoroutine},
-		{lua.TabLibName, lua.OpenTable},
-		{lua.StringLibName, lua.OpenString},
-		{lua.MathLibName, lua.OpenMath},
-		{lua.DebugLibName, lua.OpenDebug},
-	} {
-		if err := l.CallByParam(lua.P{
-			Fn:      l.NewFunction(pair.f),
-			NRet:    0,
-			Protect: true,
-		}, lua.LString(pair.n)); err != nil {
-			panic(err)
-		}
-	}
-
-	luajson.Preload(l)
-	requireGlobal(l, "cjson", "json")
-
 	// set global variable KEYS
 	keysTable := l.NewTable()
 	keysS, args := args[0], args[1:]
@@ -85,11 +63,61 @@ func (m *Miniredis) runLuaScript(c *server.Peer, sha, script string, args []stri
 	}
 	l.SetGlobal("ARGV", argvTable)
 
-	redisFuncs, redisConstants := mkLua(m.srv, c, sha)
-	// Register command handlers
+	redisFuncs, redisConstants := mkLua(m.srv, c, sha, readOnly, false)
+	openRedis(l, redisFuncs, redisConstants)
+
+	// lua can call redis.setresp(...), but it's tmp state.
+	oldresp := c.Resp3
+	defer func() {
+		c.SetResp3(oldresp)
+		c.SwitchResp3 = nil
+	}()
+	if err := doScript(l, script); err != nil {
+		c.WriteError(err.Error())
+		return false
+	}
+
+	luaToRedis(l, c, l.Get(1))
+	return true
+}
+
+// newLuaState makes a Lua state with the standard libraries and cjson
+// loaded.
+func newLuaState() *lua.LState {
+	l := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	// Taken from the go-lua manual
+	for _, pair := range []struct {
+		n string
+		f lua.LGFunction
+	}{
+		{lua.LoadLibName, lua.OpenPackage},
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.CoroutineLibName, lua.OpenCoroutine},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.DebugLibName, lua.OpenDebug},
+	} {
+		if err := l.CallByParam(lua.P{
+			Fn:      l.NewFunction(pair.f),
+			NRet:    0,
+			Protect: true,
+		}, lua.LString(pair.n)); err != nil {
+			panic(err)
+		}
+	}
+
+	luajson.Preload(l)
+	requireGlobal(l, "cjson", "json")
+	return l
+}
+
+// openRedis registers the global 'redis' module, and protects the globals.
+func openRedis(l *lua.LState, funcs map[string]lua.LGFunction, constants map[string]lua.LValue) {
 	l.Push(l.NewFunction(func(l *lua.LState) int {
-		mod := l.RegisterModule("redis", redisFuncs).(*lua.LTable)
-		for k, v := range redisConstants {
+		mod := l.RegisterModule("redis", funcs).(*lua.LTable)
+		for k, v := range constants {
 			mod.RawSetString(k, v)
 		}
 		l.Push(mod)
@@ -100,18 +128,6 @@ func (m *Miniredis) runLuaScript(c *server.Peer, sha, script string, args []stri
 
 	l.Push(lua.LString("redis"))
 	l.Call(1, 0)
-
-	// lua can call redis.setresp(...), but it's tmp state.
-	oldresp := c.Resp3
-	if err := doScript(l, script); err != nil {
-		c.WriteError(err.Error())
-		return false
-	}
-
-	luaToRedis(l, c, l.Get(1))
-	c.Resp3 = oldresp
-	c.SwitchResp3 = nil
-	return true
 }
 
 // doScript pre-compiles the given script into a Lua prototype,
@@ -150,64 +166,71 @@ func compile(script string) (*lua.FunctionProto, error) {
 	return proto, nil
 }
 
-func (m *Miniredis) cmdEval(c *server.Peer, cmd string, args []string) {
-	if len(args) < 2 {
-		setDirty(c)
-		c.WriteError(errWrongNumber(cmd))
-		return
-	}
-	if !m.handleAuth(c) {
-		return
-	}
-	if m.checkPubsub(c, cmd) {
-		return
-	}
-	ctx := getCtx(c)
-	if ctx.nested {
-		c.WriteError(msgNotFromScripts(ctx.nestedSHA))
-		return
-	}
-
-	script, args := args[0], args[1:]
-
-	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
-		sha := sha1Hex(script)
-		ok := m.runLuaScript(c, sha, script, args)
-		if ok {
-			m.scripts[sha] = script
+// EVAL and EVAL_RO
+func (m *Miniredis) makeCmdEval(readOnly bool) server.Cmd {
+	return func(c *server.Peer, cmd string, args []string) {
+		if len(args) < 2 {
+			setDirty(c)
+			c.WriteError(errWrongNumber(cmd))
+			return
+		}
+		if !m.handleAuth(c) {
+			return
+		}
+		if m.checkPubsub(c, cmd) {
+			return
+		}
+		ctx := getCtx(c)
+		if ctx.nested {
+			c.WriteError(msgNotFromScripts(ctx.nestedSHA))
+			return
 		}
-	})
-}
 
-func (m *Miniredis) cmdEvalsha(c *server.Peer, cmd string, args []string) {
-	if len(args) < 2 {
-		setDirty(c)
-		c.WriteError(errWrongNumber(cmd))
-		return
-	}
-	if !m.handleAuth(c) {
-		return
-	}
-	if m.checkPubsub(c, cmd) {
-		return
-	}
-	ctx := getCtx(c)
-	if ctx.nested {
-		c.WriteError(msgNotFromScripts(ctx.nestedSHA))
-		return
-	}
+		script, args := args[0], args[1:]
 
-	sha, args := args[0], args[1:]
+		withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+			sha := sha1Hex(script)
+			ok := m.runLuaScript(c, sha, script, readOnly, args)
+			if ok {
+				m.scripts[sha] = script
+			}
+		})
+	}
+}
 
-	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
-		script, ok := m.scripts[sha]
-		if !ok {
-			c.WriteError(msgNoScriptFound)
+// EVALSHA and EVALSHA_RO
+func (m *Miniredis) makeCmdEvalsha(readOnly bool) server.Cmd {
+	return func(c *server.Peer, cmd string, args []string) {
+		if len(args) < 2 {
+			setDirty(c)
+			c.WriteError(errWrongNumber(cmd))
+			return
+		}
+		if !m.handleAuth(c) {
+			return
+		}
+		if m.checkPubsub(c, cmd) {
+			return
+		}
+		ctx := getCtx(c)
+		if ctx.nested {
+			c.WriteError(msgNotFromScripts(ctx.nestedSHA))
 			return
 		}
 
-		m.runLuaScript(c, sha, script, args)
-	})
+		// SHAs are case insensitive
+		sha, args := strings.ToLower(args[0]), args[1:]
+
+		withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+			script, ok := m.scripts[sha]
+			if !ok {
+				c.WriteError(msgNoScriptFound)
+				return
+			}
+
+			m.runLuaScript(c, sha, script, readOnly, args)
+		})
+	}
 }
 
 func (m *Miniredis) cmdScript(c *server.Peer, cmd string, args []string) {
@@ -282,7 +305,7 @@ func (m *Miniredis) cmdScript(c *server.Peer, cmd string, args []string) {
 		case "exists":
 			c.WriteLen(len(args))
 			for _, arg := range args {
-				if _, ok := m.scripts[arg]; ok {
+				if _, ok := m.scripts[strings.ToLower(arg)]; ok {
 					c.WriteInt(1)
 				} else {
 					c.WriteInt(0)
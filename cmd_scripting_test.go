@@ -1,6 +1,7 @@
 package miniredis
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2/proto"
@@ -289,6 +290,33 @@ func TestEvalsha(t *testing.T) {
 		"EVALSHA", "foo", "1", "bar",
 		proto.Error(msgNoScriptFound),
 	)
+
+	t.Run("sha", func(t *testing.T) {
+		mustDo(t, c,
+			"EVALSHA", "0123456789abcdef0123456789abcdef01234567", "0",
+			proto.Error("NOSCRIPT No matching script. Please use EVAL."),
+		)
+		// too long
+		mustDo(t, c,
+			"EVALSHA", script1sha+"0", "0",
+			proto.Error(msgNoScriptFound),
+		)
+		mustDo(t, c,
+			"EVALSHA", script1sha[:39], "0",
+			proto.Error(msgNoScriptFound),
+		)
+
+		// case insensitive
+		upper := strings.ToUpper(script1sha)
+		mustDo(t, c,
+			"EVALSHA", upper, "1", "key1", "key2",
+			proto.Strings("key1", "key2"),
+		)
+		mustDo(t, c,
+			"SCRIPT", "EXISTS", upper,
+			proto.Ints(1),
+		)
+	})
 }
 
 func TestCmdEvalReply(t *testing.T) {
@@ -469,6 +497,12 @@ func TestCmdEvalResponse(t *testing.T) {
 		"EVAL", "return redis.call('HMGET','mkey', 'bad', 'key')", "0",
 		proto.Array(proto.Nil, proto.Nil),
 	)
+	// redis.setresp() only lasts for the script, even if it fails
+	mustContain(t, c,
+		"EVAL", "redis.setresp(3); error('boom')", "0",
+		"boom",
+	)
+	mustContain(t, c, "CLIENT", "INFO", " resp=2 ")
 }
 
 func TestCmdEvalAuth(t *testing.T) {
@@ -597,3 +631,100 @@ func TestLuaTX(t *testing.T) {
 		)
 	})
 }
+
+func TestEvalRO(t *testing.T) {
+	s, c := runWithClient(t)
+	s.Set("foo", "bar")
+
+	mustDo(t, c,
+		"EVAL_RO", "return redis.call('GET', KEYS[1])", "1", "foo",
+		proto.String("bar"),
+	)
+	mustContain(t, c,
+		"EVAL_RO", "return redis.call('SET', KEYS[1], 'baz')", "1", "foo",
+		msgWriteFromReadOnly,
+	)
+	s.CheckGet(t, "foo", "bar")
+
+	// pcall gives the error
+	mustContain(t, c,
+		"EVAL_RO", "return redis.pcall('DEL', KEYS[1])", "1", "foo",
+		msgWriteFromReadOnly,
+	)
+	s.CheckGet(t, "foo", "bar")
+
+	t.Run("evalsha", func(t *testing.T) {
+		sha := sha1Hex("return redis.call('SET', KEYS[1], ARGV[1])")
+		mustDo(t, c,
+			"SCRIPT", "LOAD", "return redis.call('SET', KEYS[1], ARGV[1])",
+			proto.String(sha),
+		)
+		mustContain(t, c,
+			"EVALSHA_RO", sha, "1", "foo", "baz",
+			msgWriteFromReadOnly,
+		)
+		s.CheckGet(t, "foo", "bar")
+		mustOK(t, c, "EVALSHA", sha, "1", "foo", "baz")
+		s.CheckGet(t, "foo", "baz")
+
+		getSha := sha1Hex("return redis.call('GET', KEYS[1])")
+		mustDo(t, c,
+			"EVALSHA_RO", getSha, "1", "foo",
+			proto.String("baz"),
+		)
+		mustDo(t, c,
+			"EVALSHA_RO", "nosuch", "0",
+			proto.Error(msgNoScriptFound),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"EVAL_RO", "return 1",
+			proto.Error("ERR wrong number of arguments for 'eval_ro' command"),
+		)
+		mustDo(t, c,
+			"EVALSHA_RO", "nosuch",
+			proto.Error("ERR wrong number of arguments for 'evalsha_ro' command"),
+		)
+	})
+}
+
+// Blocking commands don't block in scripts.
+func TestLuaBlocking(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("eval", func(t *testing.T) {
+		// timeout 0 would block forever
+		mustNil(t, c, "EVAL", "return redis.call('BLPOP', 'l', '0')", "0")
+		mustNil(t, c, "EVAL", "return redis.call('BZPOPMIN', 'z', '0')", "0")
+		mustNil(t, c, "EVAL", "return redis.call('BLMOVE', 'l', 'l2', 'LEFT', 'LEFT', '0')", "0")
+
+		s.Lpush("l", "aap")
+		mustDo(t, c,
+			"EVAL", "return redis.call('BLPOP', 'l', '0')", "0",
+			proto.Strings("l", "aap"),
+		)
+	})
+
+	t.Run("fcall", func(t *testing.T) {
+		mustDo(t, c,
+			"FUNCTION", "LOAD", `#!lua name=blocking
+redis.register_function('pop', function(keys, args) return redis.call('BLPOP', keys[1], '0') end)`,
+			proto.String("blocking"),
+		)
+		mustNil(t, c, "FCALL", "pop", "1", "l")
+		s.Lpush("l", "noot")
+		mustDo(t, c,
+			"FCALL", "pop", "1", "l",
+			proto.Strings("l", "noot"),
+		)
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		mustContain(t, c,
+			"EVAL", "return redis.call('WAIT', '0', '0')", "0",
+			"This Redis command is not allowed from script",
+		)
+	})
+}
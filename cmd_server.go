@@ -6,9 +6,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/server"
-	"github.com/alicebob/miniredis/v2/size"
 )
 
 func commandsServer(m *Miniredis) {
@@ -19,6 +19,13 @@ func commandsServer(m *Miniredis) {
 	m.srv.Register("INFO", m.cmdInfo)
 	m.srv.Register("TIME", m.cmdTime)
 	m.srv.Register("MEMORY", m.cmdMemory)
+	m.srv.Register("SAVE", m.cmdSave)
+	m.srv.Register("BGSAVE", m.cmdBgsave)
+	m.srv.Register("LASTSAVE", m.cmdLastsave)
+	m.srv.Register("LOLWUT", m.cmdLolwut)
+	m.srv.Register("WAIT", m.cmdWait)
+	m.srv.Register("ROLE", m.cmdRole)
+	m.srv.Register("FAILOVER", m.cmdFailover)
 }
 
 // MEMORY
@@ -52,31 +59,12 @@ func (m *Miniredis) cmdMemory(c *server.Peer, cmd string, args []string) {
 				return
 			}
 
-			var (
-				value interface{}
-				ok    bool
-			)
-			switch db.keys[args[0]] {
-			case "string":
-				value, ok = db.stringKeys[args[0]]
-			case "set":
-				value, ok = db.setKeys[args[0]]
-			case "hash":
-				value, ok = db.hashKeys[args[0]]
-			case "list":
-				value, ok = db.listKeys[args[0]]
-			case "hll":
-				value, ok = db.hllKeys[args[0]]
-			case "zset":
-				value, ok = db.sortedsetKeys[args[0]]
-			case "stream":
-				value, ok = db.streamKeys[args[0]]
-			}
+			n, ok := db.memoryUsage(args[0])
 			if !ok {
 				c.WriteNull()
 				return
 			}
-			c.WriteInt(size.Of(value))
+			c.WriteInt(n)
 		default:
 			c.WriteError(fmt.Sprintf(msgMemorySubcommand, strings.ToUpper(cmd)))
 		}
@@ -175,3 +163,295 @@ func (m *Miniredis) cmdTime(c *server.Peer, cmd string, args []string) {
 		c.WriteBulk(strconv.FormatInt(microseconds, 10))
 	})
 }
+
+// LOLWUT
+func (m *Miniredis) cmdLolwut(c *server.Peer, cmd string, args []string) {
+	// "VERSION v" is validated but ignored, same as all other arguments.
+	if len(args) >= 2 && strings.ToUpper(args[0]) == "VERSION" {
+		var v int
+		if ok := optInt(c, args[1], &v); !ok {
+			return
+		}
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		c.WriteVerbatim("txt", "Redis ver. "+redisVersion+"\n")
+	})
+}
+
+// SAVE
+func (m *Miniredis) cmdSave(c *server.Peer, cmd string, args []string) {
+	if len(args) > 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		m.save()
+		c.WriteOK()
+	})
+}
+
+// BGSAVE
+func (m *Miniredis) cmdBgsave(c *server.Peer, cmd string, args []string) {
+	if len(args) > 0 && strings.ToUpper(args[0]) == "SCHEDULE" {
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		// nothing happens in the background, it's done right away
+		m.save()
+		c.WriteInline("Background saving started")
+	})
+}
+
+// LASTSAVE
+func (m *Miniredis) cmdLastsave(c *server.Peer, cmd string, args []string) {
+	if len(args) > 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		c.WriteInt(int(m.lastSave.Unix()))
+	})
+}
+
+// save doesn't write anything anywhere, but it does reset the INFO
+// persistence counters.
+func (m *Miniredis) save() {
+	m.dirty = 0
+	m.lastSave = m.effectiveNow()
+}
+
+// WAIT
+func (m *Miniredis) cmdWait(c *server.Peer, cmd string, args []string) {
+	if len(args) != 2 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+	ctx := getCtx(c)
+	if ctx.nested {
+		c.WriteError(msgNotFromScripts(ctx.nestedSHA))
+		return
+	}
+
+	numReplicas, err := strconv.Atoi(args[0])
+	if err != nil {
+		setDirty(c)
+		c.WriteError(msgInvalidInt)
+		return
+	}
+	ms, err := strconv.Atoi(args[1])
+	if err != nil {
+		setDirty(c)
+		c.WriteError(msgTimeoutNotInt)
+		return
+	}
+	if ms < 0 {
+		setDirty(c)
+		c.WriteError(msgTimeoutNegative)
+		return
+	}
+	timeout := time.Duration(ms) * time.Millisecond
+
+	m.Lock()
+	replicas, lag := m.replicas, m.replLag
+	m.Unlock()
+
+	// acked gives the replicas which acknowledged after waiting d.
+	acked := func(d time.Duration) int {
+		if d < lag {
+			return 0
+		}
+		return replicas
+	}
+
+	// Without a replication lag there's nothing to wait for, so we report the
+	// replicas right away. With a lag, and enough replicas, we wait for the
+	// lag, otherwise for the timeout, where 0 is forever.
+	var wait time.Duration
+	switch {
+	case lag == 0, numReplicas <= 0:
+	case numReplicas <= replicas:
+		wait = lag
+		if timeout != 0 && timeout < lag {
+			wait = timeout
+		}
+	default:
+		wait = timeout
+		if wait == 0 && !inTx(ctx) {
+			blocking(m, c, cmd, 0, func(*server.Peer, *connCtx) bool { return false }, nil)
+			return
+		}
+	}
+
+	if wait == 0 || inTx(ctx) {
+		withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+			c.WriteInt(acked(0))
+		})
+		return
+	}
+	blocking(
+		m,
+		c,
+		cmd,
+		wait,
+		func(*server.Peer, *connCtx) bool { return false },
+		func(c *server.Peer) {
+			c.WriteInt(acked(wait))
+		},
+	)
+}
+
+// ROLE
+func (m *Miniredis) cmdRole(c *server.Peer, cmd string, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		if m.currentRole() == roleSlave {
+			c.WriteLen(5)
+			c.WriteBulk(roleSlave)
+			c.WriteBulk(m.masterHost)
+			c.WriteInt(m.masterPort)
+			c.WriteBulk("connected")
+			c.WriteInt(0)
+			return
+		}
+		c.WriteLen(3)
+		c.WriteBulk(roleMaster)
+		c.WriteInt(0)
+		c.WriteLen(0)
+	})
+}
+
+// FAILOVER
+func (m *Miniredis) cmdFailover(c *server.Peer, cmd string, args []string) {
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		to      bool
+		force   bool
+		abort   bool
+		timeout int
+	}
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "TO":
+			if len(args) < 3 || opts.to {
+				syntaxError(c)
+				return
+			}
+			var port int
+			if ok := optInt(c, args[2], &port); !ok {
+				return
+			}
+			opts.to = true
+			args = args[3:]
+			if len(args) > 0 && strings.ToUpper(args[0]) == "FORCE" {
+				opts.force = true
+				args = args[1:]
+			}
+		case "ABORT":
+			if opts.abort {
+				syntaxError(c)
+				return
+			}
+			opts.abort = true
+			args = args[1:]
+		case "TIMEOUT":
+			if len(args) < 2 || opts.timeout != 0 {
+				syntaxError(c)
+				return
+			}
+			if ok := optInt(c, args[1], &opts.timeout); !ok {
+				return
+			}
+			if opts.timeout <= 0 {
+				setDirty(c)
+				c.WriteError(msgFailoverTimeout)
+				return
+			}
+			args = args[2:]
+		default:
+			syntaxError(c)
+			return
+		}
+	}
+	if opts.abort && (opts.to || opts.timeout != 0) {
+		setDirty(c)
+		c.WriteError(msgFailoverAbortArgs)
+		return
+	}
+	if opts.force && opts.timeout == 0 {
+		setDirty(c)
+		c.WriteError(msgFailoverForce)
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		if m.currentRole() == roleSlave {
+			c.WriteError(msgFailoverReplica)
+			return
+		}
+		if opts.abort {
+			// a FAILOVER is done as soon as it's started
+			c.WriteError(msgNoFailover)
+			return
+		}
+		c.WriteOK()
+	})
+}
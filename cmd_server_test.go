@@ -118,6 +118,35 @@ func TestCmdServerTime(t *testing.T) {
 	)
 }
 
+func TestCmdServerLolwut(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c,
+		"LOLWUT",
+		proto.String("Redis ver. 6.0.5\n"),
+	)
+	mustDo(t, c,
+		"LOLWUT", "VERSION", "5", "1", "2",
+		proto.String("Redis ver. 6.0.5\n"),
+	)
+	mustDo(t, c,
+		"LOLWUT", "VERSION", "five",
+		proto.Error(msgInvalidInt),
+	)
+
+	t.Run("RESP3", func(t *testing.T) {
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c.Close()
+		useRESP3(t, c)
+
+		mustDo(t, c,
+			"LOLWUT",
+			proto.Verbatim("txt", "Redis ver. 6.0.5\n"),
+		)
+	})
+}
+
 // Test Memory Usage
 func TestCmdServerMemoryUsage(t *testing.T) {
 	_, c := runWithClient(t)
@@ -149,3 +178,369 @@ func TestCmdServerMemoryUsageLowerCase(t *testing.T) {
 		proto.Int(19),
 	)
 }
+
+func TestCmdServerWait(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("no replicas", func(t *testing.T) {
+		must0(t, c, "WAIT", "0", "0")
+
+		// no lag: returns right away, whatever the timeout
+		start := time.Now()
+		must0(t, c, "WAIT", "1", "500")
+		must0(t, c, "WAIT", "1", "0")
+		assert(t, time.Since(start) < 500*time.Millisecond, "didn't wait for the timeout")
+	})
+
+	t.Run("replicas", func(t *testing.T) {
+		s.SetReplicas(3)
+		defer s.SetReplicas(0)
+
+		mustDo(t, c, "WAIT", "1", "0", proto.Int(3))
+		mustDo(t, c, "WAIT", "5", "0", proto.Int(3))
+	})
+
+	t.Run("lag", func(t *testing.T) {
+		s.SetReplicas(1)
+		s.SetReplicationLag(50 * time.Millisecond)
+		defer s.SetReplicationLag(0)
+
+		start := time.Now()
+		must1(t, c, "WAIT", "1", "200")
+		took := time.Since(start)
+		assert(t, took >= 50*time.Millisecond, "waited for the lag")
+		assert(t, took < 200*time.Millisecond, "didn't wait for the timeout")
+
+		// timeout before the replica acknowledged
+		start = time.Now()
+		must0(t, c, "WAIT", "1", "10")
+		took = time.Since(start)
+		assert(t, took >= 10*time.Millisecond && took < 50*time.Millisecond, "waited for the timeout")
+
+		// not enough replicas: wait for the timeout
+		start = time.Now()
+		must1(t, c, "WAIT", "2", "100")
+		assert(t, time.Since(start) >= 100*time.Millisecond, "waited for the timeout")
+	})
+
+	t.Run("no lag", func(t *testing.T) {
+		s.SetReplicas(2)
+		mustDo(t, c, "WAIT", "1", "0", proto.Int(2))
+		mustDo(t, c, "WAIT", "0", "0", proto.Int(2))
+	})
+
+	t.Run("MULTI", func(t *testing.T) {
+		s.SetReplicas(1)
+		s.SetReplicationLag(time.Hour)
+		defer s.SetReplicationLag(0)
+		mustOK(t, c, "MULTI")
+		mustDo(t, c, "WAIT", "1", "0", proto.Inline("QUEUED"))
+		mustDo(t, c, "EXEC", proto.Ints(0))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"WAIT", "1",
+			proto.Error(errWrongNumber("wait")),
+		)
+		mustDo(t, c,
+			"WAIT", "foo", "0",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"WAIT", "1", "foo",
+			proto.Error(msgTimeoutNotInt),
+		)
+		mustDo(t, c,
+			"WAIT", "1", "-1",
+			proto.Error(msgTimeoutNegative),
+		)
+		mustContain(t, c,
+			"EVAL", "return redis.call('WAIT', '0', '0')", "0",
+			"not allowed from script",
+		)
+	})
+}
+
+func TestCmdServerCommandInfo(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("count", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "COUNT",
+			proto.Int(len(s.srv.Commands())),
+		)
+		assert(t, len(s.srv.Commands()) > 200, "commands: %d", len(s.srv.Commands()))
+	})
+
+	t.Run("info", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "INFO", "get", "MSET", "nosuch", "getex",
+			proto.Array(
+				proto.Array(
+					proto.String("get"),
+					proto.Int(2),
+					proto.Array(proto.Inline("readonly"), proto.Inline("fast")),
+					proto.Int(1),
+					proto.Int(1),
+					proto.Int(1),
+				),
+				proto.Array(
+					proto.String("mset"),
+					proto.Int(-3),
+					proto.Array(proto.Inline("write"), proto.Inline("denyoom")),
+					proto.Int(1),
+					proto.Int(-1),
+					proto.Int(2),
+				),
+				proto.Nil,
+				// not in our table
+				proto.Array(
+					proto.String("getex"),
+					proto.Int(-1),
+					proto.Array(),
+					proto.Int(0),
+					proto.Int(0),
+					proto.Int(0),
+				),
+			),
+		)
+		// known to Redis, not to us
+		mustDo(t, c,
+			"COMMAND", "INFO", "monitor",
+			proto.Array(proto.Nil),
+		)
+
+		res, err := c.Do("COMMAND", "INFO")
+		ok(t, err)
+		all, err := proto.ReadArray(res)
+		ok(t, err)
+		equals(t, len(s.srv.Commands()), len(all))
+	})
+
+	t.Run("docs", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "DOCS", "get", "nosuch", "SET",
+			proto.Array(
+				proto.String("get"), proto.Array(),
+				proto.String("set"), proto.Array(),
+			),
+		)
+
+		res, err := c.Do("COMMAND", "DOCS")
+		ok(t, err)
+		all, err := proto.ReadArray(res)
+		ok(t, err)
+		equals(t, 2*len(s.srv.Commands()), len(all))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "COUNT", "foo",
+			proto.Error("ERR wrong number of arguments for 'command|count' command"),
+		)
+	})
+}
+
+func TestCmdServerCommandGetkeys(t *testing.T) {
+	_, c := runWithClient(t)
+
+	t.Run("getkeys", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "MSET", "a", "1", "b", "2",
+			proto.Strings("a", "b"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "ZUNIONSTORE", "dst", "2", "z1", "z2", "WEIGHTS", "1", "2",
+			proto.Strings("dst", "z1", "z2"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "EVAL", "return 1", "2", "k1", "k2", "arg",
+			proto.Strings("k1", "k2"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "FCALL_RO", "fn", "1", "k1", "arg",
+			proto.Strings("k1"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "GEORADIUS", "src", "1", "2", "3", "km", "STORE", "dst",
+			proto.Strings("src", "dst"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "GEORADIUSBYMEMBER", "src", "STORE", "3", "km",
+			proto.Strings("src"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "BITOP", "AND", "dst", "a", "b",
+			proto.Strings("dst", "a", "b"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "XREADGROUP", "GROUP", "g", "c", "STREAMS", "s1", "s2", ">", ">",
+			proto.Strings("s1", "s2"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "BLMPOP", "0", "2", "l1", "l2", "LEFT",
+			proto.Strings("l1", "l2"),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "EVAL", "return 1", "0",
+			proto.Array(),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "FCALL_RO", "f", "0",
+			proto.Array(),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "EVALSHA", "abc", "0",
+			proto.Array(),
+		)
+	})
+
+	t.Run("getkeysandflags", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "SET", "foo", "bar",
+			proto.Array(
+				proto.Array(proto.String("foo"), proto.Strings("OW", "update")),
+			),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "SET", "foo", "bar", "GET",
+			proto.Array(
+				proto.Array(proto.String("foo"), proto.Strings("RW", "access", "update")),
+			),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "SET", "foo", "GET",
+			proto.Array(
+				proto.Array(proto.String("foo"), proto.Strings("OW", "update")),
+			),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "GET", "foo",
+			proto.Array(
+				proto.Array(proto.String("foo"), proto.Strings("RO", "access")),
+			),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "EVAL_RO", "return 1", "1", "foo",
+			proto.Array(
+				proto.Array(proto.String("foo"), proto.Strings("RO", "access")),
+			),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS", "LMOVE", "src", "dst", "LEFT", "RIGHT",
+			proto.Array(
+				proto.Array(proto.String("src"), proto.Strings("RW", "access", "delete")),
+				proto.Array(proto.String("dst"), proto.Strings("RW", "insert")),
+			),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"COMMAND", "GETKEYS",
+			proto.Error(errWrongNumber("command|getkeys")),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYSANDFLAGS",
+			proto.Error(errWrongNumber("command|getkeysandflags")),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "NOSUCH", "foo",
+			proto.Error(msgInvalidCommand),
+		)
+		mustDo(t, c,
+			"COMMAND", "GETKEYS", "PING",
+			proto.Error(msgNoKeyArgs),
+		)
+		mustDo(t, c,
+			"COMMAND", "NOSUCH",
+			proto.Error("ERR unknown subcommand 'nosuch'. Try COMMAND HELP."),
+		)
+	})
+}
+
+func TestCmdServerRole(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustDo(t, c,
+		"ROLE",
+		proto.Array(proto.String("master"), proto.Int(0), proto.Array()),
+	)
+	mustContain(t, c, "INFO", "replication", "role:master\r\n")
+	mustOK(t, c, "FAILOVER")
+	mustOK(t, c, "FAILOVER", "TO", "localhost", "6380", "TIMEOUT", "100")
+	mustOK(t, c, "FAILOVER", "TO", "localhost", "6380", "FORCE", "TIMEOUT", "100")
+
+	ok(t, s.SetRole("slave"))
+	s.SetMaster("localhost", 6380)
+	mustDo(t, c,
+		"ROLE",
+		proto.Array(
+			proto.String("slave"),
+			proto.String("localhost"),
+			proto.Int(6380),
+			proto.String("connected"),
+			proto.Int(0),
+		),
+	)
+	mustDo(t, c,
+		"INFO", "replication",
+		proto.String("# Replication\n"+
+			"role:slave\r\n"+
+			"master_host:localhost\r\n"+
+			"master_port:6380\r\n"+
+			"master_link_status:up\r\n"),
+	)
+	mustContain(t, c, "HELLO", "2", "slave")
+	mustDo(t, c,
+		"FAILOVER",
+		proto.Error("ERR FAILOVER is not valid when server is a replica."),
+	)
+
+	ok(t, s.SetRole("master"))
+	s.SetReplicas(2)
+	mustContain(t, c, "INFO", "replication", "connected_slaves:2\r\n")
+
+	ok(t, s.SetRole("replica"))
+	mustContain(t, c, "INFO", "replication", "role:slave\r\n")
+	assert(t, s.SetRole("primary") != nil, "invalid role")
+	assert(t, s.SetRole("SLAVE") != nil, "invalid role")
+	mustContain(t, c, "INFO", "replication", "role:slave\r\n")
+	ok(t, s.SetRole("master"))
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"ROLE", "foo",
+			proto.Error(errWrongNumber("role")),
+		)
+		mustDo(t, c,
+			"FAILOVER", "FOO",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"FAILOVER", "TO", "localhost",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"FAILOVER", "TO", "localhost", "foo",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"FAILOVER", "TIMEOUT", "0",
+			proto.Error("ERR FAILOVER timeout must be greater than 0"),
+		)
+		mustDo(t, c,
+			"FAILOVER", "TO", "localhost", "6380", "FORCE",
+			proto.Error("ERR FAILOVER with force option requires both a timeout and target HOST and IP."),
+		)
+		mustDo(t, c,
+			"FAILOVER", "ABORT", "TIMEOUT", "10",
+			proto.Error("ERR FAILOVER abort cannot be used with other arguments"),
+		)
+		mustDo(t, c,
+			"FAILOVER", "ABORT",
+			proto.Error("ERR No failover in progress."),
+		)
+	})
+}
@@ -3,7 +3,7 @@
 package miniredis
 
 import (
-	"fmt"
+	"errors"
 	"strconv"
 	"strings"
 
@@ -120,7 +120,7 @@ func (m *Miniredis) cmdSdiff(c *server.Peer, cmd string, args []string) {
 		}
 
 		c.WriteSetLen(len(set))
-		for k := range set {
+		for _, k := range set.members() {
 			c.WriteBulk(k)
 		}
 	})
@@ -151,7 +151,7 @@ func (m *Miniredis) cmdSdiffstore(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		db.del(dest, true)
+		db.unset(dest, true)
 		db.setSet(dest, set)
 		c.WriteInt(len(set))
 	})
@@ -182,8 +182,8 @@ func (m *Miniredis) cmdSinter(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		c.WriteLen(len(set))
-		for k := range set {
+		c.WriteSetLen(len(set))
+		for _, k := range set.members() {
 			c.WriteBulk(k)
 		}
 	})
@@ -214,7 +214,7 @@ func (m *Miniredis) cmdSinterstore(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		db.del(dest, true)
+		db.unset(dest, true)
 		db.setSet(dest, set)
 		c.WriteInt(len(set))
 	})
@@ -234,61 +234,53 @@ func (m *Miniredis) cmdSintercard(c *server.Peer, cmd string, args []string) {
 		return
 	}
 
-	opts := struct {
-		keys  []string
-		limit int
-	}{}
-
-	numKeys, err := strconv.Atoi(args[0])
+	keys, limit, err := intercardParse(args)
 	if err != nil {
 		setDirty(c)
-		c.WriteError("ERR numkeys should be greater than 0")
+		c.WriteError(err.Error())
 		return
 	}
-	if numKeys < 1 {
-		setDirty(c)
-		c.WriteError("ERR numkeys should be greater than 0")
-		return
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		count, err := db.setIntercard(keys, limit)
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+		c.WriteInt(count)
+	})
+}
+
+// intercardParse parses the arguments of SINTERCARD and ZINTERCARD:
+// numkeys key [key ...] [LIMIT limit]
+func intercardParse(args []string) ([]string, int, error) {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil || numKeys < 1 {
+		return nil, 0, errors.New("ERR numkeys should be greater than 0")
 	}
 
 	args = args[1:]
 	if len(args) < numKeys {
-		setDirty(c)
-		c.WriteError("ERR Number of keys can't be greater than number of args")
-		return
+		return nil, 0, errors.New("ERR Number of keys can't be greater than number of args")
 	}
-	opts.keys = args[:numKeys]
+	keys, args := args[:numKeys], args[numKeys:]
 
-	args = args[numKeys:]
+	limit := 0
 	if len(args) == 2 && strings.ToLower(args[0]) == "limit" {
 		l, err := strconv.Atoi(args[1])
 		if err != nil {
-			setDirty(c)
-			c.WriteError(msgInvalidInt)
-			return
+			return nil, 0, errors.New(msgInvalidInt)
 		}
 		if l < 0 {
-			setDirty(c)
-			c.WriteError(msgLimitIsNegative)
-			return
+			return nil, 0, errors.New(msgLimitIsNegative)
 		}
-		opts.limit = l
+		limit = l
 	} else if len(args) > 0 {
-		setDirty(c)
-		c.WriteError(msgSyntaxError)
-		return
+		return nil, 0, errors.New(msgSyntaxError)
 	}
-
-	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
-		db := m.db(ctx.selectedDB)
-
-		count, err := db.setIntercard(opts.keys, opts.limit)
-		if err != nil {
-			c.WriteError(err.Error())
-			return
-		}
-		c.WriteInt(count)
-	})
+	return keys, limit, nil
 }
 
 // SISMEMBER
@@ -681,8 +673,8 @@ func (m *Miniredis) cmdSunion(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		c.WriteLen(len(set))
-		for k := range set {
+		c.WriteSetLen(len(set))
+		for _, k := range set.members() {
 			c.WriteBulk(k)
 		}
 	})
@@ -713,7 +705,7 @@ func (m *Miniredis) cmdSunionstore(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		db.del(dest, true)
+		db.unset(dest, true)
 		db.setSet(dest, set)
 		c.WriteInt(len(set))
 	})
@@ -736,16 +728,20 @@ func (m *Miniredis) cmdSscan(c *server.Peer, cmd string, args []string) {
 	var opts struct {
 		key       string
 		value     int
-		cursor    int
+		cursor    uint64
 		count     int
 		withMatch bool
 		match     string
 	}
 
 	opts.key = args[0]
-	if ok := optIntErr(c, args[1], &opts.cursor, msgInvalidCursor); !ok {
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		setDirty(c)
+		c.WriteError(msgInvalidCursor)
 		return
 	}
+	opts.cursor = cursor
 	args = args[2:]
 
 	// MATCH and COUNT options
@@ -789,40 +785,22 @@ func (m *Miniredis) cmdSscan(c *server.Peer, cmd string, args []string) {
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
-		// return _all_ (matched) keys every time
 		if db.exists(opts.key) && db.t(opts.key) != "set" {
 			c.WriteError(ErrWrongType.Error())
 			return
 		}
-		members := db.setMembers(opts.key)
+
+		cursor, members := scanMembers(db.setMembers(opts.key), opts.cursor, opts.count)
 		if opts.withMatch {
 			members, _ = matchKeys(members, opts.match)
 		}
-		low := opts.cursor
-		high := low + opts.count
-		// validate high is correct
-		if high > len(members) || high == 0 {
-			high = len(members)
-		}
-		if opts.cursor > high {
-			// invalid cursor
-			c.WriteLen(2)
-			c.WriteBulk("0") // no next cursor
-			c.WriteLen(0)    // no elements
-			return
-		}
-		cursorValue := low + opts.count
-		if cursorValue > len(members) {
-			cursorValue = 0 // no next cursor
-		}
-		members = members[low:high]
+
 		c.WriteLen(2)
-		c.WriteBulk(fmt.Sprintf("%d", cursorValue))
+		c.WriteBulk(strconv.FormatUint(cursor, 10))
 		c.WriteLen(len(members))
 		for _, k := range members {
 			c.WriteBulk(k)
 		}
-
 	})
 }
 
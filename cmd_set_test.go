@@ -1,7 +1,9 @@
 package miniredis
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2/proto"
@@ -146,6 +148,8 @@ func TestSmismember(t *testing.T) {
 
 	mustDo(t, c, "SMISMEMBER", "s", "aap", "nosuch", "mies", proto.Ints(1, 0, 1))
 	mustDo(t, c, "SMISMEMBER", "q", "aap", "nosuch", "mies", proto.Ints(0, 0, 0))
+	mustDo(t, c, "SMISMEMBER", "s", "nosuch", "noot", "noot", "aap", proto.Ints(0, 1, 1, 1))
+	mustDo(t, c, "SMISMEMBER", "s", "", proto.Ints(0))
 
 	t.Run("errors", func(t *testing.T) {
 		mustOK(t, c, "SET", "str", "value")
@@ -571,6 +575,22 @@ func TestSinter(t *testing.T) {
 			proto.Error(msgWrongType),
 		)
 	})
+
+	t.Run("RESP3", func(t *testing.T) {
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c.Close()
+		useRESP3(t, c)
+
+		mustDo(t, c,
+			"SINTER", "s1", "s2", "s3",
+			proto.StringSet("mies"),
+		)
+		mustDo(t, c,
+			"SINTER", "s1", "nosuch",
+			proto.Set(),
+		)
+	})
 }
 
 // Test SINTERSTORE
@@ -708,6 +728,39 @@ func TestSintercard(t *testing.T) {
 		)
 	})
 
+	t.Run("limit on many keys", func(t *testing.T) {
+		var members []string
+		for i := 0; i < 100; i++ {
+			members = append(members, strconv.Itoa(i))
+		}
+		s.SetAdd("big1", members...)
+		s.SetAdd("big2", members[10:]...)
+		s.SetAdd("big3", members[:50]...)
+		mustDo(t, c,
+			"SINTERCARD", "3", "big1", "big2", "big3",
+			proto.Int(40),
+		)
+		mustDo(t, c,
+			"SINTERCARD", "3", "big1", "big2", "big3", "limit", "7",
+			proto.Int(7),
+		)
+		mustDo(t, c,
+			"SINTERCARD", "2", "big3", "big3", "LIMIT", "0",
+			proto.Int(50),
+		)
+		mustDo(t, c,
+			"SINTERCARD", "1", "big1", "LIMIT", "1000",
+			proto.Int(100),
+		)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		mustDo(t, c,
+			"SINTERCARD", "1", "key1", "LIMIT", "-1",
+			proto.Error(msgLimitIsNegative),
+		)
+	})
+
 	t.Run("bad limit", func(t *testing.T) {
 		mustDo(
 			t, c,
@@ -783,6 +836,22 @@ func TestSunion(t *testing.T) {
 			proto.Error(msgWrongType),
 		)
 	})
+
+	t.Run("RESP3", func(t *testing.T) {
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		defer c.Close()
+		useRESP3(t, c)
+
+		mustDo(t, c,
+			"SUNION", "s3",
+			proto.StringSet("aap", "mies", "wim"),
+		)
+		mustDo(t, c,
+			"SUNION", "nosuch",
+			proto.Set(),
+		)
+	})
 }
 
 // Test SUNIONSTORE
@@ -839,16 +908,7 @@ func TestSscan(t *testing.T) {
 		),
 	)
 
-	// Invalid cursor
-	mustDo(t, c,
-		"SSCAN", "set", "42",
-		proto.Array(
-			proto.String("0"),
-			proto.Strings(),
-		),
-	)
-
-	// COUNT (ignored)
+	// COUNT bigger than the set
 	mustDo(t, c,
 		"SSCAN", "set", "0", "COUNT", "200",
 		proto.Array(
@@ -912,39 +972,51 @@ func TestSscan(t *testing.T) {
 		)
 	})
 
-	s.SetAdd("largeset", "v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8")
-	mustDo(t, c,
-		"SSCAN", "largeset", "0", "COUNT", "3",
-		proto.Array(
-			proto.String("3"),
-			proto.Array(
-				proto.String("v1"),
-				proto.String("v2"),
-				proto.String("v3"),
-			),
-		),
-	)
-	mustDo(t, c,
-		"SSCAN", "largeset", "3", "COUNT", "3",
-		proto.Array(
-			proto.String("6"),
-			proto.Array(
-				proto.String("v4"),
-				proto.String("v5"),
-				proto.String("v6"),
-			),
-		),
-	)
-	mustDo(t, c,
-		"SSCAN", "largeset", "6", "COUNT", "3",
-		proto.Array(
-			proto.String("0"),
-			proto.Array(
-				proto.String("v7"),
-				proto.String("v8"),
-			),
-		),
-	)
+	t.Run("cursor", func(t *testing.T) {
+		var vs []string
+		for i := 0; i < 100; i++ {
+			vs = append(vs, fmt.Sprintf("v%d", i))
+		}
+		s.SetAdd("largeset", vs...)
+
+		res, err := c.Do("SSCAN", "largeset", "0", "COUNT", "10")
+		ok(t, err)
+		parts, err := proto.ReadArray(res)
+		ok(t, err)
+		cursor, err := proto.ReadString(parts[0])
+		ok(t, err)
+		assert(t, cursor != "0", "got a next cursor")
+		page, err := proto.ReadStrings(parts[1])
+		ok(t, err)
+		assert(t, len(page) >= 10 && len(page) < 100, "got a single page")
+
+		seen := scanAll(t, c, []string{"SSCAN", "largeset"}, "COUNT", "10")
+		equals(t, 100, len(seen))
+
+		// the set changes during the scan
+		seen = map[string]int{}
+		cursor = "0"
+		for i := 0; ; i++ {
+			res, err := c.Do("SSCAN", "largeset", cursor, "COUNT", "5")
+			ok(t, err)
+			parts, err := proto.ReadArray(res)
+			ok(t, err)
+			cursor, err = proto.ReadString(parts[0])
+			ok(t, err)
+			page, err := proto.ReadStrings(parts[1])
+			ok(t, err)
+			for _, v := range page {
+				seen[v]++
+			}
+			if cursor == "0" {
+				break
+			}
+			s.SetAdd("largeset", fmt.Sprintf("new%d", i))
+		}
+		for _, v := range vs {
+			assert(t, seen[v] > 0, "member %q not returned", v)
+		}
+	})
 }
 
 func TestDelElem(t *testing.T) {
@@ -0,0 +1,169 @@
+// Commands from https://redis.io/commands#server
+
+package miniredis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+const (
+	slowlogMaxArgc   = 32  // max arguments stored in a slowlog entry
+	slowlogMaxString = 128 // max length of a single argument in a slowlog entry
+)
+
+// slowlogEntry is a single SLOWLOG GET entry.
+type slowlogEntry struct {
+	id       int
+	time     time.Time
+	duration time.Duration
+	args     []string // the command and its arguments
+	addr     string
+	name     string // CLIENT SETNAME
+}
+
+// commandsSlowlog handles SLOWLOG operations.
+func commandsSlowlog(m *Miniredis) {
+	m.srv.Register("SLOWLOG", m.cmdSlowlog)
+}
+
+// SLOWLOG
+func (m *Miniredis) cmdSlowlog(c *server.Peer, cmd string, args []string) {
+	if len(args) == 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	switch sub := strings.ToUpper(args[0]); sub {
+	case "GET":
+		m.cmdSlowlogGet(c, args[1:])
+	case "LEN":
+		m.cmdSlowlogLen(c, args[1:])
+	case "RESET":
+		m.cmdSlowlogReset(c, args[1:])
+	default:
+		setDirty(c)
+		c.WriteError(fmt.Sprintf("ERR unknown subcommand '%s'. Try SLOWLOG HELP.", args[0]))
+	}
+}
+
+// SLOWLOG GET
+func (m *Miniredis) cmdSlowlogGet(c *server.Peer, args []string) {
+	if len(args) > 1 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("slowlog|get"))
+		return
+	}
+	count := 10
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			setDirty(c)
+			c.WriteError(msgInvalidInt)
+			return
+		}
+		if n < -1 {
+			setDirty(c)
+			c.WriteError("ERR count should be greater than or equal to -1")
+			return
+		}
+		count = n
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		entries := m.slowlog
+		if count >= 0 && count < len(entries) {
+			entries = entries[:count]
+		}
+		c.WriteLen(len(entries))
+		for _, e := range entries {
+			c.WriteLen(6)
+			c.WriteInt(e.id)
+			c.WriteInt(int(e.time.Unix()))
+			c.WriteInt(int(e.duration.Microseconds()))
+			c.WriteStrings(e.args)
+			c.WriteBulk(e.addr)
+			c.WriteBulk(e.name)
+		}
+	})
+}
+
+// SLOWLOG LEN
+func (m *Miniredis) cmdSlowlogLen(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("slowlog|len"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		c.WriteInt(len(m.slowlog))
+	})
+}
+
+// SLOWLOG RESET
+func (m *Miniredis) cmdSlowlogReset(c *server.Peer, args []string) {
+	if len(args) != 0 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("slowlog|reset"))
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		m.slowlog = nil
+		c.WriteOK()
+	})
+}
+
+// logSlow adds a command to the slowlog, if it took long enough. Needs the
+// lock.
+func (m *Miniredis) logSlow(c *server.Peer, cmd string, args []string, d time.Duration) {
+	switch cmd {
+	case "AUTH", "HELLO":
+		// these have passwords
+		return
+	}
+	threshold := m.configInt("slowlog-log-slower-than")
+	if threshold < 0 || d.Microseconds() < int64(threshold) {
+		return
+	}
+
+	all := append([]string{cmd}, args...)
+	var stored []string
+	for i, a := range all {
+		if i == slowlogMaxArgc-1 && len(all) > slowlogMaxArgc {
+			stored = append(stored, fmt.Sprintf("... (%d more arguments)", len(all)-i))
+			break
+		}
+		if len(a) > slowlogMaxString {
+			a = fmt.Sprintf("%s... (%d more bytes)", a[:slowlogMaxString], len(a)-slowlogMaxString)
+		}
+		stored = append(stored, a)
+	}
+
+	e := slowlogEntry{
+		id:       m.slowlogID,
+		time:     m.effectiveNow(),
+		duration: d,
+		args:     stored,
+		addr:     c.Addr,
+		name:     c.ClientName,
+	}
+	m.slowlogID++
+	// newest first
+	m.slowlog = append([]slowlogEntry{e}, m.slowlog...)
+	if max := m.configInt("slowlog-max-len"); max >= 0 && len(m.slowlog) > max {
+		m.slowlog = m.slowlog[:max]
+	}
+}
@@ -0,0 +1,109 @@
+package miniredis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+func TestSlowlog(t *testing.T) {
+	s, c := runWithClient(t)
+
+	mustOK(t, c, "CONFIG", "SET", "slowlog-log-slower-than", "5000")
+	s.SetCommandLatency("GET", 10*time.Millisecond)
+	s.SetTime(time.Unix(1700000000, 0))
+
+	mustNil(t, c, "GET", "foo")
+	mustOK(t, c, "SET", "foo", "bar")
+	mustDo(t, c, "SLOWLOG", "LEN", proto.Int(1))
+
+	res, err := c.Do("SLOWLOG", "GET")
+	ok(t, err)
+	entries, err := proto.Parse(res)
+	ok(t, err)
+	equals(t, 1, len(entries.([]interface{})))
+	e := entries.([]interface{})[0].([]interface{})
+	equals(t, 6, len(e))
+	equals(t, 0, e[0])
+	equals(t, 1700000000, e[1])
+	assert(t, e[2].(int) >= 10000, "duration")
+	equals(t, []interface{}{"GET", "foo"}, e[3])
+	assert(t, strings.HasPrefix(e[4].(string), "127.0.0.1:"), "client addr")
+	equals(t, "", e[5])
+
+	t.Run("name", func(t *testing.T) {
+		mustOK(t, c, "CLIENT", "SETNAME", "wim")
+		mustNil(t, c, "GET", "bar")
+		mustDo(t, c, "SLOWLOG", "LEN", proto.Int(2))
+		mustContain(t, c, "SLOWLOG", "GET", "1", "wim")
+	})
+
+	t.Run("count", func(t *testing.T) {
+		mustDo(t, c, "SLOWLOG", "GET", "0", proto.Array())
+		mustContain(t, c, "SLOWLOG", "GET", "-1", "foo")
+	})
+
+	t.Run("long", func(t *testing.T) {
+		args := []string{"GET", strings.Repeat("x", 200)}
+		for i := 0; i < 40; i++ {
+			args = append(args, "arg")
+		}
+		_, err := c.Do(args...)
+		ok(t, err)
+		mustContain(t, c, "SLOWLOG", "GET", "1", "... (72 more bytes)")
+		mustContain(t, c, "SLOWLOG", "GET", "1", "... (11 more arguments)")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mustOK(t, c, "CONFIG", "SET", "slowlog-log-slower-than", "-1")
+		mustOK(t, c, "SLOWLOG", "RESET")
+		mustNil(t, c, "GET", "foo2")
+		mustDo(t, c, "SLOWLOG", "LEN", proto.Int(0))
+	})
+
+	t.Run("max len", func(t *testing.T) {
+		mustOK(t, c, "CONFIG", "SET", "slowlog-log-slower-than", "0", "slowlog-max-len", "2")
+		s.SetCommandLatency("GET", 0)
+		for i := 0; i < 4; i++ {
+			mustNil(t, c, "GET", "foo2")
+		}
+		mustDo(t, c, "SLOWLOG", "LEN", proto.Int(2))
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		// time spent waiting doesn't count
+		mustOK(t, c, "CONFIG", "SET", "slowlog-log-slower-than", "50000")
+		mustOK(t, c, "SLOWLOG", "RESET")
+		mustDo(t, c, "BLPOP", "nosuch", "0.1", proto.NilList)
+		mustDo(t, c, "SLOWLOG", "GET", proto.Array())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"SLOWLOG",
+			proto.Error(errWrongNumber("slowlog")),
+		)
+		mustDo(t, c,
+			"SLOWLOG", "FOO",
+			proto.Error("ERR unknown subcommand 'FOO'. Try SLOWLOG HELP."),
+		)
+		mustDo(t, c,
+			"SLOWLOG", "GET", "foo",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"SLOWLOG", "GET", "-2",
+			proto.Error("ERR count should be greater than or equal to -1"),
+		)
+		mustDo(t, c,
+			"SLOWLOG", "LEN", "foo",
+			proto.Error(errWrongNumber("slowlog|len")),
+		)
+		mustDo(t, c,
+			"SLOWLOG", "RESET", "foo",
+			proto.Error(errWrongNumber("slowlog|reset")),
+		)
+	})
+}
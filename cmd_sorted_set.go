@@ -4,25 +4,29 @@ package miniredis
 
 import (
 	"errors"
-	"fmt"
 	"math"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/server"
 )
 
 // commandsSortedSet handles all sorted set operations.
 func commandsSortedSet(m *Miniredis) {
+	m.srv.Register("BZPOPMAX", m.cmdBzpopmax(true))
+	m.srv.Register("BZPOPMIN", m.cmdBzpopmax(false))
 	m.srv.Register("ZADD", m.cmdZadd)
 	m.srv.Register("ZCARD", m.cmdZcard)
 	m.srv.Register("ZCOUNT", m.cmdZcount)
 	m.srv.Register("ZINCRBY", m.cmdZincrby)
 	m.srv.Register("ZINTER", m.makeCmdZinter(false))
 	m.srv.Register("ZINTERSTORE", m.makeCmdZinter(true))
+	m.srv.Register("ZINTERCARD", m.cmdZintercard)
 	m.srv.Register("ZLEXCOUNT", m.cmdZlexcount)
 	m.srv.Register("ZRANGE", m.cmdZrange)
+	m.srv.Register("ZRANGESTORE", m.cmdZrangestore)
 	m.srv.Register("ZRANGEBYLEX", m.makeCmdZrangebylex(false))
 	m.srv.Register("ZRANGEBYSCORE", m.makeCmdZrangebyscore(false))
 	m.srv.Register("ZRANK", m.makeCmdZrank(false))
@@ -42,6 +46,8 @@ func commandsSortedSet(m *Miniredis) {
 	m.srv.Register("ZPOPMAX", m.cmdZpopmax(true))
 	m.srv.Register("ZPOPMIN", m.cmdZpopmax(false))
 	m.srv.Register("ZRANDMEMBER", m.cmdZrandmember)
+	m.srv.Register("ZMPOP", m.cmdZmpop)
+	m.srv.Register("BZMPOP", m.cmdBzmpop)
 }
 
 // ZADD
@@ -104,20 +110,9 @@ outer:
 	}
 
 	if len(args) == 0 || len(args)%2 != 0 {
-		setDirty(c)
-		c.WriteError(msgSyntaxError)
+		syntaxError(c)
 		return
 	}
-	for len(args) > 0 {
-		score, err := strconv.ParseFloat(args[0], 64)
-		if err != nil {
-			setDirty(c)
-			c.WriteError(msgInvalidFloat)
-			return
-		}
-		elems[args[1]] = score
-		args = args[2:]
-	}
 
 	if opts.xx && opts.nx {
 		setDirty(c)
@@ -133,12 +128,23 @@ outer:
 		return
 	}
 
-	if opts.incr && len(elems) > 1 {
+	if opts.incr && len(args) > 2 {
 		setDirty(c)
 		c.WriteError(msgSingleElementPair)
 		return
 	}
 
+	for len(args) > 0 {
+		score, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			setDirty(c)
+			c.WriteError(msgInvalidFloat)
+			return
+		}
+		elems[args[1]] = score
+		args = args[2:]
+	}
+
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
 
@@ -490,7 +496,7 @@ func (m *Miniredis) makeCmdZinter(store bool) func(c *server.Peer, cmd string, a
 
 			if opts.Store {
 				// ZINTERSTORE mode
-				db.del(opts.Destination, true)
+				db.unset(opts.Destination, true)
 				db.ssetSet(opts.Destination, sset)
 				c.WriteInt(len(sset))
 				return
@@ -511,6 +517,39 @@ func (m *Miniredis) makeCmdZinter(store bool) func(c *server.Peer, cmd string, a
 	}
 }
 
+// ZINTERCARD
+func (m *Miniredis) cmdZintercard(c *server.Peer, cmd string, args []string) {
+	if len(args) < 2 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	keys, limit, err := intercardParse(args)
+	if err != nil {
+		setDirty(c)
+		c.WriteError(err.Error())
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		count, err := db.zsetIntercard(keys, limit)
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+		c.WriteInt(count)
+	})
+}
+
 // ZLEXCOUNT
 func (m *Miniredis) cmdZlexcount(c *server.Peer, cmd string, args []string) {
 	if len(args) != 3 {
@@ -609,6 +648,7 @@ func (m *Miniredis) cmdZrange(c *server.Peer, cmd string, args []string) {
 			opts.WithLimit = true
 			args = args[1:]
 			if len(args) < 2 {
+				setDirty(c)
 				c.WriteError(msgSyntaxError)
 				return
 			}
@@ -619,10 +659,16 @@ func (m *Miniredis) cmdZrange(c *server.Peer, cmd string, args []string) {
 			opts.WithScores = true
 			args = args[1:]
 		default:
+			setDirty(c)
 			c.WriteError(msgSyntaxError)
 			return
 		}
 	}
+	if opts.ByLex && opts.WithScores {
+		setDirty(c)
+		c.WriteError(msgWithScoresByLex)
+		return
+	}
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		switch {
@@ -666,6 +712,130 @@ func (m *Miniredis) cmdZrange(c *server.Peer, cmd string, args []string) {
 	})
 }
 
+// ZRANGESTORE
+func (m *Miniredis) cmdZrangestore(c *server.Peer, cmd string, args []string) {
+	if len(args) < 4 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		Destination string
+		Key         string
+		Min         string
+		Max         string
+		ByScore     bool
+		ByLex       bool
+		Reverse     bool
+		WithLimit   bool
+		Offset      string
+		Count       string
+	}
+
+	opts.Destination, opts.Key, opts.Min, opts.Max = args[0], args[1], args[2], args[3]
+	args = args[4:]
+
+	for len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "byscore":
+			opts.ByScore = true
+			args = args[1:]
+		case "bylex":
+			opts.ByLex = true
+			args = args[1:]
+		case "rev":
+			opts.Reverse = true
+			args = args[1:]
+		case "limit":
+			opts.WithLimit = true
+			args = args[1:]
+			if len(args) < 2 {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			opts.Offset = args[0]
+			opts.Count = args[1]
+			args = args[2:]
+		default:
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+	}
+	if opts.ByScore && opts.ByLex {
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return
+	}
+	if opts.WithLimit && !opts.ByScore && !opts.ByLex {
+		setDirty(c)
+		c.WriteError(msgLimitCombination)
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		var (
+			elems ssElems
+			err   error
+		)
+		switch {
+		case opts.ByScore:
+			elems, err = zrangeByScore(db, optsRangeByScore{
+				Key:       opts.Key,
+				Min:       opts.Min,
+				Max:       opts.Max,
+				Reverse:   opts.Reverse,
+				WithLimit: opts.WithLimit,
+				Offset:    opts.Offset,
+				Count:     opts.Count,
+			})
+		case opts.ByLex:
+			elems, err = zrangeByLex(db, optsRangeByLex{
+				Key:       opts.Key,
+				Min:       opts.Min,
+				Max:       opts.Max,
+				Reverse:   opts.Reverse,
+				WithLimit: opts.WithLimit,
+				Offset:    opts.Offset,
+				Count:     opts.Count,
+			})
+		default:
+			elems, err = zrange(db, optsRange{
+				Key:     opts.Key,
+				Min:     opts.Min,
+				Max:     opts.Max,
+				Reverse: opts.Reverse,
+			})
+		}
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+
+		if len(elems) > 0 {
+			sset := newSortedSet()
+			for _, el := range elems {
+				sset[el.member] = el.score
+			}
+			db.unset(opts.Destination, true)
+			db.ssetSet(opts.Destination, sset)
+		} else {
+			db.del(opts.Destination, true)
+		}
+		c.WriteInt(len(elems))
+	})
+}
+
 // ZREVRANGE
 func (m *Miniredis) cmdZrevrange(c *server.Peer, cmd string, args []string) {
 	if len(args) < 3 {
@@ -822,21 +992,20 @@ func (m *Miniredis) makeCmdZrank(reverse bool) server.Cmd {
 
 		key, member := args[0], args[1]
 
+		withScore := false
+		if len(args) > 0 && strings.ToUpper(args[len(args)-1]) == "WITHSCORE" {
+			withScore = true
+			args = args[:len(args)-1]
+		}
+		if len(args) != 2 {
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+
 		withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 			db := m.db(ctx.selectedDB)
 
-			withScore := false
-			if len(args) > 0 && strings.ToUpper(args[len(args)-1]) == "WITHSCORE" {
-				withScore = true
-				args = args[:len(args)-1]
-			}
-
-			if len(args) > 2 {
-				setDirty(c)
-				c.WriteError(msgSyntaxError)
-				return
-			}
-
 			if !db.exists(key) {
 				if withScore {
 					c.WriteLen(-1)
@@ -1176,11 +1345,14 @@ func parseFloatRange(s string) (float64, bool, error) {
 	}
 	switch strings.ToLower(s) {
 	case "+inf":
-		return math.Inf(+1), true, nil
+		return math.Inf(+1), inclusive, nil
 	case "-inf":
-		return math.Inf(-1), true, nil
+		return math.Inf(-1), inclusive, nil
 	default:
 		f, err := strconv.ParseFloat(s, 64)
+		if err == nil && math.IsNaN(f) {
+			return 0, false, errors.New(msgInvalidMinMax)
+		}
 		return f, inclusive, err
 	}
 }
@@ -1403,7 +1575,7 @@ func (m *Miniredis) cmdZunionstore(c *server.Peer, cmd string, args []string) {
 			}
 		}
 		if deleteDest {
-			db.del(destination, true)
+			db.unset(destination, true)
 		}
 
 		sset, err := executeZUnion(db, opts)
@@ -1522,16 +1694,20 @@ func (m *Miniredis) cmdZscan(c *server.Peer, cmd string, args []string) {
 
 	var opts struct {
 		key       string
-		cursor    int
+		cursor    uint64
 		count     int
 		withMatch bool
 		match     string
 	}
 
 	opts.key = args[0]
-	if ok := optIntErr(c, args[1], &opts.cursor, msgInvalidCursor); !ok {
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		setDirty(c)
+		c.WriteError(msgInvalidCursor)
 		return
 	}
+	opts.cursor = cursor
 	args = args[2:]
 	// MATCH and COUNT options
 	for len(args) > 0 {
@@ -1579,33 +1755,14 @@ func (m *Miniredis) cmdZscan(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		members := db.ssetMembers(opts.key)
+		cursor, members := scanMembers(db.ssetMembers(opts.key), opts.cursor, opts.count)
 		if opts.withMatch {
 			members, _ = matchKeys(members, opts.match)
 		}
 
-		low := opts.cursor
-		high := low + opts.count
-		// validate high is correct
-		if high > len(members) || high == 0 {
-			high = len(members)
-		}
-		if opts.cursor > high {
-			// invalid cursor
-			c.WriteLen(2)
-			c.WriteBulk("0") // no next cursor
-			c.WriteLen(0)    // no elements
-			return
-		}
-		cursorValue := low + opts.count
-		if cursorValue >= len(members) {
-			cursorValue = 0 // no next cursor
-		}
-		members = members[low:high]
-
 		c.WriteLen(2)
-		c.WriteBulk(fmt.Sprintf("%d", cursorValue))
-		// HSCAN gives key, values.
+		c.WriteBulk(strconv.FormatUint(cursor, 10))
+		// ZSCAN gives key, values.
 		c.WriteLen(len(members) * 2)
 		for _, k := range members {
 			c.WriteBulk(k)
@@ -1678,6 +1835,188 @@ func (m *Miniredis) cmdZpopmax(reverse bool) server.Cmd {
 	}
 }
 
+// BZPOPMAX and BZPOPMIN
+func (m *Miniredis) cmdBzpopmax(reverse bool) server.Cmd {
+	return func(c *server.Peer, cmd string, args []string) {
+		if len(args) < 2 {
+			setDirty(c)
+			c.WriteError(errWrongNumber(cmd))
+			return
+		}
+		if !m.handleAuth(c) {
+			return
+		}
+		if m.checkPubsub(c, cmd) {
+			return
+		}
+
+		var opts struct {
+			keys    []string
+			timeout time.Duration
+		}
+
+		if ok := optDuration(c, args[len(args)-1], &opts.timeout); !ok {
+			return
+		}
+		opts.keys = args[:len(args)-1]
+
+		blocking(
+			m,
+			c,
+			cmd,
+			opts.timeout,
+			func(c *server.Peer, ctx *connCtx) bool {
+				db := m.db(ctx.selectedDB)
+				for _, key := range opts.keys {
+					if !db.exists(key) {
+						continue
+					}
+					if db.t(key) != "zset" {
+						c.WriteError(msgWrongType)
+						return true
+					}
+
+					members := db.ssetMembers(key)
+					if len(members) == 0 {
+						continue
+					}
+					el := members[0]
+					if reverse {
+						el = members[len(members)-1]
+					}
+					c.WriteLen(3)
+					c.WriteBulk(key)
+					c.WriteBulk(el)
+					c.WriteFloat(db.ssetScore(key, el))
+					db.ssetRem(key, el)
+					return true
+				}
+				return false
+			},
+			func(c *server.Peer) {
+				// timeout
+				c.WriteLen(-1)
+			},
+		)
+	}
+}
+
+// ZMPOP
+func (m *Miniredis) cmdZmpop(c *server.Peer, cmd string, args []string) {
+	if len(args) < 3 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		keys  []string
+		where int
+		count int
+	}
+	if ok := optMpop(c, args, [2]string{"MIN", "MAX"}, &opts.keys, &opts.where, &opts.count); !ok {
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		if !zmpop(c, db, opts.keys, opts.where == 1, opts.count) {
+			if c.Resp3 {
+				c.WriteNull()
+				return
+			}
+			c.WriteLen(-1)
+		}
+	})
+}
+
+// BZMPOP
+func (m *Miniredis) cmdBzmpop(c *server.Peer, cmd string, args []string) {
+	if len(args) < 4 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		timeout time.Duration
+		keys    []string
+		where   int
+		count   int
+	}
+	if ok := optDuration(c, args[0], &opts.timeout); !ok {
+		return
+	}
+	if ok := optMpop(c, args[1:], [2]string{"MIN", "MAX"}, &opts.keys, &opts.where, &opts.count); !ok {
+		return
+	}
+
+	blocking(
+		m,
+		c,
+		cmd,
+		opts.timeout,
+		func(c *server.Peer, ctx *connCtx) bool {
+			db := m.db(ctx.selectedDB)
+			return zmpop(c, db, opts.keys, opts.where == 1, opts.count)
+		},
+		func(c *server.Peer) {
+			// timeout
+			c.WriteLen(-1)
+		},
+	)
+}
+
+// zmpop pops up to count members from the first non-empty sorted set, and
+// writes the [key, [[member, score]...]] reply. Returns false, and writes
+// nothing, if all sets are empty.
+func zmpop(c *server.Peer, db *RedisDB, keys []string, reverse bool, count int) bool {
+	for _, key := range keys {
+		if !db.exists(key) {
+			continue
+		}
+		if db.t(key) != "zset" {
+			c.WriteError(msgWrongType)
+			return true
+		}
+
+		members := db.ssetMembers(key)
+		if len(members) == 0 {
+			continue
+		}
+		if reverse {
+			reverseSlice(members)
+		}
+		if len(members) > count {
+			members = members[:count]
+		}
+		c.WriteLen(2)
+		c.WriteBulk(key)
+		c.WriteLen(len(members))
+		for _, el := range members {
+			c.WriteLen(2)
+			c.WriteBulk(el)
+			c.WriteFloat(db.ssetScore(key, el))
+			db.ssetRem(key, el)
+		}
+		return true
+	}
+	return false
+}
+
 // ZRANDMEMBER
 func (m *Miniredis) cmdZrandmember(c *server.Peer, cmd string, args []string) {
 	if len(args) < 1 {
@@ -1787,41 +2126,37 @@ type optsRange struct {
 }
 
 func runRange(m *Miniredis, c *server.Peer, cctx *connCtx, opts optsRange) {
+	elems, err := zrange(m.db(cctx.selectedDB), opts)
+	if err != nil {
+		c.WriteError(err.Error())
+		return
+	}
+	writeSSElems(c, elems, opts.WithScores)
+}
+
+// zrange gives the elements for ZRANGE (by rank). A missing key gives no
+// elements.
+func zrange(db *RedisDB, opts optsRange) (ssElems, error) {
 	min, minErr := strconv.Atoi(opts.Min)
 	max, maxErr := strconv.Atoi(opts.Max)
 	if minErr != nil || maxErr != nil {
-		c.WriteError(msgInvalidInt)
-		return
+		return nil, errors.New(msgInvalidInt)
 	}
 
-	db := m.db(cctx.selectedDB)
-
 	if !db.exists(opts.Key) {
-		c.WriteLen(0)
-		return
+		return nil, nil
 	}
 
 	if db.t(opts.Key) != "zset" {
-		c.WriteError(ErrWrongType.Error())
-		return
+		return nil, ErrWrongType
 	}
 
-	members := db.ssetMembers(opts.Key)
+	members := db.ssetElements(opts.Key)
 	if opts.Reverse {
-		reverseSlice(members)
+		reverseElems(members)
 	}
 	rs, re := redisRange(len(members), min, max, false)
-	if opts.WithScores {
-		c.WriteLen((re - rs) * 2)
-	} else {
-		c.WriteLen(re - rs)
-	}
-	for _, el := range members[rs:re] {
-		c.WriteBulk(el)
-		if opts.WithScores {
-			c.WriteFloat(db.ssetScore(opts.Key, el))
-		}
-	}
+	return members[rs:re], nil
 }
 
 type optsRangeByScore struct {
@@ -1836,37 +2171,41 @@ type optsRangeByScore struct {
 }
 
 func runRangeByScore(m *Miniredis, c *server.Peer, cctx *connCtx, opts optsRangeByScore) {
+	elems, err := zrangeByScore(m.db(cctx.selectedDB), opts)
+	if err != nil {
+		c.WriteError(err.Error())
+		return
+	}
+	writeSSElems(c, elems, opts.WithScores)
+}
+
+// zrangeByScore gives the elements for ZRANGE BYSCORE. A missing key gives no
+// elements.
+func zrangeByScore(db *RedisDB, opts optsRangeByScore) (ssElems, error) {
 	var limitOffset, limitCount int
 	var err error
 	if opts.WithLimit {
 		limitOffset, err = strconv.Atoi(opts.Offset)
 		if err != nil {
-			c.WriteError(msgInvalidInt)
-			return
+			return nil, errors.New(msgInvalidInt)
 		}
 		limitCount, err = strconv.Atoi(opts.Count)
 		if err != nil {
-			c.WriteError(msgInvalidInt)
-			return
+			return nil, errors.New(msgInvalidInt)
 		}
 	}
 	min, minIncl, minErr := parseFloatRange(opts.Min)
 	max, maxIncl, maxErr := parseFloatRange(opts.Max)
 	if minErr != nil || maxErr != nil {
-		c.WriteError(msgInvalidMinMax)
-		return
+		return nil, errors.New(msgInvalidMinMax)
 	}
 
-	db := m.db(cctx.selectedDB)
-
 	if !db.exists(opts.Key) {
-		c.WriteLen(0)
-		return
+		return nil, nil
 	}
 
 	if db.t(opts.Key) != "zset" {
-		c.WriteError(ErrWrongType.Error())
-		return
+		return nil, ErrWrongType
 	}
 
 	members := db.ssetElements(opts.Key)
@@ -1879,36 +2218,10 @@ func runRangeByScore(m *Miniredis, c *server.Peer, cctx *connCtx, opts optsRange
 		reverseElems(members)
 	}
 
-	// Apply LIMIT ranges. That's <start> <elements>. Unlike RANGE.
 	if opts.WithLimit {
-		if limitOffset < 0 {
-			members = ssElems{}
-		} else {
-			if limitOffset < len(members) {
-				members = members[limitOffset:]
-			} else {
-				// out of range
-				members = ssElems{}
-			}
-			if limitCount >= 0 {
-				if len(members) > limitCount {
-					members = members[:limitCount]
-				}
-			}
-		}
-	}
-
-	if opts.WithScores {
-		c.WriteLen(len(members) * 2)
-	} else {
-		c.WriteLen(len(members))
-	}
-	for _, el := range members {
-		c.WriteBulk(el.member)
-		if opts.WithScores {
-			c.WriteFloat(el.score)
-		}
+		members = withLimit(members, limitOffset, limitCount)
 	}
+	return members, nil
 }
 
 type optsRangeByLex struct {
@@ -1923,37 +2236,41 @@ type optsRangeByLex struct {
 }
 
 func runRangeByLex(m *Miniredis, c *server.Peer, cctx *connCtx, opts optsRangeByLex) {
+	elems, err := zrangeByLex(m.db(cctx.selectedDB), opts)
+	if err != nil {
+		c.WriteError(err.Error())
+		return
+	}
+	writeSSElems(c, elems, false)
+}
+
+// zrangeByLex gives the elements for ZRANGE BYLEX. A missing key gives no
+// elements.
+func zrangeByLex(db *RedisDB, opts optsRangeByLex) (ssElems, error) {
 	var limitOffset, limitCount int
 	var err error
 	if opts.WithLimit {
 		limitOffset, err = strconv.Atoi(opts.Offset)
 		if err != nil {
-			c.WriteError(msgInvalidInt)
-			return
+			return nil, errors.New(msgInvalidInt)
 		}
 		limitCount, err = strconv.Atoi(opts.Count)
 		if err != nil {
-			c.WriteError(msgInvalidInt)
-			return
+			return nil, errors.New(msgInvalidInt)
 		}
 	}
 	min, minIncl, minErr := parseLexrange(opts.Min)
 	max, maxIncl, maxErr := parseLexrange(opts.Max)
 	if minErr != nil || maxErr != nil {
-		c.WriteError(msgInvalidRangeItem)
-		return
+		return nil, errors.New(msgInvalidRangeItem)
 	}
 
-	db := m.db(cctx.selectedDB)
-
 	if !db.exists(opts.Key) {
-		c.WriteLen(0)
-		return
+		return nil, nil
 	}
 
 	if db.t(opts.Key) != "zset" {
-		c.WriteError(ErrWrongType.Error())
-		return
+		return nil, ErrWrongType
 	}
 
 	members := db.ssetMembers(opts.Key)
@@ -1968,28 +2285,40 @@ func runRangeByLex(m *Miniredis, c *server.Peer, cctx *connCtx, opts optsRangeBy
 		reverseSlice(members)
 	}
 
-	// Apply LIMIT ranges. That's <start> <elements>. Unlike RANGE.
+	elems := make(ssElems, 0, len(members))
+	for _, member := range members {
+		elems = append(elems, ssElem{score: db.ssetScore(opts.Key, member), member: member})
+	}
 	if opts.WithLimit {
-		if limitOffset < 0 {
-			members = nil
-		} else {
-			if limitOffset < len(members) {
-				members = members[limitOffset:]
-			} else {
-				// out of range
-				members = nil
-			}
-			if limitCount >= 0 {
-				if len(members) > limitCount {
-					members = members[:limitCount]
-				}
-			}
-		}
+		elems = withLimit(elems, limitOffset, limitCount)
+	}
+	return elems, nil
+}
+
+// withLimit applies LIMIT ranges. That's <start> <elements>. Unlike RANGE.
+func withLimit(elems ssElems, offset, count int) ssElems {
+	if offset < 0 || offset >= len(elems) {
+		return nil
+	}
+	elems = elems[offset:]
+	if count >= 0 && len(elems) > count {
+		elems = elems[:count]
 	}
+	return elems
+}
 
-	c.WriteLen(len(members))
-	for _, el := range members {
-		c.WriteBulk(el)
+// writeSSElems writes the members, and maybe their scores.
+func writeSSElems(c *server.Peer, elems ssElems, withScores bool) {
+	if withScores {
+		c.WriteLen(len(elems) * 2)
+	} else {
+		c.WriteLen(len(elems))
+	}
+	for _, el := range elems {
+		c.WriteBulk(el.member)
+		if withScores {
+			c.WriteFloat(el.score)
+		}
 	}
 }
 
@@ -1,8 +1,11 @@
 package miniredis
 
 import (
+	"fmt"
 	"math"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/proto"
 )
@@ -112,6 +115,15 @@ func TestSortedSet(t *testing.T) {
 		mustNil(t, c,
 			"ZRANK", "nosuch", "nosuch",
 		)
+
+		mustDo(t, c,
+			"ZRANK", "z", "nosuch", "WITHSCORE",
+			proto.NilList,
+		)
+		mustDo(t, c,
+			"ZREVRANK", "nosuch", "nosuch", "WITHSCORE",
+			proto.NilList,
+		)
 	}
 
 	// Direct usage
@@ -146,6 +158,20 @@ func TestSortedSet(t *testing.T) {
 			"ZRANK", "set", "spurious", "args",
 			proto.Error(msgSyntaxError),
 		)
+		mustDo(t, c,
+			"ZREVRANK", "set", "spurious", "WITHSCORE", "WITHSCORE",
+			proto.Error(msgSyntaxError),
+		)
+		// syntax errors are found when queueing
+		mustOK(t, c, "MULTI")
+		mustDo(t, c,
+			"ZRANK", "set", "spurious", "args",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"EXEC",
+			proto.Error("EXECABORT Transaction discarded because of previous errors."),
+		)
 
 		mustDo(t, c,
 			"ZREVRANK",
@@ -297,6 +323,18 @@ func TestSortedSetAdd(t *testing.T) {
 			"ZADD", "set", "GT", "LT", "1.0", "foo",
 			proto.Error(msgGTLTandNX),
 		)
+		mustDo(t, c,
+			"ZADD", "set", "NX", "XX", "noint", "foo",
+			proto.Error(msgXXandNX),
+		)
+		mustDo(t, c,
+			"ZADD", "set", "INCR", "1.0", "foo", "2.3", "foo",
+			proto.Error(msgSingleElementPair),
+		)
+		mustDo(t, c,
+			"ZADD", "set", "FOO", "1.0", "foo",
+			proto.Error(msgSyntaxError),
+		)
 	})
 
 	useRESP3(t, c)
@@ -380,6 +418,10 @@ func TestSortedSetRange(t *testing.T) {
 			"ZRANGE", "z", "0", "+inf", "BYSCORE", "LIMIT", "1", "9999",
 			proto.Strings("two", "zwei", "drei", "three", "inf"),
 		)
+		mustDo(t, c,
+			"ZRANGE", "z", "+inf", "2", "BYSCORE", "REV", "LIMIT", "1", "2", "WITHSCORES",
+			proto.Strings("three", "3", "drei", "3"),
+		)
 	})
 
 	t.Run("errors", func(t *testing.T) {
@@ -411,6 +453,10 @@ func TestSortedSetRange(t *testing.T) {
 			"ZRANGE", "set", "1", "2", "LIMIT", "1", "2",
 			proto.Error(msgLimitCombination),
 		)
+		mustDo(t, c,
+			"ZRANGE", "set", "-", "+", "BYLEX", "WITHSCORES",
+			proto.Error(msgWithScoresByLex),
+		)
 		// Wrong type of key
 		s.Set("str", "value")
 		mustDo(t, c,
@@ -653,6 +699,31 @@ func TestSortedSetRangeByScore(t *testing.T) {
 		)
 	}
 
+	t.Run("limit with scores", func(t *testing.T) {
+		s.ZAdd("five", 1, "a")
+		s.ZAdd("five", 2, "b")
+		s.ZAdd("five", 3, "c")
+		s.ZAdd("five", 4, "d")
+		s.ZAdd("five", 5, "e")
+
+		mustDo(t, c,
+			"ZRANGEBYSCORE", "five", "-inf", "+inf", "LIMIT", "1", "2", "WITHSCORES",
+			proto.Strings("b", "2", "c", "3"),
+		)
+		mustDo(t, c,
+			"ZRANGEBYSCORE", "five", "(1", "5", "WITHSCORES", "LIMIT", "1", "2",
+			proto.Strings("c", "3", "d", "4"),
+		)
+		mustDo(t, c,
+			"ZREVRANGEBYSCORE", "five", "(5", "-inf", "LIMIT", "1", "2", "WITHSCORES",
+			proto.Strings("c", "3", "b", "2"),
+		)
+		mustDo(t, c,
+			"ZRANGEBYSCORE", "five", "(4", "(5", "LIMIT", "1", "2", "WITHSCORES",
+			proto.Strings(),
+		)
+	})
+
 	t.Run("errors", func(t *testing.T) {
 		mustDo(t, c,
 			"ZRANGEBYSCORE",
@@ -713,6 +784,62 @@ func TestSortedSetRangeByScore(t *testing.T) {
 	})
 }
 
+// Test ZCOUNT and ZLEXCOUNT boundaries
+func TestSortedSetCount(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("zcount", func(t *testing.T) {
+		s.ZAdd("z", math.Inf(-1), "min")
+		s.ZAdd("z", 1, "one")
+		s.ZAdd("z", 2, "two")
+		s.ZAdd("z", 3, "three")
+		s.ZAdd("z", math.Inf(+1), "max")
+
+		mustDo(t, c, "ZCOUNT", "z", "1", "3", proto.Int(3))
+		mustDo(t, c, "ZCOUNT", "z", "(1", "3", proto.Int(2))
+		mustDo(t, c, "ZCOUNT", "z", "1", "(3", proto.Int(2))
+		mustDo(t, c, "ZCOUNT", "z", "(1", "(2", proto.Int(0))
+		mustDo(t, c, "ZCOUNT", "z", "-inf", "+inf", proto.Int(5))
+		mustDo(t, c, "ZCOUNT", "z", "(-inf", "(+inf", proto.Int(3))
+		mustDo(t, c, "ZCOUNT", "z", "3", "1", proto.Int(0))
+		mustDo(t, c, "ZCOUNT", "nosuch", "-inf", "+inf", proto.Int(0))
+
+		mustDo(t, c,
+			"ZRANGEBYSCORE", "z", "(-inf", "(+inf",
+			proto.Strings("one", "two", "three"),
+		)
+
+		mustDo(t, c,
+			"ZCOUNT", "z", "nan", "1",
+			proto.Error(msgInvalidMinMax),
+		)
+		mustDo(t, c,
+			"ZCOUNT", "z", "[1", "2",
+			proto.Error(msgInvalidMinMax),
+		)
+	})
+
+	t.Run("zlexcount", func(t *testing.T) {
+		for _, m := range []string{"a", "b", "c", "d", "e"} {
+			s.ZAdd("lex", 0, m)
+		}
+
+		mustDo(t, c, "ZLEXCOUNT", "lex", "-", "+", proto.Int(5))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "[b", "[d", proto.Int(3))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "(b", "[d", proto.Int(2))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "(b", "(d", proto.Int(1))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "-", "(c", proto.Int(2))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "(c", "+", proto.Int(2))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "+", "-", proto.Int(0))
+		mustDo(t, c, "ZLEXCOUNT", "lex", "[d", "[b", proto.Int(0))
+
+		mustDo(t, c,
+			"ZRANGEBYLEX", "lex", "(b", "[d",
+			proto.Strings("c", "d"),
+		)
+	})
+}
+
 func TestIssue10(t *testing.T) {
 	s, c := runWithClient(t)
 
@@ -1446,16 +1573,7 @@ func TestZscan(t *testing.T) {
 		),
 	)
 
-	// Invalid cursor
-	mustDo(t, c,
-		"ZSCAN", "h", "42",
-		proto.Array(
-			proto.String("0"),
-			proto.Array(),
-		),
-	)
-
-	// COUNT (ignored)
+	// COUNT bigger than the set
 	mustDo(t, c,
 		"ZSCAN", "h", "0", "COUNT", "200",
 		proto.Array(
@@ -1524,55 +1642,15 @@ func TestZscan(t *testing.T) {
 		)
 	})
 
-	s.ZAdd("largeset", 1.0, "v1")
-	s.ZAdd("largeset", 2.0, "v2")
-	s.ZAdd("largeset", 3.0, "v3")
-	s.ZAdd("largeset", 4.0, "v4")
-	s.ZAdd("largeset", 5.0, "v5")
-	s.ZAdd("largeset", 6.0, "v6")
-	s.ZAdd("largeset", 7.0, "v7")
-	s.ZAdd("largeset", 8.0, "v8")
-
-	mustDo(t, c,
-		"ZSCAN", "largeset", "0", "COUNT", "3",
-		proto.Array(
-			proto.String("3"),
-			proto.Array(
-				proto.String("v1"),
-				proto.String("1"),
-				proto.String("v2"),
-				proto.String("2"),
-				proto.String("v3"),
-				proto.String("3"),
-			),
-		),
-	)
-	mustDo(t, c,
-		"ZSCAN", "largeset", "3", "COUNT", "3",
-		proto.Array(
-			proto.String("6"),
-			proto.Array(
-				proto.String("v4"),
-				proto.String("4"),
-				proto.String("v5"),
-				proto.String("5"),
-				proto.String("v6"),
-				proto.String("6"),
-			),
-		),
-	)
-	mustDo(t, c,
-		"ZSCAN", "largeset", "6", "COUNT", "3",
-		proto.Array(
-			proto.String("0"),
-			proto.Array(
-				proto.String("v7"),
-				proto.String("7"),
-				proto.String("v8"),
-				proto.String("8"),
-			),
-		),
-	)
+	t.Run("cursor", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			s.ZAdd("largeset", float64(i), fmt.Sprintf("v%d", i))
+		}
+		seen := scanAll(t, c, []string{"ZSCAN", "largeset"}, "COUNT", "10")
+		for i := 0; i < 100; i++ {
+			equals(t, 1, seen[fmt.Sprintf("v%d", i)])
+		}
+	})
 }
 
 func TestZunionstore(t *testing.T) {
@@ -1949,6 +2027,198 @@ func TestZinterstore(t *testing.T) {
 	})
 }
 
+func TestZintercard(t *testing.T) {
+	s, c := runWithClient(t)
+
+	for i := 0; i < 10; i++ {
+		s.ZAdd("z1", float64(i), fmt.Sprintf("m%d", i))
+		if i%2 == 0 {
+			s.ZAdd("z2", float64(i), fmt.Sprintf("m%d", i))
+		}
+	}
+	s.SAdd("s1", "m0", "m2", "m3")
+
+	t.Run("basic", func(t *testing.T) {
+		for _, keys := range [][]string{
+			{"z1"},
+			{"z1", "z2"},
+			{"z1", "z2", "s1"},
+			{"z1", "nosuch"},
+		} {
+			args := append([]string{strconv.Itoa(len(keys))}, keys...)
+			n, err := c.Do(append([]string{"ZINTERSTORE", "dest"}, args...)...)
+			ok(t, err)
+			mustDo(t, c, "ZCARD", "dest", n)
+			mustDo(t, c, append(append([]string{"ZINTERCARD"}, args...), n)...)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		mustDo(t, c, "ZINTERCARD", "2", "z1", "z2", "LIMIT", "0", proto.Int(5))
+		mustDo(t, c, "ZINTERCARD", "2", "z1", "z2", "LIMIT", "3", proto.Int(3))
+		mustDo(t, c, "ZINTERCARD", "2", "z1", "z2", "limit", "1", proto.Int(1))
+		mustDo(t, c, "ZINTERCARD", "2", "z1", "z2", "LIMIT", "100", proto.Int(5))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"ZINTERCARD",
+			proto.Error(errWrongNumber("zintercard")),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "1",
+			proto.Error(errWrongNumber("zintercard")),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "0", "z1",
+			proto.Error("ERR numkeys should be greater than 0"),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "noint", "z1",
+			proto.Error("ERR numkeys should be greater than 0"),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "3", "z1", "z2",
+			proto.Error("ERR Number of keys can't be greater than number of args"),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "1", "z1", "z2",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "1", "z1", "LIMIT", "-1",
+			proto.Error(msgLimitIsNegative),
+		)
+		mustDo(t, c,
+			"ZINTERCARD", "1", "z1", "LIMIT", "noint",
+			proto.Error(msgInvalidInt),
+		)
+
+		s.Set("str", "value")
+		mustDo(t, c,
+			"ZINTERCARD", "2", "nosuch", "str",
+			proto.Error(msgWrongType),
+		)
+	})
+}
+
+func TestZrangestore(t *testing.T) {
+	s, c := runWithClient(t)
+
+	s.ZAdd("z", 1, "one")
+	s.ZAdd("z", 2, "two")
+	s.ZAdd("z", 2, "zwei")
+	s.ZAdd("z", 3, "three")
+	s.ZAdd("z", 4, "four")
+
+	t.Run("rank", func(t *testing.T) {
+		mustDo(t, c, "ZRANGESTORE", "dst", "z", "0", "1", proto.Int(2))
+		equals(t, map[string]float64{"one": 1, "two": 2}, s.DB(0).sortedSet("dst"))
+
+		mustDo(t, c, "ZRANGESTORE", "dst", "z", "0", "1", "REV", proto.Int(2))
+		equals(t, map[string]float64{"four": 4, "three": 3}, s.DB(0).sortedSet("dst"))
+
+		mustDo(t, c, "ZRANGESTORE", "dst", "z", "-2", "-1", proto.Int(2))
+		mustDo(t, c,
+			"ZRANGE", "dst", "0", "-1", "WITHSCORES",
+			proto.Strings("three", "3", "four", "4"),
+		)
+	})
+
+	t.Run("BYSCORE", func(t *testing.T) {
+		mustDo(t, c, "ZRANGESTORE", "dst", "z", "(1", "3", "BYSCORE", proto.Int(3))
+		mustDo(t, c,
+			"ZRANGE", "dst", "0", "-1",
+			proto.Strings("two", "zwei", "three"),
+		)
+
+		mustDo(t, c, "ZRANGESTORE", "dst", "z", "+inf", "2", "BYSCORE", "REV", "LIMIT", "1", "2", proto.Int(2))
+		mustDo(t, c,
+			"ZRANGE", "dst", "0", "-1",
+			proto.Strings("zwei", "three"),
+		)
+	})
+
+	t.Run("BYLEX", func(t *testing.T) {
+		s.ZAdd("lex", 0, "a")
+		s.ZAdd("lex", 0, "b")
+		s.ZAdd("lex", 0, "c")
+		s.ZAdd("lex", 0, "d")
+
+		mustDo(t, c, "ZRANGESTORE", "dst", "lex", "[b", "+", "BYLEX", proto.Int(3))
+		mustDo(t, c,
+			"ZRANGE", "dst", "0", "-1",
+			proto.Strings("b", "c", "d"),
+		)
+
+		mustDo(t, c, "ZRANGESTORE", "dst", "lex", "+", "-", "BYLEX", "REV", "LIMIT", "0", "2", proto.Int(2))
+		mustDo(t, c,
+			"ZRANGE", "dst", "0", "-1",
+			proto.Strings("c", "d"),
+		)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		mustOK(t, c, "SET", "str", "value")
+		mustDo(t, c, "ZRANGESTORE", "str", "z", "10", "20", proto.Int(0))
+		equals(t, false, s.Exists("str"))
+
+		mustDo(t, c, "ZRANGESTORE", "dst", "z", "0", "0", proto.Int(1))
+		mustDo(t, c, "ZRANGESTORE", "dst", "nosuch", "0", "-1", proto.Int(0))
+		equals(t, false, s.Exists("dst"))
+	})
+
+	t.Run("same key", func(t *testing.T) {
+		s.ZAdd("self", 1, "a")
+		s.ZAdd("self", 2, "b")
+		mustDo(t, c, "ZRANGESTORE", "self", "self", "1", "1", proto.Int(1))
+		mustDo(t, c,
+			"ZRANGE", "self", "0", "-1",
+			proto.Strings("b"),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "0",
+			proto.Error(errWrongNumber("zrangestore")),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "0", "1", "WITHSCORES",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "0", "1", "BYSCORE", "BYLEX",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "0", "1", "LIMIT", "0", "1",
+			proto.Error(msgLimitCombination),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "0", "1", "BYSCORE", "LIMIT", "0",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "foo", "1",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "foo", "1", "BYSCORE",
+			proto.Error(msgInvalidMinMax),
+		)
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "z", "foo", "1", "BYLEX",
+			proto.Error(msgInvalidRangeItem),
+		)
+		mustOK(t, c, "SET", "str", "value")
+		mustDo(t, c,
+			"ZRANGESTORE", "dst", "str", "0", "1",
+			proto.Error(msgWrongType),
+		)
+	})
+}
+
 func TestSSRange(t *testing.T) {
 	ss := newSortedSet()
 	ss.set(1.0, "key1")
@@ -2148,6 +2418,79 @@ func TestSortedSetPopMax(t *testing.T) {
 	})
 }
 
+// Test BZPOPMIN and BZPOPMAX
+func TestSortedSetBzpop(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("basic", func(t *testing.T) {
+		s.ZAdd("z", 1, "one")
+		s.ZAdd("z", 2, "two")
+		s.ZAdd("z", 3, "three")
+
+		mustDo(t, c,
+			"BZPOPMIN", "nosuch", "z", "1",
+			proto.Strings("z", "one", "1"),
+		)
+		mustDo(t, c,
+			"BZPOPMAX", "z", "1",
+			proto.Strings("z", "three", "3"),
+		)
+		mustDo(t, c,
+			"BZPOPMAX", "z", "1",
+			proto.Strings("z", "two", "2"),
+		)
+		must0(t, c, "EXISTS", "z")
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		got := goStrings(t, s, "BZPOPMIN", "zb", "0")
+		time.Sleep(30 * time.Millisecond)
+
+		mustDo(t, c,
+			"ZADD", "zb", "4", "four",
+			proto.Int(1),
+		)
+		select {
+		case have := <-got:
+			equals(t, proto.Strings("zb", "four", "4"), have)
+		case <-time.After(500 * time.Millisecond):
+			t.Error("BZPOPMIN took too long")
+		}
+		must0(t, c, "EXISTS", "zb")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		got := goStrings(t, s, "BZPOPMAX", "zb", "0.1")
+		select {
+		case have := <-got:
+			equals(t, proto.NilList, have)
+		case <-time.After(500 * time.Millisecond):
+			t.Error("BZPOPMAX took too long")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"BZPOPMIN",
+			proto.Error(errWrongNumber("bzpopmin")),
+		)
+		mustDo(t, c,
+			"BZPOPMIN", "key",
+			proto.Error(errWrongNumber("bzpopmin")),
+		)
+		mustDo(t, c,
+			"BZPOPMIN", "key", "-1",
+			proto.Error("ERR timeout is negative"),
+		)
+
+		s.Set("str", "value")
+		mustDo(t, c,
+			"BZPOPMAX", "str", "1",
+			proto.Error(msgWrongType),
+		)
+	})
+}
+
 // Test ZRANDMEMBER
 func TestSortedSetRandmember(t *testing.T) {
 	s, c := runWithClient(t)
@@ -2256,3 +2599,114 @@ func TestSortedSetRandmember(t *testing.T) {
 		)
 	})
 }
+
+// Test ZMPOP and BZMPOP
+func TestSortedSetMpop(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("basic", func(t *testing.T) {
+		s.ZAdd("z", 1, "one")
+		s.ZAdd("z", 2, "two")
+		s.ZAdd("z", 3, "three")
+
+		mustDo(t, c,
+			"ZMPOP", "2", "nosuch", "z", "MIN",
+			proto.Array(
+				proto.String("z"),
+				proto.Array(proto.Strings("one", "1")),
+			),
+		)
+		mustDo(t, c,
+			"ZMPOP", "1", "z", "max", "COUNT", "5",
+			proto.Array(
+				proto.String("z"),
+				proto.Array(
+					proto.Strings("three", "3"),
+					proto.Strings("two", "2"),
+				),
+			),
+		)
+		must0(t, c, "EXISTS", "z")
+		mustNilList(t, c,
+			"ZMPOP", "1", "z", "MIN",
+		)
+	})
+
+	t.Run("RESP3", func(t *testing.T) {
+		s, c := runWithClient(t)
+		useRESP3(t, c)
+
+		s.ZAdd("z", 1.5, "one")
+		mustDo(t, c,
+			"ZMPOP", "1", "z", "MIN",
+			proto.Array(
+				proto.String("z"),
+				proto.Array(proto.Array(proto.String("one"), proto.Float(1.5))),
+			),
+		)
+		mustDo(t, c,
+			"ZMPOP", "1", "z", "MIN",
+			proto.NilResp3,
+		)
+	})
+
+	t.Run("blocking", func(t *testing.T) {
+		got := goStrings(t, s, "BZMPOP", "0", "1", "zb", "MAX")
+		time.Sleep(30 * time.Millisecond)
+
+		mustDo(t, c,
+			"ZADD", "zb", "4", "four",
+			proto.Int(1),
+		)
+		select {
+		case have := <-got:
+			equals(t, proto.Array(proto.String("zb"), proto.Array(proto.Strings("four", "4"))), have)
+		case <-time.After(500 * time.Millisecond):
+			t.Error("BZMPOP took too long")
+		}
+		must0(t, c, "EXISTS", "zb")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		got := goStrings(t, s, "BZMPOP", "0.1", "1", "zb", "MAX")
+		select {
+		case have := <-got:
+			equals(t, proto.NilList, have)
+		case <-time.After(500 * time.Millisecond):
+			t.Error("BZMPOP took too long")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"ZMPOP", "1", "z",
+			proto.Error(errWrongNumber("zmpop")),
+		)
+		mustDo(t, c,
+			"ZMPOP", "0", "z", "MIN",
+			proto.Error(msgNumkeysPositive),
+		)
+		mustDo(t, c,
+			"ZMPOP", "1", "z", "LEFT",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"ZMPOP", "1", "z", "MIN", "COUNT", "-1",
+			proto.Error(msgCountPositive),
+		)
+		mustDo(t, c,
+			"BZMPOP", "0", "1", "z",
+			proto.Error(errWrongNumber("bzmpop")),
+		)
+		mustDo(t, c,
+			"BZMPOP", "foo", "1", "z", "MIN",
+			proto.Error(msgInvalidTimeout),
+		)
+
+		s.Set("str", "value")
+		mustDo(t, c,
+			"ZMPOP", "1", "str", "MIN",
+			proto.Error(msgWrongType),
+		)
+	})
+}
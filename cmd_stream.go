@@ -342,8 +342,9 @@ func (m *Miniredis) cmdXgroup(c *server.Peer, cmd string, args []string) {
 		m.cmdXgroupCreateconsumer(c, cmd, args)
 	case "delconsumer":
 		m.cmdXgroupDelconsumer(c, cmd, args)
-	case "help",
-		"setid":
+	case "setid":
+		m.cmdXgroupSetid(c, cmd, args)
+	case "help":
 		err := fmt.Sprintf("ERR 'XGROUP %s' not supported", subCmd)
 		setDirty(c)
 		c.WriteError(err)
@@ -358,23 +359,67 @@ func (m *Miniredis) cmdXgroup(c *server.Peer, cmd string, args []string) {
 
 // XGROUP CREATE
 func (m *Miniredis) cmdXgroupCreate(c *server.Peer, cmd string, args []string) {
-	if len(args) != 3 && len(args) != 4 {
+	if len(args) < 3 {
 		setDirty(c)
 		c.WriteError(errWrongNumber("CREATE"))
 		return
 	}
-	stream, group, id := args[0], args[1], args[2]
+	var opts struct {
+		stream, group, id string
+		mkstream          bool
+	}
+	opts.stream, opts.group, opts.id = args[0], args[1], args[2]
+	args = args[3:]
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "MKSTREAM":
+			opts.mkstream = true
+			args = args[1:]
+		case "ENTRIESREAD":
+			// we don't track entries-read, but we do validate it
+			if len(args) < 2 {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				setDirty(c)
+				c.WriteError(msgInvalidInt)
+				return
+			}
+			if n < -1 {
+				setDirty(c)
+				c.WriteError("ERR value for ENTRIESREAD must be positive or -1")
+				return
+			}
+			args = args[2:]
+		default:
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+	}
+	if opts.id != "$" {
+		id, err := formatStreamID(opts.id)
+		if err != nil {
+			setDirty(c)
+			c.WriteError(msgInvalidStreamID)
+			return
+		}
+		opts.id = id
+	}
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
 
-		s, err := db.stream(stream)
+		s, err := db.stream(opts.stream)
 		if err != nil {
 			c.WriteError(err.Error())
 			return
 		}
-		if s == nil && len(args) == 4 && strings.ToUpper(args[3]) == "MKSTREAM" {
-			if s, err = db.newStream(stream); err != nil {
+		if s == nil && opts.mkstream {
+			if s, err = db.newStream(opts.stream); err != nil {
 				c.WriteError(err.Error())
 				return
 			}
@@ -384,7 +429,7 @@ func (m *Miniredis) cmdXgroupCreate(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		if err := s.createGroup(group, id); err != nil {
+		if err := s.createGroup(opts.group, opts.id); err != nil {
 			c.WriteError(err.Error())
 			return
 		}
@@ -424,6 +469,62 @@ func (m *Miniredis) cmdXgroupDestroy(c *server.Peer, cmd string, args []string)
 	})
 }
 
+// XGROUP SETID
+func (m *Miniredis) cmdXgroupSetid(c *server.Peer, cmd string, args []string) {
+	if len(args) != 3 && len(args) != 5 {
+		setDirty(c)
+		c.WriteError(errWrongNumber("SETID"))
+		return
+	}
+	key, groupName, id := args[0], args[1], args[2]
+	if len(args) == 5 {
+		// we don't track entries-read, but we do validate it
+		if strings.ToUpper(args[3]) != "ENTRIESREAD" {
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+		n, err := strconv.Atoi(args[4])
+		if err != nil {
+			setDirty(c)
+			c.WriteError(msgInvalidInt)
+			return
+		}
+		if n < -1 {
+			setDirty(c)
+			c.WriteError("ERR value for ENTRIESREAD must be positive or -1")
+			return
+		}
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		s, err := db.stream(key)
+		if err != nil {
+			c.WriteError(err.Error())
+			return
+		}
+		if s == nil {
+			c.WriteError(msgXgroupKeyNotFound)
+			return
+		}
+
+		g, ok := s.groups[groupName]
+		if !ok {
+			err := fmt.Sprintf("NOGROUP No such consumer group '%s' for key name '%s'", groupName, key)
+			c.WriteError(err)
+			return
+		}
+
+		if err := g.setLastID(id); err != nil {
+			c.WriteError(msgInvalidStreamID)
+			return
+		}
+		c.WriteOK()
+	})
+}
+
 // XGROUP CREATECONSUMER
 func (m *Miniredis) cmdXgroupCreateconsumer(c *server.Peer, cmd string, args []string) {
 	if len(args) != 3 {
@@ -799,6 +900,7 @@ parsing:
 	blocking(
 		m,
 		c,
+		cmd,
 		opts.blockTimeout,
 		func(c *server.Peer, ctx *connCtx) bool {
 			if ctx.nested {
@@ -1039,6 +1141,7 @@ parsing:
 	blocking(
 		m,
 		c,
+		cmd,
 		opts.blockTimeout,
 		func(c *server.Peer, ctx *connCtx) bool {
 			if ctx.nested {
@@ -1274,7 +1377,7 @@ func writeXpending(
 	consumer *string,
 ) {
 	if len(g.pending) == 0 || count < 0 {
-		c.WriteLen(-1)
+		c.WriteLen(0)
 		return
 	}
 
@@ -1313,10 +1416,6 @@ func writeXpending(
 			})
 		}
 	}
-	if len(res) == 0 {
-		c.WriteLen(-1)
-		return
-	}
 	c.WriteLen(len(res))
 	for _, e := range res {
 		c.WriteLen(4)
@@ -1647,13 +1746,21 @@ func (m *Miniredis) cmdXclaim(c *server.Peer, cmd string, args []string) {
 			arg == "JUSTID" {
 			break
 		}
-		opts.ids = append(opts.ids, arg)
+		opts.ids = append(opts.ids, args[0])
 		args = args[1:]
 	}
 
 	for len(args) > 0 {
 		arg := strings.ToUpper(args[0])
 		switch arg {
+		case "IDLE", "TIME", "RETRYCOUNT":
+			if len(args) < 2 {
+				setDirty(c)
+				c.WriteError(fmt.Sprintf("ERR Unrecognized XCLAIM option '%s'", args[0]))
+				return
+			}
+		}
+		switch arg {
 		case "IDLE":
 			idleMs, err := strconv.ParseInt(args[1], 10, 64)
 			if err != nil {
@@ -1710,7 +1817,7 @@ func (m *Miniredis) cmdXclaim(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		claimedEntryIDs := m.xclaim(g, opts.consumerName, opts.minIdleTime, opts.newLastDelivery, opts.ids, opts.retryCount, opts.force)
+		claimedEntryIDs := m.xclaim(g, opts.consumerName, opts.minIdleTime, opts.newLastDelivery, opts.ids, opts.retryCount, opts.force, opts.justId)
 		writeXclaim(c, g.stream, claimedEntryIDs, opts.justId)
 	})
 }
@@ -1723,6 +1830,7 @@ func (m *Miniredis) xclaim(
 	ids []string,
 	retryCount *int,
 	force bool,
+	justId bool,
 ) (claimedEntryIDs []string) {
 	for _, id := range ids {
 		pelPos, pelEntry := group.searchPending(id)
@@ -1731,6 +1839,10 @@ func (m *Miniredis) xclaim(
 			if !force {
 				continue
 			}
+			// FORCE only creates PEL entries for IDs which are in the stream
+			if _, e := group.stream.get(id); e == nil {
+				continue
+			}
 
 			if pelPos < len(group.pending) {
 				group.pending = append(group.pending[:pelPos+1], group.pending[pelPos:]...)
@@ -1746,13 +1858,17 @@ func (m *Miniredis) xclaim(
 			}
 			group.setLastSuccess(consumerName, m.effectiveNow())
 		} else {
+			if minIdleTime > 0 && m.effectiveNow().Sub(pelEntry.lastDelivery) < minIdleTime {
+				continue
+			}
 			group.consumers[pelEntry.consumer].numPendingEntries--
 			pelEntry.consumer = consumerName
 		}
 
+		// JUSTID doesn't count as a delivery attempt
 		if retryCount != nil {
 			pelEntry.deliveryCount = *retryCount
-		} else {
+		} else if !justId {
 			pelEntry.deliveryCount++
 		}
 		pelEntry.lastDelivery = newLastDelivery
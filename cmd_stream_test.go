@@ -696,8 +696,171 @@ func TestStreamGroup(t *testing.T) {
 			proto.Error("ERR unknown subcommand 'foo'. Try XGROUP HELP."),
 		)
 		mustDo(t, c,
-			"XGROUP", "SETID",
-			proto.Error("ERR 'XGROUP setid' not supported"),
+			"XGROUP", "SETID", "s",
+			proto.Error("ERR wrong number of arguments for 'setid' command"),
+		)
+	})
+
+	t.Run("CREATE", func(t *testing.T) {
+		mustDo(t, c,
+			"XGROUP", "CREATE", "nosuch", "processing", "$",
+			proto.Error(msgXgroupKeyNotFound),
+		)
+		mustDo(t, c,
+			"XADD", "created", "0-1", "name", "Mercury",
+			proto.String("0-1"),
+		)
+		mustOK(t, c, "XGROUP", "CREATE", "created", "processing", "0", "ENTRIESREAD", "0")
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "processing", "$",
+			proto.Error("BUSYGROUP Consumer Group name already exists"),
+		)
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "processing", "$", "MKSTREAM",
+			proto.Error("BUSYGROUP Consumer Group name already exists"),
+		)
+		mustOK(t, c, "XGROUP", "CREATE", "created", "other", "0-1", "MKSTREAM", "ENTRIESREAD", "1")
+		mustDo(t, c,
+			"XREADGROUP", "GROUP", "processing", "alice", "STREAMS", "created", ">",
+			proto.Array(
+				proto.Array(
+					proto.String("created"),
+					proto.Array(
+						proto.Array(proto.String("0-1"), proto.Strings("name", "Mercury")),
+					),
+				),
+			),
+		)
+		mustNilList(t, c,
+			"XREADGROUP", "GROUP", "other", "alice", "STREAMS", "created", ">",
+		)
+
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "new", "foo",
+			proto.Error(msgInvalidStreamID),
+		)
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "new", "$", "ENTRIESREAD", "foo",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "new", "$", "ENTRIESREAD", "-2",
+			proto.Error("ERR value for ENTRIESREAD must be positive or -1"),
+		)
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "new", "$", "ENTRIESREAD",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"XGROUP", "CREATE", "created", "new", "$", "FOO",
+			proto.Error(msgSyntaxError),
+		)
+	})
+
+	t.Run("MKSTREAM", func(t *testing.T) {
+		mustDo(t, c,
+			"XGROUP", "CREATE", "new", "processing", "0", "mkstream",
+			proto.Inline("OK"),
+		)
+		must0(t, c, "XLEN", "new")
+		mustDo(t, c, "TYPE", "new", proto.Inline("stream"))
+	})
+
+	t.Run("consumers", func(t *testing.T) {
+		mustOK(t, c, "XGROUP", "CREATE", "planets", "processing", "$", "MKSTREAM")
+		mustDo(t, c,
+			"XGROUP", "CREATECONSUMER", "planets", "processing", "alice",
+			proto.Int(1),
+		)
+		mustDo(t, c,
+			"XGROUP", "CREATECONSUMER", "planets", "processing", "alice",
+			proto.Int(0),
+		)
+		mustDo(t, c,
+			"XADD", "planets", "0-1", "name", "Mercury",
+			proto.String("0-1"),
+		)
+		mustDo(t, c,
+			"XADD", "planets", "0-2", "name", "Venus",
+			proto.String("0-2"),
+		)
+		mustDo(t, c,
+			"XREADGROUP", "GROUP", "processing", "alice", "STREAMS", "planets", ">",
+			proto.Array(
+				proto.Array(
+					proto.String("planets"),
+					proto.Array(
+						proto.Array(proto.String("0-1"), proto.Strings("name", "Mercury")),
+						proto.Array(proto.String("0-2"), proto.Strings("name", "Venus")),
+					),
+				),
+			),
+		)
+		mustDo(t, c,
+			"XGROUP", "DELCONSUMER", "planets", "processing", "alice",
+			proto.Int(2),
+		)
+		mustDo(t, c,
+			"XPENDING", "planets", "processing",
+			proto.Array(proto.Int(0), proto.Nil, proto.Nil, proto.NilList),
+		)
+		mustDo(t, c,
+			"XINFO", "CONSUMERS", "planets", "processing",
+			proto.Array(),
+		)
+	})
+
+	t.Run("SETID", func(t *testing.T) {
+		mustOK(t, c, "XGROUP", "SETID", "planets", "processing", "0")
+		mustDo(t, c,
+			"XREADGROUP", "GROUP", "processing", "bob", "COUNT", "1", "STREAMS", "planets", ">",
+			proto.Array(
+				proto.Array(
+					proto.String("planets"),
+					proto.Array(
+						proto.Array(proto.String("0-1"), proto.Strings("name", "Mercury")),
+					),
+				),
+			),
+		)
+
+		mustOK(t, c, "XGROUP", "SETID", "planets", "processing", "$", "ENTRIESREAD", "2")
+		mustNilList(t, c,
+			"XREADGROUP", "GROUP", "processing", "bob", "STREAMS", "planets", ">",
+		)
+
+		mustOK(t, c, "XGROUP", "SETID", "planets", "processing", "0-1")
+		mustDo(t, c,
+			"XREADGROUP", "GROUP", "processing", "bob", "STREAMS", "planets", ">",
+			proto.Array(
+				proto.Array(
+					proto.String("planets"),
+					proto.Array(
+						proto.Array(proto.String("0-2"), proto.Strings("name", "Venus")),
+					),
+				),
+			),
+		)
+
+		mustDo(t, c,
+			"XGROUP", "SETID", "nosuch", "processing", "0",
+			proto.Error(msgXgroupKeyNotFound),
+		)
+		mustDo(t, c,
+			"XGROUP", "SETID", "planets", "nosuch", "0",
+			proto.Error("NOGROUP No such consumer group 'nosuch' for key name 'planets'"),
+		)
+		mustDo(t, c,
+			"XGROUP", "SETID", "planets", "processing", "foo",
+			proto.Error(msgInvalidStreamID),
+		)
+		mustDo(t, c,
+			"XGROUP", "SETID", "planets", "processing", "0", "ENTRIESREAD", "foo",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"XGROUP", "SETID", "planets", "processing", "0", "FOO", "1",
+			proto.Error(msgSyntaxError),
 		)
 	})
 }
@@ -966,7 +1129,7 @@ func TestStreamXpending(t *testing.T) {
 		)
 		mustDo(t, c,
 			"XPENDING", "planets", "processing", "-", "+", "-99",
-			proto.NilList,
+			proto.Array(),
 		)
 
 		// Increase delivery count
@@ -992,11 +1155,11 @@ func TestStreamXpending(t *testing.T) {
 
 		mustDo(t, c,
 			"XPENDING", "planets", "processing", "IDLE", "5000", "-", "+", "999",
-			proto.NilList,
+			proto.Array(),
 		)
 		mustDo(t, c,
 			"XPENDING", "planets", "processing", "-", "+", "999", "bob",
-			proto.NilList,
+			proto.Array(),
 		)
 		mustDo(t, c,
 			"XPENDING", "planets", "processing", "IDLE", "4000", "-", "+", "999", "alice",
@@ -1016,7 +1179,7 @@ func TestStreamXpending(t *testing.T) {
 		)
 		mustDo(t, c,
 			"XPENDING", "planets", "processing", "-", "+", "999",
-			proto.NilList,
+			proto.Array(),
 		)
 	})
 
@@ -1429,7 +1592,7 @@ func TestStreamClaim(t *testing.T) {
 	)
 	mustDo(t, c,
 		"XPENDING", "planets", "processing", "-", "+", "999",
-		proto.NilList,
+		proto.Array(),
 	)
 
 	mustDo(t, c,
@@ -1688,6 +1851,118 @@ func TestStreamClaim(t *testing.T) {
 	)
 	mustDo(t, c,
 		"XPENDING", "planets", "processing", "-", "+", "999",
-		proto.NilList,
+		proto.Array(),
+	)
+}
+
+func TestStreamClaimOptions(t *testing.T) {
+	s, c := runWithClient(t)
+
+	now := time.Now()
+	s.SetTime(now)
+
+	mustOK(t, c,
+		"XGROUP", "CREATE", "planets", "processing", "$", "MKSTREAM",
+	)
+	mustDo(t, c,
+		"XADD", "planets", "0-1", "name", "Mercury",
+		proto.String("0-1"),
+	)
+	mustDo(t, c,
+		"XADD", "planets", "0-2", "name", "Venus",
+		proto.String("0-2"),
 	)
+
+	t.Run("FORCE", func(t *testing.T) {
+		// not pending, but in the stream
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "0", "0-1", "FORCE", "JUSTID",
+			proto.Strings("0-1"),
+		)
+		mustDo(t, c,
+			"XPENDING", "planets", "processing", "-", "+", "999",
+			proto.Array(
+				proto.Array(proto.String("0-1"), proto.String("alice"), proto.Int(0), proto.Int(1)),
+			),
+		)
+
+		// not in the stream at all
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "0", "0-99", "FORCE",
+			proto.Array(),
+		)
+		mustDo(t, c,
+			"XPENDING", "planets", "processing",
+			proto.Array(
+				proto.Int(1),
+				proto.String("0-1"),
+				proto.String("0-1"),
+				proto.Array(proto.Array(proto.String("alice"), proto.String("1"))),
+			),
+		)
+	})
+
+	t.Run("JUSTID", func(t *testing.T) {
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "bob", "0", "0-1", "JUSTID",
+			proto.Strings("0-1"),
+		)
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "bob", "0", "0-1", "justid",
+			proto.Strings("0-1"),
+		)
+		mustDo(t, c,
+			"XPENDING", "planets", "processing", "-", "+", "999",
+			proto.Array(
+				proto.Array(proto.String("0-1"), proto.String("bob"), proto.Int(0), proto.Int(1)),
+			),
+		)
+
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "bob", "0", "0-1",
+			proto.Array(
+				proto.Array(proto.String("0-1"), proto.Strings("name", "Mercury")),
+			),
+		)
+		mustDo(t, c,
+			"XPENDING", "planets", "processing", "-", "+", "999",
+			proto.Array(
+				proto.Array(proto.String("0-1"), proto.String("bob"), proto.Int(0), proto.Int(2)),
+			),
+		)
+	})
+
+	t.Run("min-idle-time", func(t *testing.T) {
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "5000", "0-1", "JUSTID",
+			proto.Array(),
+		)
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "5000", "0-1", "IDLE", "10000", "JUSTID",
+			proto.Array(),
+		)
+
+		s.SetTime(now.Add(6 * time.Second))
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "5000", "0-1", "IDLE", "10000", "JUSTID",
+			proto.Strings("0-1"),
+		)
+		mustDo(t, c,
+			"XPENDING", "planets", "processing", "-", "+", "999",
+			proto.Array(
+				proto.Array(proto.String("0-1"), proto.String("alice"), proto.Int(10000), proto.Int(2)),
+			),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "0", "0-1", "IDLE",
+			proto.Error("ERR Unrecognized XCLAIM option 'IDLE'"),
+		)
+		mustDo(t, c,
+			"XCLAIM", "planets", "processing", "alice", "0", "0-1", "RETRYCOUNT",
+			proto.Error("ERR Unrecognized XCLAIM option 'RETRYCOUNT'"),
+		)
+	})
 }
@@ -14,6 +14,8 @@ import (
 // commandsString handles all string value operations.
 func commandsString(m *Miniredis) {
 	m.srv.Register("APPEND", m.cmdAppend)
+	m.srv.Register("BITFIELD", m.makeCmdBitfield(false))
+	m.srv.Register("BITFIELD_RO", m.makeCmdBitfield(true))
 	m.srv.Register("BITCOUNT", m.cmdBitcount)
 	m.srv.Register("BITOP", m.cmdBitop)
 	m.srv.Register("BITPOS", m.cmdBitpos)
@@ -28,6 +30,7 @@ func commandsString(m *Miniredis) {
 	m.srv.Register("INCRBYFLOAT", m.cmdIncrbyfloat)
 	m.srv.Register("INCRBY", m.cmdIncrby)
 	m.srv.Register("INCR", m.cmdIncr)
+	m.srv.Register("LCS", m.cmdLcs)
 	m.srv.Register("MGET", m.cmdMget)
 	m.srv.Register("MSET", m.cmdMset)
 	m.srv.Register("MSETNX", m.cmdMsetnx)
@@ -86,14 +89,12 @@ func (m *Miniredis) cmdSet(c *server.Peer, cmd string, args []string) {
 			fallthrough
 		case "EX", "EXAT":
 			if len(args) < 2 {
-				setDirty(c)
-				c.WriteError(msgInvalidInt)
+				syntaxError(c)
 				return
 			}
 			if opts.ttlSet {
 				// multiple ex/exat/px/pxat options set
-				setDirty(c)
-				c.WriteError(msgSyntaxError)
+				syntaxError(c)
 				return
 			}
 			expire, err := strconv.Atoi(args[1])
@@ -122,11 +123,14 @@ func (m *Miniredis) cmdSet(c *server.Peer, cmd string, args []string) {
 			args = args[1:]
 			continue
 		default:
-			setDirty(c)
-			c.WriteError(msgSyntaxError)
+			syntaxError(c)
 			return
 		}
 	}
+	if (opts.nx && opts.xx) || (opts.keepttl && opts.ttlSet) {
+		syntaxError(c)
+		return
+	}
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
@@ -168,14 +172,25 @@ func (m *Miniredis) cmdSet(c *server.Peer, cmd string, args []string) {
 
 		old, existed := db.stringKeys[opts.key]
 		if !readonly {
-			db.del(opts.key, true) // be sure to remove existing values of other type keys.
 			// a vanilla SET clears the expire
 			if opts.ttl >= 0 { // EXAT/PXAT can expire right away
+				db.unset(opts.key, true) // be sure to remove existing values of other type keys.
 				db.stringSet(opts.key, opts.value)
+			} else {
+				db.del(opts.key, true)
 			}
 			if opts.ttl != 0 {
 				db.ttl[opts.key] = opts.ttl
 			}
+			switch {
+			case opts.ttl < 0:
+				db.notify(notifyGeneric, "del", opts.key)
+			case opts.ttl > 0 && !opts.keepttl:
+				db.notify(notifyString, "set", opts.key)
+				db.notify(notifyGeneric, "expire", opts.key)
+			default:
+				db.notify(notifyString, "set", opts.key)
+			}
 		}
 		if opts.get {
 			if !existed {
@@ -220,9 +235,11 @@ func (m *Miniredis) cmdSetex(c *server.Peer, cmd string, args []string) {
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
 
-		db.del(key, true) // Clear any existing keys.
+		db.unset(key, true) // Clear any existing keys.
 		db.stringSet(key, value)
 		db.ttl[key] = time.Duration(ttl) * time.Second
+		db.notify(notifyString, "set", key)
+		db.notify(notifyGeneric, "expire", key)
 		c.WriteOK()
 	})
 }
@@ -261,9 +278,11 @@ func (m *Miniredis) cmdPsetex(c *server.Peer, cmd string, args []string) {
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
 
-		db.del(opts.key, true) // Clear any existing keys.
+		db.unset(opts.key, true) // Clear any existing keys.
 		db.stringSet(opts.key, opts.value)
 		db.ttl[opts.key] = time.Duration(opts.ttl) * time.Millisecond
+		db.notify(notifyString, "set", opts.key)
+		db.notify(notifyGeneric, "expire", opts.key)
 		c.WriteOK()
 	})
 }
@@ -293,6 +312,7 @@ func (m *Miniredis) cmdSetnx(c *server.Peer, cmd string, args []string) {
 		}
 
 		db.stringSet(key, value)
+		db.notify(notifyString, "set", key)
 		c.WriteInt(1)
 	})
 }
@@ -325,8 +345,9 @@ func (m *Miniredis) cmdMset(c *server.Peer, cmd string, args []string) {
 			key, value := args[0], args[1]
 			args = args[2:]
 
-			db.del(key, true) // clear TTL
+			db.unset(key, true) // clear TTL
 			db.stringSet(key, value)
+			db.notify(notifyString, "set", key)
 		}
 		c.WriteOK()
 	})
@@ -374,6 +395,7 @@ func (m *Miniredis) cmdMsetnx(c *server.Peer, cmd string, args []string) {
 			for k, v := range keys {
 				// Nothing to delete. That's the whole point.
 				db.stringSet(k, v)
+				db.notify(notifyString, "set", k)
 			}
 		}
 		c.WriteInt(res)
@@ -403,12 +425,15 @@ func (m *Miniredis) cmdGet(c *server.Peer, cmd string, args []string) {
 			c.WriteNull()
 			return
 		}
-		if db.t(key) != "string" {
+		switch db.t(key) {
+		case "string":
+			c.WriteBulk(db.stringGet(key))
+		case "hll":
+			// not the same bytes Redis uses
+			c.WriteBulk(string(db.hllKeys[key].Bytes()))
+		default:
 			c.WriteError(msgWrongType)
-			return
 		}
-
-		c.WriteBulk(db.stringGet(key))
 	})
 }
 
@@ -438,8 +463,7 @@ func (m *Miniredis) cmdGetex(c *server.Peer, cmd string, args []string) {
 		switch arg := strings.ToUpper(args[0]); arg {
 		case "PERSIST":
 			if len(args) > 1 {
-				setDirty(c)
-				c.WriteError(msgSyntaxError)
+				syntaxError(c)
 				return
 			}
 			opts.persist = true
@@ -448,8 +472,7 @@ func (m *Miniredis) cmdGetex(c *server.Peer, cmd string, args []string) {
 			fallthrough
 		case "EX", "EXAT":
 			if len(args) != 2 {
-				setDirty(c)
-				c.WriteError(msgSyntaxError)
+				syntaxError(c)
 				return
 			}
 			expire, err := strconv.Atoi(args[1])
@@ -460,7 +483,7 @@ func (m *Miniredis) cmdGetex(c *server.Peer, cmd string, args []string) {
 			}
 			if expire <= 0 {
 				setDirty(c)
-				c.WriteError(msgInvalidSETime)
+				c.WriteError(msgInvalidGETEXTime)
 				return
 			}
 
@@ -470,8 +493,7 @@ func (m *Miniredis) cmdGetex(c *server.Peer, cmd string, args []string) {
 				opts.ttl = time.Duration(expire) * timeUnit
 			}
 		default:
-			setDirty(c)
-			c.WriteError(msgSyntaxError)
+			syntaxError(c)
 			return
 		}
 	}
@@ -483,19 +505,31 @@ func (m *Miniredis) cmdGetex(c *server.Peer, cmd string, args []string) {
 			c.WriteNull()
 			return
 		}
+		if db.t(opts.key) != "string" {
+			c.WriteError(msgWrongType)
+			return
+		}
+
+		v := db.stringGet(opts.key)
 		switch {
 		case opts.persist:
-			delete(db.ttl, opts.key)
+			if _, ok := db.ttl[opts.key]; ok {
+				delete(db.ttl, opts.key)
+				db.incr(opts.key)
+				db.notify(notifyGeneric, "persist", opts.key)
+			}
 		case opts.ttl != 0:
 			db.ttl[opts.key] = opts.ttl
+			db.incr(opts.key)
+			// EXAT/PXAT can expire right away
+			if db.checkTTL(opts.key) {
+				db.notify(notifyGeneric, "del", opts.key)
+			} else {
+				db.notify(notifyGeneric, "expire", opts.key)
+			}
 		}
 
-		if db.t(opts.key) != "string" {
-			c.WriteError(msgWrongType)
-			return
-		}
-
-		c.WriteBulk(db.stringGet(opts.key))
+		c.WriteBulk(v)
 	})
 }
 
@@ -527,6 +561,7 @@ func (m *Miniredis) cmdGetset(c *server.Peer, cmd string, args []string) {
 		db.stringSet(key, value)
 		// a GETSET clears the ttl
 		delete(db.ttl, key)
+		db.notify(notifyString, "set", key)
 
 		if !ok {
 			c.WriteNull()
@@ -560,13 +595,19 @@ func (m *Miniredis) cmdGetdel(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
-		if db.t(key) != "string" {
+		var v string
+		switch db.t(key) {
+		case "string":
+			v = db.stringGet(key)
+		case "hll":
+			// not the same bytes Redis uses
+			v = string(db.hllKeys[key].Bytes())
+		default:
 			c.WriteError(msgWrongType)
 			return
 		}
-
-		v := db.stringGet(key)
 		db.del(key, true)
+		db.notify(notifyGeneric, "del", key)
 		c.WriteBulk(v)
 	})
 }
@@ -632,6 +673,7 @@ func (m *Miniredis) cmdIncr(c *server.Peer, cmd string, args []string) {
 			c.WriteError(err.Error())
 			return
 		}
+		db.notify(notifyString, "incrby", key)
 		// Don't touch TTL
 		c.WriteInt(v)
 	})
@@ -673,6 +715,7 @@ func (m *Miniredis) cmdIncrby(c *server.Peer, cmd string, args []string) {
 			c.WriteError(err.Error())
 			return
 		}
+		db.notify(notifyString, "incrby", opts.key)
 		// Don't touch TTL
 		c.WriteInt(v)
 	})
@@ -713,6 +756,7 @@ func (m *Miniredis) cmdIncrbyfloat(c *server.Peer, cmd string, args []string) {
 			c.WriteError(err.Error())
 			return
 		}
+		db.notify(notifyString, "incrbyfloat", key)
 		// Don't touch TTL
 		c.WriteBulk(formatBig(v))
 	})
@@ -745,6 +789,7 @@ func (m *Miniredis) cmdDecr(c *server.Peer, cmd string, args []string) {
 			c.WriteError(err.Error())
 			return
 		}
+		db.notify(notifyString, "incrby", key)
 		// Don't touch TTL
 		c.WriteInt(v)
 	})
@@ -786,6 +831,7 @@ func (m *Miniredis) cmdDecrby(c *server.Peer, cmd string, args []string) {
 			c.WriteError(err.Error())
 			return
 		}
+		db.notify(notifyString, "incrby", opts.key)
 		// Don't touch TTL
 		c.WriteInt(v)
 	})
@@ -843,8 +889,14 @@ func (m *Miniredis) cmdAppend(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
+		if len(db.stringKeys[key])+len(value) > m.configInt("proto-max-bulk-len") {
+			c.WriteError(msgStringTooLong)
+			return
+		}
+
 		newValue := db.stringKeys[key] + value
 		db.stringSet(key, newValue)
+		db.notify(notifyString, "append", key)
 
 		c.WriteInt(len(newValue))
 	})
@@ -928,6 +980,17 @@ func (m *Miniredis) cmdSetrange(c *server.Peer, cmd string, args []string) {
 			return
 		}
 
+		if len(opts.subst) == 0 {
+			// nothing to write, and a missing key isn't created
+			c.WriteInt(len(db.stringKeys[opts.key]))
+			return
+		}
+
+		if opts.pos+len(opts.subst) > m.configInt("proto-max-bulk-len") {
+			c.WriteError(msgStringTooLong)
+			return
+		}
+
 		v := []byte(db.stringKeys[opts.key])
 		end := opts.pos + len(opts.subst)
 		if len(v) < end {
@@ -937,6 +1000,7 @@ func (m *Miniredis) cmdSetrange(c *server.Peer, cmd string, args []string) {
 		}
 		copy(v[opts.pos:end], opts.subst)
 		db.stringSet(opts.key, string(v))
+		db.notify(notifyString, "setrange", opts.key)
 		c.WriteInt(len(v))
 	})
 }
@@ -959,6 +1023,7 @@ func (m *Miniredis) cmdBitcount(c *server.Peer, cmd string, args []string) {
 		useRange bool
 		start    int
 		end      int
+		bit      bool // BIT instead of BYTE offsets
 		key      string
 	}
 	opts.key, args = args[0], args[1:]
@@ -971,6 +1036,15 @@ func (m *Miniredis) cmdBitcount(c *server.Peer, cmd string, args []string) {
 			return
 		}
 		args = args[2:]
+		if len(args) > 0 {
+			switch strings.ToUpper(args[0]) {
+			case "BYTE":
+				args = args[1:]
+			case "BIT":
+				opts.bit = true
+				args = args[1:]
+			}
+		}
 	}
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
@@ -992,6 +1066,11 @@ func (m *Miniredis) cmdBitcount(c *server.Peer, cmd string, args []string) {
 		}
 
 		v := db.stringKeys[opts.key]
+		if opts.useRange && opts.bit {
+			start, end := redisRange(len(v)*8, opts.start, opts.end, true)
+			c.WriteInt(countBitRange([]byte(v), start, end))
+			return
+		}
 		if opts.useRange {
 			v = withRange(v, opts.start, opts.end)
 		}
@@ -1048,11 +1127,12 @@ func (m *Miniredis) cmdBitop(c *server.Peer, cmd string, args []string) {
 				}[opts.op]
 				res = sliceBinOp(cb, res, []byte(v))
 			}
-			db.del(opts.target, false) // Keep TTL
 			if len(res) == 0 {
 				db.del(opts.target, true)
+				db.notify(notifyGeneric, "del", opts.target)
 			} else {
 				db.stringSet(opts.target, string(res))
+				db.notify(notifyString, "set", opts.target)
 			}
 			c.WriteInt(len(res))
 		case "NOT":
@@ -1070,11 +1150,12 @@ func (m *Miniredis) cmdBitop(c *server.Peer, cmd string, args []string) {
 			for i := range value {
 				value[i] = ^value[i]
 			}
-			db.del(opts.target, false) // Keep TTL
 			if len(value) == 0 {
 				db.del(opts.target, true)
+				db.notify(notifyGeneric, "del", opts.target)
 			} else {
 				db.stringSet(opts.target, string(value))
+				db.notify(notifyString, "set", opts.target)
 			}
 			c.WriteInt(len(value))
 		default:
@@ -1085,7 +1166,7 @@ func (m *Miniredis) cmdBitop(c *server.Peer, cmd string, args []string) {
 
 // BITPOS
 func (m *Miniredis) cmdBitpos(c *server.Peer, cmd string, args []string) {
-	if len(args) < 2 || len(args) > 4 {
+	if len(args) < 2 || len(args) > 5 {
 		setDirty(c)
 		c.WriteError(errWrongNumber(cmd))
 		return
@@ -1103,6 +1184,7 @@ func (m *Miniredis) cmdBitpos(c *server.Peer, cmd string, args []string) {
 		Start   int
 		End     int
 		WithEnd bool
+		Unit    string // "BYTE" or "BIT"
 	}
 
 	opts.Key = args[0]
@@ -1120,6 +1202,15 @@ func (m *Miniredis) cmdBitpos(c *server.Peer, cmd string, args []string) {
 		}
 		opts.WithEnd = true
 	}
+	opts.Unit = "BYTE"
+	if len(args) > 4 {
+		opts.Unit = strings.ToUpper(args[4])
+		if opts.Unit != "BYTE" && opts.Unit != "BIT" {
+			setDirty(c)
+			c.WriteError(msgSyntaxError)
+			return
+		}
+	}
 
 	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
 		db := m.db(ctx.selectedDB)
@@ -1137,6 +1228,10 @@ func (m *Miniredis) cmdBitpos(c *server.Peer, cmd string, args []string) {
 			return
 		}
 		value := db.stringKeys[opts.Key]
+		if opts.Unit == "BIT" {
+			c.WriteInt(bitPosRange([]byte(value), opts.Bit == 1, opts.Start, opts.End))
+			return
+		}
 		start := opts.Start
 		end := opts.End
 		if start < 0 {
@@ -1203,12 +1298,12 @@ func (m *Miniredis) cmdGetbit(c *server.Peer, cmd string, args []string) {
 		bit int
 	}
 	opts.key = args[0]
-	if ok := optIntErr(c, args[1], &opts.bit, "ERR bit offset is not an integer or out of range"); !ok {
+	if ok := optIntErr(c, args[1], &opts.bit, msgBitOffset); !ok {
 		return
 	}
 	if opts.bit < 0 {
 		setDirty(c)
-		c.WriteError("ERR bit offset is not an integer or out of range")
+		c.WriteError(msgBitOffset)
 		return
 	}
 
@@ -1256,12 +1351,12 @@ func (m *Miniredis) cmdSetbit(c *server.Peer, cmd string, args []string) {
 		newBit int
 	}
 	opts.key = args[0]
-	if ok := optIntErr(c, args[1], &opts.bit, "ERR bit offset is not an integer or out of range"); !ok {
+	if ok := optIntErr(c, args[1], &opts.bit, msgBitOffset); !ok {
 		return
 	}
 	if opts.bit < 0 {
 		setDirty(c)
-		c.WriteError("ERR bit offset is not an integer or out of range")
+		c.WriteError(msgBitOffset)
 		return
 	}
 	if ok := optIntErr(c, args[2], &opts.newBit, "ERR bit is not an integer or out of range"); !ok {
@@ -1280,6 +1375,10 @@ func (m *Miniredis) cmdSetbit(c *server.Peer, cmd string, args []string) {
 			c.WriteError(msgWrongType)
 			return
 		}
+		if opts.bit/8 >= m.configInt("proto-max-bulk-len") {
+			c.WriteError(msgBitOffset)
+			return
+		}
 		value := []byte(db.stringKeys[opts.key])
 
 		ourByteNr := opts.bit / 8
@@ -1300,17 +1399,371 @@ func (m *Miniredis) cmdSetbit(c *server.Peer, cmd string, args []string) {
 			value[ourByteNr] |= 1 << uint8(7-ourBitNr)
 		}
 		db.stringSet(opts.key, string(value))
+		db.notify(notifyString, "setbit", opts.key)
 
 		c.WriteInt(old)
 	})
 }
 
+// BITFIELD and BITFIELD_RO
+func (m *Miniredis) makeCmdBitfield(readOnly bool) server.Cmd {
+	return func(c *server.Peer, cmd string, args []string) {
+		if len(args) < 1 {
+			setDirty(c)
+			c.WriteError(errWrongNumber(cmd))
+			return
+		}
+		if !m.handleAuth(c) {
+			return
+		}
+		if m.checkPubsub(c, cmd) {
+			return
+		}
+
+		type bitfieldOp struct {
+			op       string // "get", "set", or "incrby"
+			typ      bitfieldType
+			offset   int
+			value    int64  // for SET and INCRBY
+			overflow string // "wrap", "sat", or "fail"
+		}
+		var (
+			key      = args[0]
+			ops      []bitfieldOp
+			overflow = "wrap"
+			write    = false
+		)
+		args = args[1:]
+		for len(args) > 0 {
+			op := strings.ToLower(args[0])
+			switch op {
+			case "get", "set", "incrby":
+			case "overflow":
+				if len(args) < 2 {
+					setDirty(c)
+					c.WriteError(msgSyntaxError)
+					return
+				}
+				switch o := strings.ToLower(args[1]); o {
+				case "wrap", "sat", "fail":
+					overflow = o
+				default:
+					setDirty(c)
+					c.WriteError(msgBitfieldOverflow)
+					return
+				}
+				args = args[2:]
+				continue
+			default:
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+
+			n := 3 // op, type, offset
+			if op != "get" {
+				n++ // and a value
+			}
+			if len(args) < n {
+				setDirty(c)
+				c.WriteError(msgSyntaxError)
+				return
+			}
+			typ, err := parseBitfieldType(args[1])
+			if err != nil {
+				setDirty(c)
+				c.WriteError(err.Error())
+				return
+			}
+			offset, err := parseBitfieldOffset(args[2], typ)
+			if err != nil {
+				setDirty(c)
+				c.WriteError(err.Error())
+				return
+			}
+			o := bitfieldOp{
+				op:       op,
+				typ:      typ,
+				offset:   offset,
+				overflow: overflow,
+			}
+			if op != "get" {
+				if readOnly {
+					setDirty(c)
+					c.WriteError(msgBitfieldRO)
+					return
+				}
+				v, err := strconv.ParseInt(args[3], 10, 64)
+				if err != nil {
+					setDirty(c)
+					c.WriteError(msgInvalidInt)
+					return
+				}
+				o.value = v
+				write = true
+			}
+			ops = append(ops, o)
+			args = args[n:]
+		}
+
+		withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+			db := m.db(ctx.selectedDB)
+
+			if t, ok := db.keys[key]; ok && t != "string" {
+				c.WriteError(msgWrongType)
+				return
+			}
+			value := []byte(db.stringKeys[key])
+			for _, o := range ops {
+				if o.offset/8 >= m.configInt("proto-max-bulk-len") {
+					c.WriteError(msgBitOffset)
+					return
+				}
+				if o.op == "get" {
+					continue
+				}
+				// Too short. Expand.
+				if l := (o.offset+int(o.typ.bits)-1)/8 + 1; l > len(value) {
+					newValue := make([]byte, l)
+					copy(newValue, value)
+					value = newValue
+				}
+			}
+
+			c.WriteLen(len(ops))
+			for _, o := range ops {
+				old := getBitfield(value, o.offset, o.typ.bits)
+				if o.op == "get" {
+					if o.typ.signed {
+						c.WriteInt(int(signedBitfield(old, o.typ.bits)))
+					} else {
+						c.WriteInt(int(old))
+					}
+					continue
+				}
+
+				var (
+					res      int64
+					field    uint64
+					overflow bool
+				)
+				if o.typ.signed {
+					oldv := signedBitfield(old, o.typ.bits)
+					var newv int64
+					if o.op == "incrby" {
+						newv, overflow = signedBitfieldOverflow(oldv, o.value, o.typ.bits, o.overflow)
+						res = newv
+					} else {
+						newv, overflow = signedBitfieldOverflow(o.value, 0, o.typ.bits, o.overflow)
+						res = oldv
+					}
+					field = uint64(newv)
+				} else {
+					if o.op == "incrby" {
+						field, overflow = unsignedBitfieldOverflow(old, o.value, o.typ.bits, o.overflow)
+						res = int64(field)
+					} else {
+						field, overflow = unsignedBitfieldOverflow(uint64(o.value), 0, o.typ.bits, o.overflow)
+						res = int64(old)
+					}
+				}
+				if overflow && o.overflow == "fail" {
+					c.WriteNull()
+					continue
+				}
+				setBitfield(value, o.offset, o.typ.bits, field)
+				c.WriteInt(int(res))
+			}
+			if write {
+				db.stringSet(key, string(value))
+				db.notify(notifyString, "setbit", key)
+			}
+		})
+	}
+}
+
+// LCS
+func (m *Miniredis) cmdLcs(c *server.Peer, cmd string, args []string) {
+	if len(args) < 2 {
+		setDirty(c)
+		c.WriteError(errWrongNumber(cmd))
+		return
+	}
+	if !m.handleAuth(c) {
+		return
+	}
+	if m.checkPubsub(c, cmd) {
+		return
+	}
+
+	var opts struct {
+		keyA, keyB   string
+		len          bool
+		idx          bool
+		minMatchLen  int
+		withMatchLen bool
+	}
+	opts.keyA, opts.keyB, args = args[0], args[1], args[2:]
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "LEN":
+			opts.len = true
+		case "IDX":
+			opts.idx = true
+		case "WITHMATCHLEN":
+			opts.withMatchLen = true
+		case "MINMATCHLEN":
+			if len(args) < 2 {
+				syntaxError(c)
+				return
+			}
+			if ok := optInt(c, args[1], &opts.minMatchLen); !ok {
+				return
+			}
+			if opts.minMatchLen < 0 {
+				opts.minMatchLen = 0
+			}
+			args = args[1:]
+		default:
+			syntaxError(c)
+			return
+		}
+		args = args[1:]
+	}
+	if opts.len && opts.idx {
+		setDirty(c)
+		c.WriteError(msgLCSLenAndIdx)
+		return
+	}
+
+	withTx(m, c, func(c *server.Peer, ctx *connCtx) {
+		db := m.db(ctx.selectedDB)
+
+		for _, k := range []string{opts.keyA, opts.keyB} {
+			if t, ok := db.keys[k]; ok && t != "string" {
+				c.WriteError(msgLCSWrongType)
+				return
+			}
+		}
+
+		res, matches := lcs(db.stringKeys[opts.keyA], db.stringKeys[opts.keyB])
+		switch {
+		case opts.len:
+			c.WriteInt(len(res))
+		case opts.idx:
+			var found []lcsMatch
+			for _, ma := range matches {
+				if ma.len() >= opts.minMatchLen {
+					found = append(found, ma)
+				}
+			}
+			c.WriteMapLen(2)
+			c.WriteBulk("matches")
+			c.WriteLen(len(found))
+			for _, ma := range found {
+				if opts.withMatchLen {
+					c.WriteLen(3)
+				} else {
+					c.WriteLen(2)
+				}
+				c.WriteLen(2)
+				c.WriteInt(ma.aStart)
+				c.WriteInt(ma.aEnd)
+				c.WriteLen(2)
+				c.WriteInt(ma.bStart)
+				c.WriteInt(ma.bEnd)
+				if opts.withMatchLen {
+					c.WriteInt(ma.len())
+				}
+			}
+			c.WriteBulk("len")
+			c.WriteInt(len(res))
+		default:
+			c.WriteBulk(res)
+		}
+	})
+}
+
 // Redis range. both start and end can be negative.
 func withRange(v string, start, end int) string {
 	s, e := redisRange(len(v), start, end, true /* string getrange symantics */)
 	return v[s:e]
 }
 
+// lcsMatch is a single common range, as returned by LCS IDX. Both ends are
+// inclusive.
+type lcsMatch struct {
+	aStart, aEnd int
+	bStart, bEnd int
+}
+
+func (ma lcsMatch) len() int {
+	return ma.aEnd - ma.aStart + 1
+}
+
+// lcs finds the longest common subsequence of a and b, and the contiguous
+// ranges it's made of. The ranges are ordered from the end of the strings to
+// the start, same as Redis.
+func lcs(a, b string) (string, []lcsMatch) {
+	// table[i][j] is the LCS length of a[:i] and b[:j]
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				table[i][j] = table[i-1][j-1] + 1
+			case table[i-1][j] > table[i][j-1]:
+				table[i][j] = table[i-1][j]
+			default:
+				table[i][j] = table[i][j-1]
+			}
+		}
+	}
+
+	var (
+		idx     = table[len(a)][len(b)]
+		res     = make([]byte, idx)
+		matches []lcsMatch
+		cur     *lcsMatch // range we're building, backwards
+	)
+	for i, j := len(a), len(b); i > 0 && j > 0; {
+		emit := false
+		if a[i-1] == b[j-1] {
+			res[idx-1] = a[i-1]
+			switch {
+			case cur == nil:
+				cur = &lcsMatch{aStart: i - 1, aEnd: i - 1, bStart: j - 1, bEnd: j - 1}
+			case cur.aStart == i && cur.bStart == j:
+				cur.aStart--
+				cur.bStart--
+			default:
+				emit = true
+			}
+			if cur.aStart == 0 || cur.bStart == 0 {
+				emit = true
+			}
+			idx--
+			i--
+			j--
+		} else {
+			if table[i-1][j] > table[i][j-1] {
+				i--
+			} else {
+				j--
+			}
+			emit = cur != nil
+		}
+		if emit {
+			matches = append(matches, *cur)
+			cur = nil
+		}
+	}
+	return string(res), matches
+}
+
 func countBits(v []byte) int {
 	count := 0
 	for _, b := range []byte(v) {
@@ -1322,6 +1775,18 @@ func countBits(v []byte) int {
 	return count
 }
 
+// countBitRange counts the set bits from bit offset start up to, but not
+// including, end.
+func countBitRange(v []byte, start, end int) int {
+	count := 0
+	for i := start; i < end; i++ {
+		if v[i/8]&(0x80>>uint(i%8)) != 0 {
+			count++
+		}
+	}
+	return count
+}
+
 // sliceBinOp applies an operator to all slice elements, with Redis string
 // padding logic.
 func sliceBinOp(f func(a, b byte) byte, a, b []byte) []byte {
@@ -1352,6 +1817,37 @@ func bitPos(s []byte, bit bool) int {
 	return -1
 }
 
+// bitPosRange is BITPOS with BIT offsets. Negative offsets count from the end
+// of the string. The BIT unit always comes with an end offset, so a clear bit
+// is never found in the "padding" after the string.
+func bitPosRange(s []byte, bit bool, start, end int) int {
+	l := len(s) * 8
+	if start < 0 {
+		start += l
+		if start < 0 {
+			start = 0
+		}
+	}
+	if end < 0 {
+		end += l
+		if end < 0 {
+			end = 0
+		}
+	}
+	if end >= l {
+		end = l - 1
+	}
+	if start > end {
+		return -1
+	}
+	for i := start; i <= end; i++ {
+		if (s[i/8]&(0x80>>uint(i%8)) != 0) == bit {
+			return i
+		}
+	}
+	return -1
+}
+
 // toBits changes a byte in 8 bools.
 func toBits(s byte) [8]bool {
 	r := [8]bool{}
@@ -1,7 +1,9 @@
 package miniredis
 
 import (
+	"math"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -134,7 +136,7 @@ func TestSet(t *testing.T) {
 
 		mustDo(t, c,
 			"SET", "one", "two", "EX",
-			proto.Error(msgInvalidInt),
+			proto.Error(msgSyntaxError),
 		)
 
 		mustDo(t, c,
@@ -157,6 +159,26 @@ func TestSet(t *testing.T) {
 		equals(t, time.Second*1337, s.TTL("foo"))
 	})
 
+	t.Run("options", func(t *testing.T) {
+		mustDo(t, c,
+			"SET", "foo", "bar", "FOO",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"SET", "foo", "bar", "NX", "XX",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"SET", "foo", "bar", "KEEPTTL", "EX", "10",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"SET", "foo", "bar", "PX",
+			proto.Error(msgSyntaxError),
+		)
+		s.CheckGet(t, "foo", "baz")
+	})
+
 	t.Run("GET", func(t *testing.T) {
 		mustNil(t, c,
 			"SET", "dino", "bar", "GET",
@@ -219,6 +241,12 @@ func TestMget(t *testing.T) {
 			"MGET", "aap",
 			proto.Array(proto.Nil),
 		)
+
+		s.Lpush("list", "value")
+		mustDo(t, c,
+			"MGET", "kees", "nosuch", "list",
+			proto.Array(proto.String("bok"), proto.Nil, proto.Nil),
+		)
 	}
 }
 
@@ -815,11 +843,57 @@ func TestGetex(t *testing.T) {
 		equals(t, time.Duration(0), s.TTL("foo"))
 	})
 
+	t.Run("past EXAT", func(t *testing.T) {
+		s.SetTime(time.Unix(100, 0))
+		s.Set("past", "bar")
+		mustDo(t, c, "GETEX", "past", "EXAT", "50", proto.String("bar"))
+		equals(t, false, s.Exists("past"))
+	})
+
+	t.Run("FastForward", func(t *testing.T) {
+		s.Set("ff", "bar")
+		mustDo(t, c, "GETEX", "ff", "EX", "10", proto.String("bar"))
+		s.FastForward(5 * time.Second)
+		mustDo(t, c, "GETEX", "ff", proto.String("bar"))
+		equals(t, 5*time.Second, s.TTL("ff"))
+		s.FastForward(5 * time.Second)
+		mustNil(t, c, "GETEX", "ff")
+	})
+
 	t.Run("errors", func(t *testing.T) {
 		mustDo(t, c,
 			"GETEX", "one", "two",
 			proto.Error(msgSyntaxError),
 		)
+		mustDo(t, c,
+			"GETEX", "one", "EX", "10", "PERSIST",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"GETEX", "one", "EX", "10", "PX", "10",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"GETEX", "one", "EX", "0",
+			proto.Error(msgInvalidGETEXTime),
+		)
+		mustDo(t, c,
+			"GETEX", "one", "FOO",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"GETEX", "one", "EX",
+			proto.Error(msgSyntaxError),
+		)
+
+		// wrong type keeps the TTL
+		s.HSet("ahash", "aap", "noot")
+		s.SetTTL("ahash", time.Minute)
+		mustDo(t, c,
+			"GETEX", "ahash", "PERSIST",
+			proto.Error(msgWrongType),
+		)
+		equals(t, time.Minute, s.TTL("ahash"))
 	})
 }
 
@@ -905,6 +979,15 @@ func TestGetdel(t *testing.T) {
 		)
 	}
 
+	// HyperLogLogs are strings
+	{
+		mustDo(t, c, "PFADD", "h", "a", proto.Int(1))
+		res, err := c.Do("GETDEL", "h")
+		ok(t, err)
+		assert(t, strings.HasPrefix(res, "$"), "GETDEL gives a string")
+		must0(t, c, "EXISTS", "h")
+	}
+
 	// Wrong usage
 	{
 		mustDo(t, c,
@@ -986,6 +1069,22 @@ func TestAppend(t *testing.T) {
 		)
 	}
 
+	// proto-max-bulk-len
+	{
+		mustOK(t, c, "CONFIG", "SET", "proto-max-bulk-len", "10")
+		s.Set("long", "12345678")
+		mustDo(t, c,
+			"APPEND", "long", "90",
+			proto.Int(10),
+		)
+		mustDo(t, c,
+			"APPEND", "long", "!",
+			proto.Error(msgStringTooLong),
+		)
+		s.CheckGet(t, "long", "1234567890")
+		mustOK(t, c, "CONFIG", "SET", "proto-max-bulk-len", "536870912")
+	}
+
 	// Wrong usage
 	{
 		mustDo(t, c,
@@ -1089,6 +1188,25 @@ func TestSetrange(t *testing.T) {
 		s.CheckGet(t, "nosuch", "\x00\x00\x00bar")
 	}
 
+	// Empty value
+	{
+		mustDo(t, c,
+			"SETRANGE", "empty", "0", "",
+			proto.Int(0),
+		)
+		mustDo(t, c,
+			"SETRANGE", "empty", "5", "",
+			proto.Int(0),
+		)
+		equals(t, false, s.Exists("empty"))
+
+		mustDo(t, c,
+			"SETRANGE", "nosuch", "10", "",
+			proto.Int(6),
+		)
+		s.CheckGet(t, "nosuch", "\x00\x00\x00bar")
+	}
+
 	// Wrong type of existing key
 	{
 		s.HSet("wrong", "aap", "noot")
@@ -1098,6 +1216,25 @@ func TestSetrange(t *testing.T) {
 		)
 	}
 
+	// proto-max-bulk-len
+	{
+		mustOK(t, c, "CONFIG", "SET", "proto-max-bulk-len", "10")
+		mustDo(t, c,
+			"SETRANGE", "long", "7", "abc",
+			proto.Int(10),
+		)
+		mustDo(t, c,
+			"SETRANGE", "long", "8", "abc",
+			proto.Error(msgStringTooLong),
+		)
+		mustDo(t, c,
+			"SETRANGE", "huge", "536870912", "abc",
+			proto.Error(msgStringTooLong),
+		)
+		equals(t, false, s.Exists("huge"))
+		mustOK(t, c, "CONFIG", "SET", "proto-max-bulk-len", "536870912")
+	}
+
 	// Wrong usage
 	{
 		mustDo(t, c,
@@ -1164,8 +1301,53 @@ func TestBitcount(t *testing.T) {
 		test(0, 0, 3)  // "a"
 		test(0, 3, 13) // "abcd"
 		test(2, -2, 4) // "c"
+
+		mustDo(t, c, "BITCOUNT", "foo", "0", "0", "BYTE", proto.Int(3))
+		mustDo(t, c, "BITCOUNT", "foo", "0", "3", "byte", proto.Int(13))
 	}
 
+	t.Run("BIT", func(t *testing.T) {
+		// a: 0b01100001
+		// b: 0b01100010
+		// c: 0b01100011
+		// d: 0b01100100
+		s.Set("foo", "abcd")
+		test := func(s, e, res int) {
+			t.Helper()
+			mustDo(t, c,
+				"BITCOUNT", "foo", strconv.Itoa(s), strconv.Itoa(e), "BIT",
+				proto.Int(res),
+			)
+		}
+		test(0, 0, 0)     // first bit of "a"
+		test(1, 1, 1)     // second bit of "a"
+		test(0, 7, 3)     // "a"
+		test(0, 31, 13)   // "abcd"
+		test(5, 10, 3)    // "001" of "a", and "011" of "b"
+		test(7, 8, 1)     // last bit of "a", first bit of "b"
+		test(-8, -1, 3)   // "d"
+		test(-3, -1, 1)   // "100" of "d"
+		test(6, 100, 11)  // past the end
+		test(-100, 1, 1)  // before the start
+		test(10, 5, 0)    // start after end
+		test(-1, -100, 0) // end before the start
+		mustDo(t, c,
+			"BITCOUNT", "foo", "0", "0", "bIt",
+			proto.Int(0),
+		)
+		must0(t, c,
+			"BITCOUNT", "nosuch", "0", "10", "BIT",
+		)
+		mustDo(t, c,
+			"BITCOUNT", "foo", "0", "0", "BITS",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"BITCOUNT", "foo", "0", "0", "BIT", "BIT",
+			proto.Error(msgSyntaxError),
+		)
+	})
+
 	// Wrong type of existing key
 	{
 		s.HSet("wrong", "aap", "noot")
@@ -1304,6 +1486,158 @@ func TestBitop(t *testing.T) {
 	}
 }
 
+func TestBitfield(t *testing.T) {
+	s, c := runWithClient(t)
+
+	t.Run("basic", func(t *testing.T) {
+		mustDo(t, c,
+			"BITFIELD", "counters", "GET", "u8", "0", "SET", "i16", "#1", "1000", "INCRBY", "u4", "16", "1", "OVERFLOW", "SAT",
+			proto.Ints(0, 0, 1),
+		)
+		s.CheckGet(t, "counters", "\x00\x00\x13\xe8")
+		mustDo(t, c,
+			"BITFIELD", "counters", "GET", "i16", "#1", "GET", "u4", "16", "GET", "u32", "0", "GET", "u8", "100",
+			proto.Ints(5096, 1, 5096, 0),
+		)
+
+		mustDo(t, c,
+			"BITFIELD", "mykey", "INCRBY", "i5", "100", "1", "GET", "u4", "0",
+			proto.Ints(1, 0),
+		)
+
+		s.Set("str", "\xff")
+		mustDo(t, c,
+			"BITFIELD", "str", "GET", "i8", "0", "GET", "u8", "0", "GET", "i3", "5", "GET", "u3", "5", "GET", "i4", "6",
+			proto.Ints(-1, 255, -1, 7, -4),
+		)
+
+		// no operations
+		mustDo(t, c, "BITFIELD", "str", proto.Ints())
+		mustDo(t, c, "BITFIELD", "nosuch", "GET", "u8", "0", proto.Ints(0))
+		equals(t, false, s.Exists("nosuch"))
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		for _, res := range [][]int{{1, 1}, {2, 2}, {3, 3}, {0, 3}} {
+			mustDo(t, c,
+				"BITFIELD", "ov", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1",
+				proto.Ints(res...),
+			)
+		}
+		mustDo(t, c,
+			"BITFIELD", "ov", "OVERFLOW", "FAIL", "INCRBY", "u2", "102", "1",
+			proto.Array(proto.Nil),
+		)
+		mustDo(t, c,
+			"BITFIELD", "ov", "GET", "u2", "102",
+			proto.Ints(3),
+		)
+
+		mustDo(t, c,
+			"BITFIELD", "sig", "SET", "i8", "0", "127", "INCRBY", "i8", "0", "1", "INCRBY", "i8", "0", "-1",
+			proto.Ints(0, -128, 127),
+		)
+		mustDo(t, c,
+			"BITFIELD", "sig", "OVERFLOW", "SAT", "INCRBY", "i8", "0", "100", "INCRBY", "i8", "0", "-1000",
+			proto.Ints(127, -128),
+		)
+		mustDo(t, c,
+			"BITFIELD", "sig", "OVERFLOW", "fail", "INCRBY", "i8", "0", "-1", "INCRBY", "i8", "0", "1",
+			proto.Array(proto.Nil, proto.Int(-127)),
+		)
+
+		// SET overflows too
+		mustDo(t, c,
+			"BITFIELD", "set", "SET", "u8", "0", "256", "SET", "u8", "0", "-1", "GET", "u8", "0",
+			proto.Ints(0, 0, 255),
+		)
+		mustDo(t, c,
+			"BITFIELD", "set", "OVERFLOW", "SAT", "SET", "u8", "0", "300", "SET", "i8", "0", "-300", "GET", "i8", "0",
+			proto.Ints(255, -1, -128),
+		)
+		mustDo(t, c,
+			"BITFIELD", "set", "OVERFLOW", "FAIL", "SET", "u8", "0", "300", "GET", "i8", "0",
+			proto.Array(proto.Nil, proto.Int(-128)),
+		)
+
+		// 64 bits
+		mustDo(t, c,
+			"BITFIELD", "big", "SET", "i64", "0", "9223372036854775807", "INCRBY", "i64", "0", "1", "GET", "u63", "1",
+			proto.Ints(0, math.MinInt64, 0),
+		)
+		mustDo(t, c,
+			"BITFIELD", "big", "SET", "u63", "1", "-1", "GET", "i64", "0",
+			proto.Ints(0, -1),
+		)
+		mustDo(t, c,
+			"BITFIELD", "big", "SET", "u1", "0", "0", "GET", "i64", "0",
+			proto.Ints(1, math.MaxInt64),
+		)
+	})
+
+	t.Run("BITFIELD_RO", func(t *testing.T) {
+		s.Set("ro", "\x0f")
+		mustDo(t, c,
+			"BITFIELD_RO", "ro", "GET", "u4", "4", "GET", "u4", "#0",
+			proto.Ints(15, 0),
+		)
+		mustDo(t, c,
+			"BITFIELD_RO", "ro", "GET", "u4", "4", "SET", "u4", "0", "1",
+			proto.Error("ERR BITFIELD_RO only supports the GET subcommand"),
+		)
+		mustDo(t, c,
+			"BITFIELD_RO", "ro", "INCRBY", "u4", "0", "1",
+			proto.Error("ERR BITFIELD_RO only supports the GET subcommand"),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mustDo(t, c,
+			"BITFIELD",
+			proto.Error(errWrongNumber("bitfield")),
+		)
+		mustDo(t, c,
+			"BITFIELD", "k", "GET", "u8",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"BITFIELD", "k", "FOO", "u8", "0",
+			proto.Error(msgSyntaxError),
+		)
+		for _, typ := range []string{"u64", "i65", "u0", "x8", "u", "8"} {
+			mustDo(t, c,
+				"BITFIELD", "k", "GET", typ, "0",
+				proto.Error("ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."),
+			)
+		}
+		mustDo(t, c,
+			"BITFIELD", "k", "GET", "u8", "-1",
+			proto.Error(msgBitOffset),
+		)
+		mustDo(t, c,
+			"BITFIELD", "k", "GET", "u8", "#foo",
+			proto.Error(msgBitOffset),
+		)
+		mustDo(t, c,
+			"BITFIELD", "k", "SET", "u8", "0", "foo",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"BITFIELD", "k", "OVERFLOW", "foo",
+			proto.Error("ERR Invalid OVERFLOW type specified"),
+		)
+		mustDo(t, c,
+			"BITFIELD", "k", "OVERFLOW",
+			proto.Error(msgSyntaxError),
+		)
+		s.HSet("hash", "aap", "noot")
+		mustDo(t, c,
+			"BITFIELD", "hash", "GET", "u8", "0",
+			proto.Error(msgWrongType),
+		)
+	})
+}
+
 func TestBitpos(t *testing.T) {
 	s, c := runWithClient(t)
 
@@ -1454,6 +1788,46 @@ func TestBitpos(t *testing.T) {
 		)
 	})
 
+	t.Run("BIT", func(t *testing.T) {
+		s.Set("bits", "\x00\xff\xf0") // 00000000 11111111 11110000
+		test := func(bit string, args []string, res int) {
+			t.Helper()
+			mustDo(t, c,
+				append(append([]string{"BITPOS", "bits", bit}, args...), "BIT", proto.Int(res))...,
+			)
+		}
+		test("1", []string{"0", "-1"}, 8)
+		test("1", []string{"9", "-1"}, 9)
+		test("1", []string{"16", "19"}, 16)
+		test("1", []string{"20", "23"}, -1)
+		test("1", []string{"-4", "-1"}, -1)
+		test("1", []string{"-5", "-1"}, 19)
+		test("1", []string{"2", "7"}, -1)
+		test("0", []string{"3", "5"}, 3)
+		test("0", []string{"8", "19"}, -1) // no padding with an end
+		test("0", []string{"8", "100"}, 20)
+		test("0", []string{"-100", "-100"}, 0)
+		test("1", []string{"10", "5"}, -1)
+
+		// with an end the string is not padded with clear bits
+		s.Set("full", "\xff")
+		mustDo(t, c, "BITPOS", "full", "0", proto.Int(8))
+		mustDo(t, c, "BITPOS", "full", "0", "3", "-1", "BIT", proto.Int(-1))
+		mustDo(t, c, "BITPOS", "full", "0", "3", "-1", "bit", proto.Int(-1))
+		mustDo(t, c, "BITPOS", "full", "1", "3", "-1", "BYTE", proto.Int(-1))
+		mustDo(t, c, "BITPOS", "full", "1", "0", "-1", "BYTE", proto.Int(0))
+
+		mustDo(t, c, "BITPOS", "nosuch", "0", "0", "-1", "BIT", proto.Int(0))
+		mustDo(t, c, "BITPOS", "nosuch", "1", "0", "-1", "BIT", proto.Int(-1))
+		s.Set("empty", "")
+		mustDo(t, c, "BITPOS", "empty", "0", "0", "-1", "BIT", proto.Int(-1))
+
+		mustDo(t, c,
+			"BITPOS", "bits", "1", "0", "-1", "BITS",
+			proto.Error(msgSyntaxError),
+		)
+	})
+
 	t.Run("wrong type", func(t *testing.T) {
 		s.HSet("wrong", "aap", "noot")
 		mustDo(t, c,
@@ -1576,6 +1950,20 @@ func TestSetbit(t *testing.T) {
 		)
 	}
 
+	// proto-max-bulk-len
+	{
+		mustOK(t, c, "CONFIG", "SET", "proto-max-bulk-len", "2")
+		must0(t, c,
+			"SETBIT", "bits", "15", "1",
+		)
+		mustDo(t, c,
+			"SETBIT", "bits", "16", "1",
+			proto.Error(msgBitOffset),
+		)
+		s.CheckGet(t, "bits", "\x00\x01")
+		mustOK(t, c, "CONFIG", "SET", "proto-max-bulk-len", "536870912")
+	}
+
 	// Wrong usage
 	{
 		mustDo(t, c,
@@ -1651,3 +2039,175 @@ func TestMsetnx(t *testing.T) {
 		)
 	}
 }
+
+// Which string commands keep the TTL, and which clear it.
+func TestStringTTL(t *testing.T) {
+	s, c := runWithClient(t)
+
+	for _, tc := range []struct {
+		cmd  []string
+		keep bool
+	}{
+		{[]string{"APPEND", "k", "more"}, true},
+		{[]string{"SETRANGE", "k", "2", "xx"}, true},
+		{[]string{"SETBIT", "k", "3", "1"}, true},
+		{[]string{"INCR", "k"}, true},
+		{[]string{"INCRBY", "k", "3"}, true},
+		{[]string{"DECR", "k"}, true},
+		{[]string{"DECRBY", "k", "3"}, true},
+		{[]string{"INCRBYFLOAT", "k", "1.5"}, true},
+		{[]string{"SET", "k", "1", "KEEPTTL"}, true},
+		{[]string{"GETSET", "k", "1"}, false},
+		{[]string{"SET", "k", "1"}, false},
+		{[]string{"MSET", "k", "1"}, false},
+	} {
+		t.Run(strings.Join(tc.cmd, " "), func(t *testing.T) {
+			s.Set("k", "12")
+			s.SetTTL("k", time.Minute)
+
+			_, err := c.Do(tc.cmd...)
+			ok(t, err)
+			if tc.keep {
+				equals(t, time.Minute, s.TTL("k"))
+			} else {
+				equals(t, time.Duration(0), s.TTL("k"))
+			}
+		})
+	}
+}
+
+func TestLcs(t *testing.T) {
+	s, c := runWithClient(t)
+
+	s.Set("key1", "ohmytext")
+	s.Set("key2", "mynewtext")
+
+	mustDo(t, c,
+		"LCS", "key1", "key2",
+		proto.String("mytext"),
+	)
+	mustDo(t, c,
+		"LCS", "key1", "key2", "LEN",
+		proto.Int(6),
+	)
+	mustDo(t, c,
+		"LCS", "key1", "key2", "IDX",
+		proto.Array(
+			proto.String("matches"),
+			proto.Array(
+				proto.Array(proto.Ints(4, 7), proto.Ints(5, 8)),
+				proto.Array(proto.Ints(2, 3), proto.Ints(0, 1)),
+			),
+			proto.String("len"),
+			proto.Int(6),
+		),
+	)
+	mustDo(t, c,
+		"LCS", "key1", "key2", "IDX", "MINMATCHLEN", "4", "WITHMATCHLEN",
+		proto.Array(
+			proto.String("matches"),
+			proto.Array(
+				proto.Array(proto.Ints(4, 7), proto.Ints(5, 8), proto.Int(4)),
+			),
+			proto.String("len"),
+			proto.Int(6),
+		),
+	)
+	mustDo(t, c,
+		"LCS", "key1", "key2", "idx", "withmatchlen", "minmatchlen", "-3",
+		proto.Array(
+			proto.String("matches"),
+			proto.Array(
+				proto.Array(proto.Ints(4, 7), proto.Ints(5, 8), proto.Int(4)),
+				proto.Array(proto.Ints(2, 3), proto.Ints(0, 1), proto.Int(2)),
+			),
+			proto.String("len"),
+			proto.Int(6),
+		),
+	)
+
+	t.Run("missing keys", func(t *testing.T) {
+		mustDo(t, c,
+			"LCS", "key1", "nosuch",
+			proto.String(""),
+		)
+		must0(t, c,
+			"LCS", "nosuch", "nosuch", "LEN",
+		)
+		mustDo(t, c,
+			"LCS", "nosuch", "key2", "IDX",
+			proto.Array(
+				proto.String("matches"),
+				proto.Array(),
+				proto.String("len"),
+				proto.Int(0),
+			),
+		)
+	})
+
+	t.Run("single chars", func(t *testing.T) {
+		s.Set("a", "abcd")
+		s.Set("b", "axcxd")
+		mustDo(t, c,
+			"LCS", "a", "b",
+			proto.String("acd"),
+		)
+		mustDo(t, c,
+			"LCS", "a", "b", "IDX", "WITHMATCHLEN",
+			proto.Array(
+				proto.String("matches"),
+				proto.Array(
+					proto.Array(proto.Ints(3, 3), proto.Ints(4, 4), proto.Int(1)),
+					proto.Array(proto.Ints(2, 2), proto.Ints(2, 2), proto.Int(1)),
+					proto.Array(proto.Ints(0, 0), proto.Ints(0, 0), proto.Int(1)),
+				),
+				proto.String("len"),
+				proto.Int(3),
+			),
+		)
+	})
+
+	t.Run("resp3", func(t *testing.T) {
+		c.Do("HELLO", "3")
+		defer c.Do("HELLO", "2")
+		mustDo(t, c,
+			"LCS", "key1", "key2", "IDX", "MINMATCHLEN", "3",
+			proto.Map(
+				proto.String("matches"),
+				proto.Array(
+					proto.Array(proto.Ints(4, 7), proto.Ints(5, 8)),
+				),
+				proto.String("len"),
+				proto.Int(6),
+			),
+		)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		s.HSet("wrong", "aap", "noot")
+		mustDo(t, c,
+			"LCS", "key1", "wrong",
+			proto.Error(msgLCSWrongType),
+		)
+		mustDo(t, c,
+			"LCS", "key1",
+			proto.Error(errWrongNumber("lcs")),
+		)
+		mustDo(t, c,
+			"LCS", "key1", "key2", "LEN", "IDX",
+			proto.Error(msgLCSLenAndIdx),
+		)
+		mustDo(t, c,
+			"LCS", "key1", "key2", "MINMATCHLEN",
+			proto.Error(msgSyntaxError),
+		)
+		mustDo(t, c,
+			"LCS", "key1", "key2", "MINMATCHLEN", "foo",
+			proto.Error(msgInvalidInt),
+		)
+		mustDo(t, c,
+			"LCS", "key1", "key2", "NOSUCH",
+			proto.Error(msgSyntaxError),
+		)
+	})
+}
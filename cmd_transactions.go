@@ -88,9 +88,11 @@ func (m *Miniredis) cmdExec(c *server.Peer, cmd string, args []string) {
 	}
 
 	c.WriteLen(len(ctx.transaction))
+	m.trackingSrc = c
 	for _, cb := range ctx.transaction {
 		cb(c, ctx)
 	}
+	m.trackingSrc = nil
 	// wake up anyone who waits on anything.
 	m.signal.Broadcast()
 
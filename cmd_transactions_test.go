@@ -2,6 +2,7 @@ package miniredis
 
 import (
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2/proto"
 )
@@ -200,6 +201,56 @@ func TestTxWatchErr(t *testing.T) {
 	)
 }
 
+func TestTxWatchGetdelGetex(t *testing.T) {
+	s, c := runWithClient(t)
+	c2, err := proto.Dial(s.Addr())
+	ok(t, err)
+	defer c2.Close()
+
+	// the watched key changes from another connection, so EXEC aborts.
+	aborts := func(t *testing.T, args ...string) {
+		t.Helper()
+		mustOK(t, c, "WATCH", "one")
+		_, err := c2.Do(args...)
+		ok(t, err)
+		mustOK(t, c, "MULTI")
+		mustDo(t, c, "GET", "one", proto.Inline("QUEUED"))
+		mustNilList(t, c, "EXEC")
+	}
+
+	t.Run("GETDEL", func(t *testing.T) {
+		s.Set("one", "two")
+		aborts(t, "GETDEL", "one")
+		equals(t, false, s.Exists("one"))
+	})
+
+	t.Run("GETEX", func(t *testing.T) {
+		s.Set("one", "two")
+		aborts(t, "GETEX", "one", "EX", "100")
+		equals(t, 100*time.Second, s.TTL("one"))
+
+		aborts(t, "GETEX", "one", "PERSIST")
+		equals(t, time.Duration(0), s.TTL("one"))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		// a plain GETEX, or a PERSIST without a TTL, doesn't change anything
+		s.Set("one", "two")
+		for _, args := range [][]string{
+			{"GETEX", "one"},
+			{"GETEX", "one", "PERSIST"},
+			{"GETDEL", "nosuch"},
+		} {
+			mustOK(t, c, "WATCH", "one")
+			_, err := c2.Do(args...)
+			ok(t, err)
+			mustOK(t, c, "MULTI")
+			mustDo(t, c, "GET", "one", proto.Inline("QUEUED"))
+			mustDo(t, c, "EXEC", proto.Strings("two"))
+		}
+	})
+}
+
 func TestUnwatch(t *testing.T) {
 	s, c := runWithClient(t)
 	c2, err := proto.Dial(s.Addr())
@@ -0,0 +1,149 @@
+package miniredis
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	errConfigNotInt          = errors.New("argument couldn't be parsed into an integer")
+	errConfigNotBool         = errors.New("argument must be 'yes' or 'no'")
+	errConfigImmutable       = errors.New("can't set immutable config")
+	errConfigSave            = errors.New("Invalid save parameters")
+	errConfigAppendfsync     = errors.New("argument(s) must be one of the following: always, everysec, no")
+	errConfigMaxmemoryPolicy = errors.New("argument(s) must be one of the following: volatile-lru, volatile-lfu, volatile-random, volatile-ttl, allkeys-lru, allkeys-lfu, allkeys-random, noeviction")
+)
+
+// configParam is a parameter for CONFIG GET and CONFIG SET.
+type configParam struct {
+	def   string                       // default value
+	check func(string) error           // validates a CONFIG SET value. Optional.
+	get   func(m *Miniredis) string    // reads the value from elsewhere. Optional.
+	set   func(m *Miniredis, v string) // stores the value elsewhere. Optional.
+}
+
+// configParams are all supported CONFIG parameters.
+var configParams = map[string]configParam{
+	"appendfsync":               {def: "everysec", check: checkConfigAppendfsync},
+	"appendonly":                {def: "no", check: checkConfigBool},
+	"databases":                 {def: "16", check: checkConfigImmutable},
+	"hash-max-listpack-entries": {def: "128", check: checkConfigInt},
+	"hash-max-listpack-value":   {def: "64", check: checkConfigInt},
+	"latency-monitor-threshold": {def: "0", check: checkConfigInt},
+	"list-max-listpack-size":    {def: "-2", check: checkConfigInt},
+	"maxmemory":                 {def: "0", check: checkConfigInt},
+	"maxmemory-policy":          {def: "noeviction", check: checkConfigMaxmemoryPolicy},
+	"notify-keyspace-events":    {check: checkConfigNotify, get: getNotify, set: setNotify},
+	"proto-max-bulk-len":        {def: "536870912", check: checkConfigInt},
+	"requirepass":               {get: getRequirepass, set: setRequirepass},
+	"save":                      {def: "3600 1 300 100 60 10000", check: checkConfigSave},
+	"set-max-intset-entries":    {def: "512", check: checkConfigInt},
+	"set-max-listpack-entries":  {def: "128", check: checkConfigInt},
+	"set-max-listpack-value":    {def: "64", check: checkConfigInt},
+	"slowlog-log-slower-than":   {def: "10000", check: checkConfigInt},
+	"slowlog-max-len":           {def: "128", check: checkConfigInt},
+	"tcp-keepalive":             {def: "300", check: checkConfigInt},
+	"timeout":                   {def: "0", check: checkConfigInt},
+	"zset-max-listpack-entries": {def: "128", check: checkConfigInt},
+	"zset-max-listpack-value":   {def: "64", check: checkConfigInt},
+}
+
+// requirepass is the password of the default user. See SetPassword().
+func getRequirepass(m *Miniredis) string {
+	return m.passwords["default"]
+}
+
+func setRequirepass(m *Miniredis, v string) {
+	m.setPassword("default", v)
+}
+
+func checkConfigInt(v string) error {
+	if _, err := strconv.Atoi(v); err != nil {
+		return errConfigNotInt
+	}
+	return nil
+}
+
+func checkConfigBool(v string) error {
+	if v != "yes" && v != "no" {
+		return errConfigNotBool
+	}
+	return nil
+}
+
+// checkConfigImmutable is for parameters which can only be set on startup.
+func checkConfigImmutable(string) error {
+	return errConfigImmutable
+}
+
+// save is "" or pairs of seconds and changes: "3600 1 300 100".
+func checkConfigSave(v string) error {
+	fields := strings.Fields(v)
+	if len(fields)%2 != 0 {
+		return errConfigSave
+	}
+	for _, f := range fields {
+		if n, err := strconv.Atoi(f); err != nil || n < 0 {
+			return errConfigSave
+		}
+	}
+	return nil
+}
+
+func checkConfigAppendfsync(v string) error {
+	switch v {
+	case "always", "everysec", "no":
+		return nil
+	default:
+		return errConfigAppendfsync
+	}
+}
+
+func defaultConfig() map[string]string {
+	c := map[string]string{}
+	for k, p := range configParams {
+		c[k] = p.def
+	}
+	return c
+}
+
+// configValue gives a config value. Needs the lock.
+func (m *Miniredis) configValue(k string) string {
+	if p := configParams[k]; p.get != nil {
+		return p.get(m)
+	}
+	return m.config[k]
+}
+
+// setConfigValue sets a config value. Needs the lock.
+func (m *Miniredis) setConfigValue(k, v string) {
+	if p := configParams[k]; p.set != nil {
+		p.set(m, v)
+		return
+	}
+	m.config[k] = v
+}
+
+// configInt gives an integer config value. Needs the lock.
+func (m *Miniredis) configInt(k string) int {
+	n, _ := strconv.Atoi(m.config[k])
+	return n
+}
+
+// configKeys gives all config keys matching a pattern, sorted.
+func (m *Miniredis) configKeys(pattern string) []string {
+	re := patternRE(pattern)
+	if re == nil {
+		return nil
+	}
+	var keys []string
+	for k := range m.config {
+		if re.MatchString(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -8,16 +8,18 @@ import (
 	"sort"
 	"strconv"
 	"time"
+
+	"github.com/alicebob/miniredis/v2/size"
 )
 
 var (
 	errInvalidEntryID = errors.New("stream ID is invalid")
 )
 
-// exists also updates the lru
+// exists also updates the lru, unless the connection has CLIENT NO-TOUCH on.
 func (db *RedisDB) exists(k string) bool {
 	_, ok := db.keys[k]
-	if ok {
+	if ok && !db.master.noTouch() {
 		db.lru[k] = db.master.effectiveNow()
 	}
 	return ok
@@ -28,10 +30,50 @@ func (db *RedisDB) t(k string) string {
 	return db.keys[k]
 }
 
+// redisType gives the type of a key the way Redis names it, for TYPE and SCAN.
+// Redis stores HyperLogLogs as strings.
+func (db *RedisDB) redisType(k string) string {
+	if t := db.t(k); t != "hll" {
+		return t
+	}
+	return "string"
+}
+
 // incr increases the version and the lru timestamp
 func (db *RedisDB) incr(k string) {
 	db.lru[k] = db.master.effectiveNow()
 	db.keyVersion[k]++
+	db.master.dirty++
+	db.master.invalidate(k)
+}
+
+// memoryUsage gives the MEMORY USAGE of a value. The bool is false if the key
+// doesn't exist.
+func (db *RedisDB) memoryUsage(k string) (int, bool) {
+	var (
+		value interface{}
+		ok    bool
+	)
+	switch db.keys[k] {
+	case "string":
+		value, ok = db.stringKeys[k]
+	case "set":
+		value, ok = db.setKeys[k]
+	case "hash":
+		value, ok = db.hashKeys[k]
+	case "list":
+		value, ok = db.listKeys[k]
+	case "hll":
+		value, ok = db.hllKeys[k]
+	case "zset":
+		value, ok = db.sortedsetKeys[k]
+	case "stream":
+		value, ok = db.streamKeys[k]
+	}
+	if !ok {
+		return 0, false
+	}
+	return size.Of(value), true
 }
 
 // allKeys returns all keys. Sorted.
@@ -56,6 +98,7 @@ func (db *RedisDB) flush() {
 	db.sortedsetKeys = map[string]sortedSet{}
 	db.ttl = map[string]time.Duration{}
 	db.streamKeys = map[string]*streamKey{}
+	db.master.invalidateAll()
 }
 
 // move something to another db. Will return ok. Or not.
@@ -91,12 +134,12 @@ func (db *RedisDB) move(key string, to *RedisDB) bool {
 		to.ttl[key] = v
 	}
 	to.incr(key)
-	db.del(key, true)
+	db.unset(key, true)
 	return true
 }
 
 func (db *RedisDB) rename(from, to string) {
-	db.del(to, true)
+	db.unset(to, true)
 	switch db.t(from) {
 	case "string":
 		db.stringKeys[to] = db.stringKeys[from]
@@ -121,17 +164,28 @@ func (db *RedisDB) rename(from, to string) {
 	}
 	db.incr(to)
 
-	db.del(from, true)
+	db.unset(from, true)
 }
 
+// del removes a key, which counts as a change.
 func (db *RedisDB) del(k string, delTTL bool) {
+	if db.unset(k, delTTL) {
+		db.master.dirty++
+	}
+}
+
+// unset removes a key, without counting it as a change. For keys which are
+// replaced right away, where the incr() of the new value counts the change.
+// Returns whether the key existed.
+func (db *RedisDB) unset(k string, delTTL bool) bool {
 	if !db.exists(k) {
-		return
+		return false
 	}
 	t := db.t(k)
 	delete(db.keys, k)
 	delete(db.lru, k)
 	db.keyVersion[k]++
+	db.master.invalidate(k)
 	if delTTL {
 		delete(db.ttl, k)
 	}
@@ -153,6 +207,7 @@ func (db *RedisDB) del(k string, delTTL bool) {
 	default:
 		panic("Unknown key type: " + t)
 	}
+	return true
 }
 
 // stringGet returns the string key or "" on error/nonexists.
@@ -163,9 +218,10 @@ func (db *RedisDB) stringGet(k string) string {
 	return db.stringKeys[k]
 }
 
-// stringSet force set()s a key. Does not touch expire.
+// stringSet force set()s a key. Does not touch expire, so commands which
+// modify a string in place (APPEND, SETRANGE, INCR, ...) keep the TTL.
 func (db *RedisDB) stringSet(k, v string) {
-	db.del(k, false)
+	db.unset(k, false)
 	db.keys[k] = "string"
 	db.stringKeys[k] = v
 	db.incr(k)
@@ -213,13 +269,18 @@ func (db *RedisDB) stringIncrfloat(k string, delta *big.Float) (*big.Float, erro
 	return v, nil
 }
 
-// listLpush is 'left push', aka unshift. Returns the new length.
-func (db *RedisDB) listLpush(k, v string) int {
+// listLpush is 'left push', aka unshift. The values are pushed one by one, so
+// the last one ends up first. Returns the new length.
+func (db *RedisDB) listLpush(k string, v ...string) int {
 	l, ok := db.listKeys[k]
 	if !ok {
 		db.keys[k] = "list"
 	}
-	l = append([]string{v}, l...)
+	head := make([]string, 0, len(v)+len(l))
+	for i := len(v) - 1; i >= 0; i-- {
+		head = append(head, v[i])
+	}
+	l = append(head, l...)
 	db.listKeys[k] = l
 	db.incr(k)
 	return len(l)
@@ -234,8 +295,8 @@ func (db *RedisDB) listLpop(k string) string {
 		db.del(k, true)
 	} else {
 		db.listKeys[k] = l
+		db.incr(k)
 	}
-	db.incr(k)
 	return el
 }
 
@@ -306,22 +367,26 @@ func (db *RedisDB) setRem(k string, fields ...string) int {
 		db.del(k, true)
 	} else {
 		db.setKeys[k] = s
+		db.incr(k)
 	}
-	db.incr(k)
 	return removed
 }
 
-// All members of a set.
-func (db *RedisDB) setMembers(k string) []string {
-	set := db.setKeys[k]
-	members := make([]string, 0, len(set))
-	for k := range set {
+// members gives all members of a set, sorted.
+func (s setKey) members() []string {
+	members := make([]string, 0, len(s))
+	for k := range s {
 		members = append(members, k)
 	}
 	sort.Strings(members)
 	return members
 }
 
+// All members of a set.
+func (db *RedisDB) setMembers(k string) []string {
+	return db.setKeys[k].members()
+}
+
 // Is a SET value present?
 func (db *RedisDB) setIsMember(k, v string) bool {
 	set, ok := db.setKeys[k]
@@ -343,14 +408,14 @@ func (db *RedisDB) hashFields(k string) []string {
 	return r
 }
 
-// hashValues returns all (sorted) values a hash key.
+// hashValues returns all values of a hash key, in the order of
+// hashFields(), so HVALS lines up with HKEYS and HGETALL.
 func (db *RedisDB) hashValues(k string) []string {
 	h := db.hashKeys[k]
 	var r []string
-	for _, v := range h {
-		r = append(r, v)
+	for _, f := range db.hashFields(k) {
+		r = append(r, h[f])
 	}
-	sort.Strings(r)
 	return r
 }
 
@@ -362,7 +427,7 @@ func (db *RedisDB) hashGet(key, field string) string {
 // hashSet returns the number of new keys
 func (db *RedisDB) hashSet(k string, fv ...string) int {
 	if t, ok := db.keys[k]; ok && t != "hash" {
-		db.del(k, true)
+		db.unset(k, true)
 	}
 	db.keys[k] = "hash"
 	if _, ok := db.hashKeys[k]; !ok {
@@ -510,6 +575,8 @@ func (db *RedisDB) ssetRem(key, member string) bool {
 	if len(ss) == 0 {
 		// Delete key on removal of last member
 		db.del(key, true)
+	} else if ok {
+		db.incr(key)
 	}
 	return ok
 }
@@ -602,39 +669,71 @@ func (db *RedisDB) setInter(keys []string) (setKey, error) {
 	return s, nil
 }
 
-// setIntercard implements the logic behind SINTER*
+// setIntercard implements the logic behind SINTERCARD
 // len keys needs to be > 0
 func (db *RedisDB) setIntercard(keys []string, limit int) (int, error) {
 	// all keys must either not exist, or be of type "set".
-	allExist := true
+	var sets []setKey
 	for _, key := range keys {
-		exists := db.exists(key)
-		allExist = allExist && exists
-		if exists && db.t(key) != "set" {
+		if !db.exists(key) {
+			sets = append(sets, nil)
+			continue
+		}
+		if db.t(key) != "set" {
 			return 0, ErrWrongType
 		}
+		sets = append(sets, db.setKeys[key])
 	}
+	return intercard(sets, limit), nil
+}
 
-	if !allExist {
-		return 0, nil
+// zsetIntercard implements the logic behind ZINTERCARD. Like ZINTER it
+// accepts both sets and sorted sets.
+// len keys needs to be > 0
+func (db *RedisDB) zsetIntercard(keys []string, limit int) (int, error) {
+	var sets []setKey
+	for _, key := range keys {
+		if !db.exists(key) {
+			sets = append(sets, nil)
+			continue
+		}
+		switch db.t(key) {
+		case "set":
+			sets = append(sets, db.setKeys[key])
+		case "zset":
+			set := setKey{}
+			for member := range db.sortedsetKeys[key] {
+				set[member] = struct{}{}
+			}
+			sets = append(sets, set)
+		default:
+			return 0, ErrWrongType
+		}
 	}
+	return intercard(sets, limit), nil
+}
 
-	smallestKey := keys[0]
+// intercard counts the members which are in all sets. It walks the smallest
+// set, and stops at limit, if limit > 0.
+func intercard(sets []setKey, limit int) int {
 	smallestIdx := 0
-	for i, key := range keys {
-		if len(db.setKeys[key]) < len(db.setKeys[smallestKey]) {
-			smallestKey = key
+	for i, set := range sets {
+		if len(set) == 0 {
+			return 0
+		}
+		if len(set) < len(sets[smallestIdx]) {
 			smallestIdx = i
 		}
 	}
-	keys[smallestIdx] = keys[len(keys)-1]
-	keys = keys[:len(keys)-1]
+	smallest := sets[smallestIdx]
+	// don't change the slice we got
+	sets = append(append([]setKey{}, sets[:smallestIdx]...), sets[smallestIdx+1:]...)
 
 	count := 0
-	for item := range db.setKeys[smallestKey] {
+	for item := range smallest {
 		inIntersection := true
-		for _, key := range keys {
-			if _, ok := db.setKeys[key][item]; !ok {
+		for _, set := range sets {
+			if _, ok := set[item]; !ok {
 				inIntersection = false
 				break
 			}
@@ -646,8 +745,7 @@ func (db *RedisDB) setIntercard(keys []string, limit int) (int, error) {
 			}
 		}
 	}
-
-	return count, nil
+	return count
 }
 
 // setUnion implements the logic behind SUNION*
@@ -707,20 +805,29 @@ func (db *RedisDB) streamGroup(key, group string) (*streamGroup, error) {
 	return s.groups[group], nil
 }
 
-// fastForward proceeds the current timestamp with duration, works as a time machine
-func (db *RedisDB) fastForward(duration time.Duration) {
+// fastForward decreases all TTLs by duration, and returns the keys which
+// expired.
+func (db *RedisDB) fastForward(duration time.Duration) []string {
+	var expired []string
 	for _, key := range db.allKeys() {
 		if value, ok := db.ttl[key]; ok {
 			db.ttl[key] = value - duration
-			db.checkTTL(key)
+			if db.checkTTL(key) {
+				db.notify(notifyExpired, "expired", key)
+				expired = append(expired, key)
+			}
 		}
 	}
+	return expired
 }
 
-func (db *RedisDB) checkTTL(key string) {
+// checkTTL deletes the key if its TTL has run out. Returns whether it did.
+func (db *RedisDB) checkTTL(key string) bool {
 	if v, ok := db.ttl[key]; ok && v <= 0 {
 		db.del(key, true)
+		return true
 	}
+	return false
 }
 
 // hllAdd adds members to a hll. Returns 1 if at least 1 if internal HyperLogLog was altered, otherwise 0
@@ -741,20 +848,24 @@ func (db *RedisDB) hllAdd(k string, elems ...string) int {
 	return hllAltered
 }
 
-// hllCount estimates the amount of members added to hll by hllAdd. If called with several arguments, hllCount returns a sum of estimations
+// hllCount estimates the amount of members added to hll by hllAdd. If called
+// with several keys it estimates the size of their union, without changing any
+// of the keys.
 func (db *RedisDB) hllCount(keys []string) (int, error) {
-	countOverall := 0
+	union := newHll()
 	for _, key := range keys {
-		if db.exists(key) && db.t(key) != "hll" {
-			return 0, ErrNotValidHllValue
-		}
 		if !db.exists(key) {
 			continue
 		}
-		countOverall += db.hllKeys[key].Count()
+		if db.t(key) != "hll" {
+			return 0, ErrNotValidHllValue
+		}
+		if len(keys) == 1 {
+			return db.hllKeys[key].Count(), nil
+		}
+		union.Merge(db.hllKeys[key])
 	}
-
-	return countOverall, nil
+	return union.Count(), nil
 }
 
 // hllMerge merges all the hlls provided as keys to the first key. Creates a new hll in the first key if it contains nothing
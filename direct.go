@@ -111,7 +111,7 @@ func (db *RedisDB) Set(k, v string) error {
 	if db.exists(k) && db.t(k) != "string" {
 		return ErrWrongType
 	}
-	db.del(k, true) // Remove expire
+	db.unset(k, true) // Remove expire
 	db.stringSet(k, v)
 	return nil
 }
@@ -820,5 +820,47 @@ func (db *RedisDB) HllMerge(destKey string, sourceKeys ...string) error {
 // Returns ErrKeyNotFound if src does not exist.
 // Overwrites dest if it already exists (unlike the redis command, which needs a flag to allow that).
 func (m *Miniredis) Copy(srcDB int, src string, destDB int, dest string) error {
-	return m.copy(m.DB(srcDB), src, m.DB(destDB), dest)
+	m.Lock()
+	defer m.Unlock()
+	defer m.signal.Broadcast()
+
+	return m.copy(m.db(srcDB), src, m.db(destDB), dest)
+}
+
+// LoadFunction loads a function library, as FUNCTION LOAD does. It returns
+// the library name.
+func (m *Miniredis) LoadFunction(code string) (string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	lib, err := parseLibrary(code)
+	if err != nil {
+		return "", err
+	}
+	if err := m.addLibrary(lib, false); err != nil {
+		lib.close()
+		return "", err
+	}
+	return lib.Name, nil
+}
+
+// Functions gives all loaded function libraries, sorted by name.
+func (m *Miniredis) Functions() []FunctionLibrary {
+	m.Lock()
+	defer m.Unlock()
+
+	var res []FunctionLibrary
+	for _, lib := range m.libraries() {
+		fs := make([]RedisFunction, len(lib.Functions))
+		for i, f := range lib.Functions {
+			f.Flags = append([]string(nil), f.Flags...)
+			fs[i] = f
+		}
+		res = append(res, FunctionLibrary{
+			Name:      lib.Name,
+			Code:      lib.Code,
+			Functions: fs,
+		})
+	}
+	return res
 }
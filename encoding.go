@@ -0,0 +1,272 @@
+package miniredis
+
+// Pick the OBJECT ENCODING Redis would use for a key.
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// Redis shares the integers 0 to 9999 between all string keys, with a
+	// refcount of INT_MAX.
+	sharedIntegers = 10000
+	sharedRefcount = 2147483647
+)
+
+// encoding gives the OBJECT ENCODING of a key, or "" if the key doesn't
+// exist. Redis never converts a key back to a smaller encoding when elements
+// are removed, but we only look at the current value.
+// Lists, sets, hashes, and sorted sets are removed when they become empty, so
+// there is no encoding for an empty one. An empty stream, made with XDEL or
+// XGROUP CREATE's MKSTREAM, is still a "stream".
+func (db *RedisDB) encoding(k string) string {
+	switch db.t(k) {
+	case "string":
+		return stringEncoding(db.stringKeys[k])
+	case "hll":
+		return "raw"
+	case "hash":
+		return db.hashEncoding(k)
+	case "list":
+		return db.listEncoding(k)
+	case "set":
+		return db.setEncoding(k)
+	case "zset":
+		return db.zsetEncoding(k)
+	case "stream":
+		return "stream"
+	default:
+		return ""
+	}
+}
+
+// refcount gives the OBJECT REFCOUNT of an existing key. Redis doesn't share
+// integers when maxmemory is set with an LRU or LFU policy, since those need
+// their own access time.
+func (db *RedisDB) refcount(k string) int {
+	if db.t(k) != "string" {
+		return 1
+	}
+	m := db.master
+	if p := m.config["maxmemory-policy"]; m.configInt("maxmemory") > 0 &&
+		(strings.HasSuffix(p, "-lru") || strings.HasSuffix(p, "-lfu")) {
+		return 1
+	}
+	v := db.stringKeys[k]
+	if stringEncoding(v) != "int" {
+		return 1
+	}
+	if n, err := strconv.Atoi(v); err != nil || n < 0 || n >= sharedIntegers {
+		return 1
+	}
+	return sharedRefcount
+}
+
+// stringEncoding is "int" for integers, "embstr" for short strings, and
+// "raw" for everything else. Redis uses "raw" for anything modified by
+// APPEND or SETRANGE, but we don't track that.
+func stringEncoding(v string) string {
+	switch {
+	case len(v) <= 20 && isRedisInt(v):
+		return "int"
+	case len(v) <= 44:
+		return "embstr"
+	default:
+		return "raw"
+	}
+}
+
+// hashEncoding is "listpack" for small hashes, and "hashtable" for everything
+// else.
+func (db *RedisDB) hashEncoding(k string) string {
+	var (
+		hash     = db.hashKeys[k]
+		maxValue = db.master.configInt("hash-max-listpack-value")
+	)
+	if len(hash) > db.master.configInt("hash-max-listpack-entries") {
+		return "hashtable"
+	}
+	for f, v := range hash {
+		if len(f) > maxValue || len(v) > maxValue {
+			return "hashtable"
+		}
+	}
+	return "listpack"
+}
+
+// listEncoding is "listpack" for lists which fit in a single quicklist node,
+// and "quicklist" for everything else. A list with an element of at least
+// the DEBUG QUICKLIST-PACKED-THRESHOLD size is always a quicklist.
+// When pushing, Redis checks the size limit with the length of the new values,
+// not their listpack size, so a list within a few bytes of the size limit can
+// be a "listpack" in Redis while we say "quicklist".
+func (db *RedisDB) listEncoding(k string) string {
+	if len(db.quicklistNodes(k)) > 1 {
+		return "quicklist"
+	}
+	for _, v := range db.listKeys[k] {
+		if len(v) >= db.master.qlThreshold {
+			return "quicklist"
+		}
+	}
+	return "listpack"
+}
+
+// quicklistNodes splits a list the way a Redis quicklist would, following
+// "list-max-listpack-size", and gives the length of every node. Elements of
+// at least the DEBUG QUICKLIST-PACKED-THRESHOLD size get a node of their own.
+func (db *RedisDB) quicklistNodes(k string) []int {
+	var (
+		list      = db.listKeys[k]
+		size      = db.master.configInt("list-max-listpack-size")
+		maxBytes  = 8192 // safety limit when size is a count
+		threshold = db.master.qlThreshold
+	)
+	if size < 0 {
+		if size < -5 {
+			size = -5
+		}
+		maxBytes = 4096 << (-size - 1)
+	}
+
+	var (
+		nodes []int
+		n     = 0
+		bytes = listpackBytes(nil)
+	)
+	for _, v := range list {
+		if len(v) >= threshold {
+			// plain node
+			if n > 0 {
+				nodes = append(nodes, n)
+				n, bytes = 0, listpackBytes(nil)
+			}
+			nodes = append(nodes, 1)
+			continue
+		}
+		l := listpackTotalBytes(v)
+		full := bytes+l > maxBytes || (size >= 0 && n >= size)
+		if n > 0 && full {
+			nodes = append(nodes, n)
+			n, bytes = 0, listpackBytes(nil)
+		}
+		n++
+		bytes += l
+	}
+	if n > 0 || len(nodes) == 0 {
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// listpackBytes is the size of a listpack with these values.
+func listpackBytes(vs []string) int {
+	n := 6 + 1 // header and terminator
+	for _, v := range vs {
+		n += listpackTotalBytes(v)
+	}
+	return n
+}
+
+// listpackTotalBytes is the size of a single listpack entry, with its
+// backlen.
+func listpackTotalBytes(v string) int {
+	l := listpackEntryBytes(v)
+	switch {
+	case l < 1<<7:
+		return l + 1
+	case l < 1<<14:
+		return l + 2
+	case l < 1<<21:
+		return l + 3
+	case l < 1<<28:
+		return l + 4
+	default:
+		return l + 5
+	}
+}
+
+// listpackEntryBytes is the size of a single listpack entry, without the
+// backlen.
+func listpackEntryBytes(v string) int {
+	if len(v) <= 20 && isRedisInt(v) {
+		i, _ := strconv.ParseInt(v, 10, 64)
+		switch {
+		case i >= 0 && i < 1<<7:
+			return 1
+		case i >= -1<<12 && i < 1<<12:
+			return 2
+		case i >= -1<<15 && i < 1<<15:
+			return 3
+		case i >= -1<<23 && i < 1<<23:
+			return 4
+		case i >= -1<<31 && i < 1<<31:
+			return 5
+		default:
+			return 9
+		}
+	}
+	switch {
+	case len(v) < 1<<6:
+		return 1 + len(v)
+	case len(v) < 1<<12:
+		return 2 + len(v)
+	default:
+		return 5 + len(v)
+	}
+}
+
+// setEncoding is "intset" for small sets with only integers, "listpack" for
+// small sets, and "hashtable" for everything else.
+func (db *RedisDB) setEncoding(k string) string {
+	var (
+		set       = db.setKeys[k]
+		maxValue  = db.master.configInt("set-max-listpack-value")
+		onlyInts  = true
+		longValue = false
+	)
+	for v := range set {
+		if !isRedisInt(v) {
+			onlyInts = false
+		}
+		if len(v) > maxValue {
+			longValue = true
+		}
+	}
+	if onlyInts {
+		// a too big intset is converted straight to a hashtable
+		if len(set) <= db.master.configInt("set-max-intset-entries") {
+			return "intset"
+		}
+		return "hashtable"
+	}
+	if !longValue && len(set) <= db.master.configInt("set-max-listpack-entries") {
+		return "listpack"
+	}
+	return "hashtable"
+}
+
+// zsetEncoding is "listpack" for small sorted sets, and "skiplist" for
+// everything else.
+func (db *RedisDB) zsetEncoding(k string) string {
+	var (
+		zset     = db.sortedsetKeys[k]
+		maxValue = db.master.configInt("zset-max-listpack-value")
+	)
+	if len(zset) > db.master.configInt("zset-max-listpack-entries") {
+		return "skiplist"
+	}
+	for v := range zset {
+		if len(v) > maxValue {
+			return "skiplist"
+		}
+	}
+	return "listpack"
+}
+
+// isRedisInt is true if Redis would store the value as an integer.
+func isRedisInt(v string) bool {
+	n, err := strconv.ParseInt(v, 10, 64)
+	return err == nil && strconv.FormatInt(n, 10) == v
+}
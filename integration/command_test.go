@@ -8,3 +8,41 @@ func TestCommand(t *testing.T) {
 		c.DoLoosely("COMMAND")
 	})
 }
+
+func TestCommandGetkeys(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("COMMAND", "GETKEYS", "GET", "foo")
+		c.Do("COMMAND", "GETKEYS", "MSET", "a", "1", "b", "2")
+		c.Do("COMMAND", "GETKEYS", "LMOVE", "src", "dst", "LEFT", "RIGHT")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "SET", "foo", "bar")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "SET", "foo", "bar", "GET")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "SET", "foo", "GET")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "GET", "foo")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "LMOVE", "src", "dst", "LEFT", "RIGHT")
+		c.Do("COMMAND", "GETKEYS", "EVAL", "return 1", "2", "k1", "k2", "arg")
+		c.Do("COMMAND", "GETKEYS", "GEORADIUS", "src", "1", "2", "3", "km", "STORE", "dst")
+		c.Do("COMMAND", "GETKEYS", "BITOP", "AND", "dst", "a", "b")
+		c.Do("COMMAND", "GETKEYS", "BLMPOP", "0", "2", "l1", "l2", "LEFT")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "EVAL_RO", "return 1", "1", "foo")
+		c.Do("COMMAND", "GETKEYS", "EVAL", "return 1", "0")
+		c.Do("COMMAND", "GETKEYS", "EVALSHA_RO", "abc", "0")
+		c.Do("COMMAND", "GETKEYS", "FCALL", "f", "0")
+		c.Do("COMMAND", "GETKEYSANDFLAGS", "EVAL", "return 1", "0")
+
+		c.Error("wrong number", "COMMAND", "GETKEYS")
+		c.Error("Invalid command", "COMMAND", "GETKEYS", "NOSUCH", "foo")
+		c.Error("no key arguments", "COMMAND", "GETKEYS", "PING")
+	})
+}
+
+func TestCommandInfo(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.DoLoosely("COMMAND", "COUNT")
+		c.Do("COMMAND", "INFO", "nosuch")
+		c.Do("COMMAND", "DOCS", "nosuch")
+
+		c.Error("wrong number", "COMMAND", "COUNT", "foo")
+	})
+}
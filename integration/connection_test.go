@@ -161,6 +161,8 @@ func TestHello(t *testing.T) {
 			c.DoLoosely("HELLO", "3", "AUTH", "default", "foo")
 			c.DoLoosely("HELLO", "3", "AUTH", "default", "foo", "SETNAME", "foo")
 			c.DoLoosely("HELLO", "3", "SETNAME", "foo")
+			c.Do("CLIENT", "GETNAME")
+			c.DoLoosely("HELLO")
 
 			// errors
 			c.Error("Syntax error", "HELLO", "3", "default", "foo")
@@ -177,6 +177,7 @@ func TestScan(t *testing.T) {
 		c.Error("invalid cursor", "SCAN", "noint")
 		c.Error("not an integer", "SCAN", "0", "COUNT", "noint")
 		c.Error("syntax error", "SCAN", "0", "COUNT", "0")
+		c.Error("syntax error", "SCAN", "0", "COUNT", "-1")
 		c.Error("syntax error", "SCAN", "0", "COUNT")
 		c.Error("syntax error", "SCAN", "0", "MATCH")
 		c.Error("syntax error", "SCAN", "0", "garbage")
@@ -211,6 +212,10 @@ func TestFastForward(t *testing.T) {
 		c.DoSorted("KEYS", "*")
 		c.Do("EXPIRE", "key4", "0")
 		c.DoSorted("KEYS", "*")
+
+		c.Error("Unsupported option", "EXPIRE", "key4", "10", "FOO")
+		c.Error("not compatible", "EXPIRE", "key4", "10", "NX", "XX")
+		c.Error("not compatible", "EXPIRE", "key4", "10", "GT", "LT")
 	})
 }
 
@@ -480,6 +485,65 @@ func TestClient(t *testing.T) {
 		c.Error("contain spaces", "CLIENT", "SETNAME", "miniredis\ntests")
 	})
 
+	testRaw(t, func(c *client) {
+		c.Do("CLIENT", "PAUSE", "10000", "WRITE")
+		c.Do("GET", "foo")
+		c.Do("CLIENT", "UNPAUSE")
+		c.Do("SET", "foo", "bar")
+		c.Do("CLIENT", "PAUSE", "0")
+		c.Do("CLIENT", "UNPAUSE")
+
+		c.Error("wrong number", "CLIENT", "PAUSE")
+		c.Error("not an integer", "CLIENT", "PAUSE", "foo")
+		c.Error("negative", "CLIENT", "PAUSE", "-1")
+		c.Error("syntax error", "CLIENT", "PAUSE", "10", "READ")
+		c.Error("wrong number", "CLIENT", "UNPAUSE", "foo")
+	})
+
+	testRaw(t, func(c *client) {
+		c.Do("CLIENT", "NO-TOUCH", "ON")
+		c.Do("CLIENT", "NO-TOUCH", "off")
+		c.Do("CLIENT", "NO-EVICT", "ON")
+		c.Do("CLIENT", "NO-EVICT", "OFF")
+
+		c.Error("wrong number", "CLIENT", "NO-TOUCH")
+		c.Error("syntax error", "CLIENT", "NO-TOUCH", "MAYBE")
+		c.Error("wrong number", "CLIENT", "NO-EVICT")
+		c.Error("syntax error", "CLIENT", "NO-EVICT", "MAYBE")
+	})
+
+	testRaw(t, func(c *client) {
+		c.DoLoosely("CLIENT", "ID")
+		c.Do("CLIENT", "SETINFO", "LIB-NAME", "miniredis-tests")
+		c.Do("CLIENT", "SETINFO", "lib-ver", "1.0")
+		c.DoLoosely("CLIENT", "INFO")
+		c.DoLoosely("CLIENT", "LIST")
+		c.DoLoosely("CLIENT", "LIST", "TYPE", "normal")
+		c.Do("CLIENT", "LIST", "TYPE", "pubsub")
+
+		c.Error("wrong number", "CLIENT", "ID", "foo")
+		c.Error("wrong number", "CLIENT", "INFO", "foo")
+		c.Error("Invalid client ID", "CLIENT", "LIST", "ID", "foo")
+		c.Error("Unknown client type", "CLIENT", "LIST", "TYPE", "foo")
+		c.Error("syntax error", "CLIENT", "LIST", "foo")
+		c.Error("wrong number", "CLIENT", "SETINFO", "LIB-NAME")
+		c.Error("Unrecognized option", "CLIENT", "SETINFO", "foo", "bar")
+		c.Error("cannot contain spaces", "CLIENT", "SETINFO", "LIB-NAME", "a b")
+		c.Error("cannot contain spaces", "CLIENT", "SETINFO", "LIB-VER", "1\x01")
+		c.Do("CLIENT", "SETINFO", "LIB-NAME", "go-redis(,go1.21.0)")
+	})
+
+	testRESP3(t, func(c *client) {
+		c.Error("another provided prefix", "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foo", "PREFIX", "foobar")
+		c.Error("another provided prefix", "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foobar", "PREFIX", "foo")
+		c.Error("another provided prefix", "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foo", "PREFIX", "foo")
+		c.Do("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "foo")
+		c.Error("an existing prefix", "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "bar", "PREFIX", "foobar")
+		c.Error("an existing prefix", "CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "fo")
+		c.Do("CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "bar")
+		c.Do("CLIENT", "TRACKING", "OFF")
+	})
+
 	testRaw2(t, func(c1, c2 *client) {
 		c1.Do("MULTI")
 		c1.Do("CLIENT", "SETNAME", "conn-c1")
@@ -512,4 +576,32 @@ func TestObject(t *testing.T) {
 		c.Error("object|idletime", "OBJECT", "IDLETIME")
 		c.Error("Transaction discarded", "EXEC")
 	})
+
+	testRaw(t, func(c *client) {
+		c.Do("SET", "foo", "bar")
+		c.Error("LFU maxmemory policy is not selected", "OBJECT", "FREQ", "foo")
+		c.Do("OBJECT", "FREQ", "nosuch")
+
+		c.Do("CONFIG", "SET", "maxmemory-policy", "allkeys-lfu")
+		c.Do("SET", "new", "bar")
+		c.Do("OBJECT", "FREQ", "new")
+		c.Error("LFU maxmemory policy is selected", "OBJECT", "IDLETIME", "foo")
+		c.Do("OBJECT", "IDLETIME", "nosuch")
+		c.Error("object|freq", "OBJECT", "FREQ")
+
+		c.Do("CONFIG", "SET", "maxmemory-policy", "noeviction")
+		c.Do("OBJECT", "IDLETIME", "foo")
+	})
+	testRaw(t, func(c *client) {
+		c.Do("SET", "shared", "42")
+		c.Do("OBJECT", "REFCOUNT", "shared")
+		c.Do("SET", "big", "12345")
+		c.Do("OBJECT", "REFCOUNT", "big")
+		c.Do("SET", "str", "foo")
+		c.Do("OBJECT", "REFCOUNT", "str")
+		c.Do("SADD", "set", "1")
+		c.Do("OBJECT", "REFCOUNT", "set")
+		c.Do("OBJECT", "REFCOUNT", "nosuch")
+		c.Error("object|refcount", "OBJECT", "REFCOUNT")
+	})
 }
@@ -422,6 +422,47 @@ func TestGeoradiusByMember(t *testing.T) {
 	})
 }
 
+func TestGeosearch(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("GEOADD",
+			"Sicily",
+			"13.361389", "38.115556", "Palermo",
+			"15.087269", "37.502669", "Catania",
+			"12.758489", "38.788135", "edge1",
+			"17.241510", "38.788135", "edge2",
+		)
+		c.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "ASC")
+		c.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYRADIUS", "200", "km", "DESC", "WITHCOORD", "WITHDIST", "WITHHASH")
+		c.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "400", "400", "km", "ASC", "WITHCOORD", "WITHDIST")
+		c.Do("GEOSEARCH", "Sicily", "FROMLONLAT", "15", "37", "BYBOX", "200", "200", "km", "ASC")
+		c.Do("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "100", "mi", "ASC", "WITHDIST")
+		c.Do("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYBOX", "300", "1000", "ft", "ASC")
+		c.Do("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "500", "km", "COUNT", "2")
+		c.Do("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "500", "km", "COUNT", "2", "DESC")
+		c.Do("GEOSEARCH", "nosuch", "FROMMEMBER", "Palermo", "BYRADIUS", "500", "km")
+
+		c.Error("wrong number", "GEOSEARCH")
+		c.Error("exactly one of FROMMEMBER", "GEOSEARCH", "Sicily", "BYRADIUS", "1", "m")
+		c.Error("exactly one of FROMMEMBER", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "FROMLONLAT", "15", "37", "BYRADIUS", "1", "m")
+		c.Error("exactly one of BYRADIUS", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo")
+		c.Error("exactly one of BYRADIUS", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "BYBOX", "1", "1", "m")
+		c.Error("could not decode", "GEOSEARCH", "Sicily", "FROMMEMBER", "nosuch", "BYRADIUS", "1", "m")
+		c.Error("not a valid float", "GEOSEARCH", "Sicily", "FROMLONLAT", "foo", "37", "BYRADIUS", "1", "m")
+		c.Error("invalid longitude", "GEOSEARCH", "Sicily", "FROMLONLAT", "15", "90", "BYRADIUS", "1", "m")
+		c.Error("need numeric radius", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "foo", "m")
+		c.Error("cannot be negative", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "-1", "m")
+		c.Error("cannot be negative", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYBOX", "1", "-1", "m")
+		c.Error("unsupported unit", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "parsec")
+		c.Error("COUNT must be > 0", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "COUNT", "0")
+		c.Error("syntax error", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "FOO")
+		c.Error("requires COUNT", "GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "ANY")
+		c.Do("GEOSEARCH", "Sicily", "FROMMEMBER", "Palermo", "BYRADIUS", "1", "m", "ANY", "COUNT", "1")
+		c.Do("SET", "str", "I am a string")
+		c.Error("wrong kind", "GEOSEARCH", "str", "FROMLONLAT", "15", "37", "BYRADIUS", "1", "m")
+	})
+}
+
 // a bit longer testset
 func TestGeo(t *testing.T) {
 	skip(t)
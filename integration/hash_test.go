@@ -270,3 +270,18 @@ func TestHrandfield(t *testing.T) {
 		c.Error("ERR wrong number", "HRANDFIELD")
 	})
 }
+
+func TestHashEncoding(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("HSET", "h", "a", "1")
+		c.Do("OBJECT", "ENCODING", "h")
+		c.Do("HSET", "h", "b", "0123456789012345678901234567890123456789012345678901234567890123456789")
+		c.Do("OBJECT", "ENCODING", "h")
+
+		c.Do("CONFIG", "SET", "hash-max-listpack-entries", "1")
+		c.Do("HSET", "h2", "a", "1", "b", "2")
+		c.Do("OBJECT", "ENCODING", "h2")
+		c.Do("CONFIG", "SET", "hash-max-listpack-entries", "128")
+	})
+}
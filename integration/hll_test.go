@@ -57,6 +57,12 @@ func TestHll(t *testing.T) {
 				c.DoApprox(2, "PFCOUNT", "res3")
 			}
 
+			// union of several keys
+			c.DoApprox(2, "PFCOUNT", "h1", "h2")
+			c.DoApprox(2, "PFCOUNT", "h1", "h2", "h3", "nosuch")
+			c.Do("TYPE", "h1")
+			c.Do("OBJECT", "ENCODING", "h1")
+
 			// failure cases
 			c.Error("wrong number", "PFADD")
 			c.Error("wrong number", "PFCOUNT")
@@ -215,6 +215,9 @@ func TestLpos(t *testing.T) {
 		c.Do("SET", "str", "I am a string")
 		c.Error("wrong kind", "LPOS", "str", "aap")
 		c.Error("wrong number", "LPOS", "l")
+		c.Error("wrong number", "LPOS")
+		c.Do("LPOS", "nosuch", "aap")
+		c.Do("LPOS", "nosuch", "aap", "COUNT", "0")
 		c.Error("syntax error", "LPOS", "l", "aap", "RANK")
 		c.Error("syntax error", "LPOS", "l", "aap", "RANK", "1", "COUNT")
 		c.Error("syntax error", "LPOS", "l", "aap", "RANK", "1", "COUNT", "1", "MAXLEN")
@@ -662,3 +665,50 @@ func TestBlmove(t *testing.T) {
 		},
 	)
 }
+
+func TestListEncoding(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("RPUSH", "l", "a", "1")
+		c.Do("OBJECT", "ENCODING", "l")
+
+		c.Do("CONFIG", "SET", "list-max-listpack-size", "2")
+		c.Do("RPUSH", "l2", "a", "b", "c")
+		c.Do("OBJECT", "ENCODING", "l2")
+		c.Do("CONFIG", "SET", "list-max-listpack-size", "-2")
+	})
+}
+
+func TestLmpop(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("RPUSH", "l1", "aap", "noot", "mies")
+		c.Do("RPUSH", "l2", "vuur")
+		c.Do("LMPOP", "3", "nosuch", "l1", "l2", "LEFT")
+		c.Do("LMPOP", "2", "l1", "l2", "right", "COUNT", "10")
+		c.Do("LMPOP", "2", "l1", "l2", "RIGHT", "COUNT", "10")
+		c.Do("LMPOP", "2", "l1", "l2", "LEFT")
+		c.Do("EXISTS", "l1", "l2")
+
+		c.Do("RPUSH", "l3", "aap")
+		c.Do("BLMPOP", "0.1", "1", "l3", "LEFT")
+		c.Do("BLMPOP", "0.1", "1", "l3", "LEFT")
+
+		// transaction
+		c.Do("MULTI")
+		c.Do("BLMPOP", "10", "1", "nosuch", "LEFT")
+		c.Do("EXEC")
+
+		c.Error("wrong number", "LMPOP", "1", "l")
+		c.Error("numkeys", "LMPOP", "foo", "l", "LEFT")
+		c.Error("numkeys", "LMPOP", "0", "l", "LEFT")
+		c.Error("syntax error", "LMPOP", "2", "l", "LEFT")
+		c.Error("syntax error", "LMPOP", "1", "l", "MIDDLE")
+		c.Error("count", "LMPOP", "1", "l", "LEFT", "COUNT", "0")
+		c.Error("syntax error", "LMPOP", "1", "l", "LEFT", "COUNT")
+		c.Error("wrong number", "BLMPOP", "0", "1", "l")
+		c.Error("timeout is negative", "BLMPOP", "-1", "1", "l", "LEFT")
+		c.Do("SET", "str", "value")
+		c.Error("wrong kind", "LMPOP", "2", "nosuch", "str", "LEFT")
+	})
+}
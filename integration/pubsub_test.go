@@ -456,3 +456,28 @@ func TestPubsubTx(t *testing.T) {
 		c1.Do("PUBSUB", "NUMPAT")
 	})
 }
+
+func TestKeyspaceNotifications(t *testing.T) {
+	skip(t)
+	testRaw2(t, func(c1, c2 *client) {
+		c2.Do("CONFIG", "SET", "notify-keyspace-events", "KEA")
+		c2.Do("CONFIG", "GET", "notify-keyspace-events")
+		c1.Do("PSUBSCRIBE", "__key*__:*")
+
+		c2.Do("SET", "foo", "bar")
+		c1.Receive()
+		c1.Receive()
+		c2.Do("EXPIRE", "foo", "100")
+		c1.Receive()
+		c1.Receive()
+		c2.Do("INCR", "count")
+		c1.Receive()
+		c1.Receive()
+		c2.Do("DEL", "foo", "nosuch")
+		c1.Receive()
+		c1.Receive()
+
+		c2.Do("CONFIG", "SET", "notify-keyspace-events", "")
+		c2.Error("Invalid event class", "CONFIG", "SET", "notify-keyspace-events", "KEz?")
+	})
+}
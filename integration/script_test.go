@@ -81,6 +81,27 @@ func TestScript(t *testing.T) {
 			c.Error("wrong number", "EVALSHA")
 			c.Error("wrong number", "EVALSHA", "nosuch")
 			c.Error("Please use EVAL", "EVALSHA", "nosuch", "0")
+			c.Error("Please use EVAL", "EVALSHA", "0123456789abcdef0123456789abcdef01234567", "0")
+			c.Do("EVALSHA", "1FA00E76656CC152AD327C13FE365858FD7BE306", "0")
+			c.Do("SCRIPT", "EXISTS", "1FA00E76656CC152AD327C13FE365858FD7BE306")
+		})
+	})
+
+	t.Run("EVAL_RO", func(t *testing.T) {
+		sha1 := "1fa00e76656cc152ad327c13fe365858fd7be306" // "return 42"
+
+		testRaw(t, func(c *client) {
+			c.Do("SET", "foo", "bar")
+			c.Do("EVAL_RO", "return redis.call('GET', KEYS[1])", "1", "foo")
+			c.Error("read-only scripts", "EVAL_RO", "return redis.call('SET', KEYS[1], 'baz')", "1", "foo")
+			c.Do("GET", "foo")
+
+			c.Do("SCRIPT", "LOAD", "return 42")
+			c.Do("EVALSHA_RO", sha1, "0")
+			c.Error("NOSCRIPT", "EVALSHA_RO", "nosuch", "0")
+
+			c.Error("wrong number", "EVAL_RO", "return 42")
+			c.Error("wrong number", "EVALSHA_RO", sha1)
 		})
 	})
 
@@ -113,6 +134,13 @@ func TestScript(t *testing.T) {
 			c.Do("EVAL", `redis.call("XREAD", "STREAMS", "pl", "$")`, "0")
 			c.Error("not allowed with BLOCK option", "EVAL", `redis.call("XREAD", "BLOCK", "10", "STREAMS", "pl", "$")`, "0")
 			c.Error("not allowed with BLOCK option", "EVAL", `redis.call("XREADGROUP", "GROUP", "group", "consumer", "BLOCK", 1000, "STREAMS", "pl", ">")`, "0")
+
+			// blocking commands time out right away
+			c.Do("EVAL", `return redis.call("BLPOP", "nosuch", "0")`, "0")
+			c.Do("EVAL", `return redis.call("BZPOPMIN", "nosuch", "0")`, "0")
+			c.Do("RPUSH", "l", "aap")
+			c.Do("EVAL", `return redis.call("BLPOP", "l", "0")`, "0")
+			c.Error("not allowed from script", "EVAL", `return redis.call("WAIT", "0", "0")`, "0")
 		})
 	})
 
@@ -388,6 +416,10 @@ func TestLuaCall(t *testing.T) {
 			"This Redis command is not allowed from script script: 508bef3f1ab46859dee541a8bc3b0f368ae1844f,",
 			"EVAL", `redis.call("AUTH", "foobar")`, "0",
 		)
+		c.Error(
+			"This Redis command is not allowed from script script: f37799c893233a336f7a61ad63d125894653e375,",
+			"EVAL", `redis.call("HELLO")`, "0",
+		)
 		c.Error(
 			"This Redis command is not allowed from script script: 62b5d652eb4d90746a5672a450ed9e3627521df1,",
 			"EVAL", `redis.call("WATCH", "foobar")`, "0",
@@ -55,6 +55,32 @@ func TestServer(t *testing.T) {
 		c.Error("wrong number of arguments", "MEMORY", "USAGE")
 		c.Error("syntax error", "MEMORY", "USAGE", "too", "many")
 	})
+
+	// the real Redis has no replicas either
+	testRaw(t, func(c *client) {
+		c.Do("WAIT", "0", "0")
+		c.Do("WAIT", "1", "10")
+		c.Error("wrong number", "WAIT", "1")
+		c.Error("not an integer", "WAIT", "foo", "0")
+		c.Error("not an integer", "WAIT", "1", "foo")
+		c.Error("negative", "WAIT", "1", "-1")
+		c.Error("not allowed from script", "EVAL", "return redis.call('WAIT', '0', '0')", "0")
+	})
+}
+
+func TestRole(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.DoLoosely("ROLE")
+		c.Error("wrong number", "ROLE", "foo")
+
+		c.Error("syntax error", "FAILOVER", "FOO")
+		c.Error("syntax error", "FAILOVER", "TO", "localhost")
+		c.Error("greater than 0", "FAILOVER", "TIMEOUT", "0")
+		c.Error("requires both a timeout", "FAILOVER", "TO", "localhost", "6380", "FORCE")
+		c.Error("cannot be used with other arguments", "FAILOVER", "ABORT", "TIMEOUT", "10")
+		c.Error("No failover in progress", "FAILOVER", "ABORT")
+	})
 }
 
 func TestServerTLS(t *testing.T) {
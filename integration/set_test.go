@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -17,6 +18,7 @@ func TestSet(t *testing.T) {
 		c.Do("SISMEMBER", "s", "aap")
 		c.Do("SISMEMBER", "s", "nosuch")
 		c.Do("SMISMEMBER", "s", "aap", "noot", "nosuch")
+		c.Do("SMISMEMBER", "s", "nosuch", "noot", "noot", "aap")
 
 		c.Do("SCARD", "nosuch")
 		c.Do("SISMEMBER", "nosuch", "nosuch")
@@ -398,3 +400,26 @@ func TestSetNoAuth(t *testing.T) {
 		},
 	)
 }
+
+func TestSetEncoding(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("SADD", "s", "1", "2", "3")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("SADD", "s", "-4")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("SADD", "s", "aap")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("SADD", "s", "0123456789012345678901234567890123456789012345678901234567890123456789")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("OBJECT", "ENCODING", "nosuch")
+
+		// set-max-listpack-value is 64
+		c.Do("SADD", "s64", "aap", strings.Repeat("x", 64))
+		c.Do("OBJECT", "ENCODING", "s64")
+		c.Do("SADD", "s65", "aap", strings.Repeat("x", 65))
+		c.Do("OBJECT", "ENCODING", "s65")
+
+		c.Error("wrong number", "OBJECT", "ENCODING")
+	})
+}
@@ -59,6 +59,9 @@ func TestSortedSet(t *testing.T) {
 		c.Error("wrong number", "ZADD", "s", "1")
 		c.Error("syntax error", "ZADD", "s", "1", "aap", "1")
 		c.Error("not a valid float", "ZADD", "s", "nofloat", "aap")
+		c.Error("syntax error", "ZADD", "s", "FOO", "1", "aap")
+		c.Error("not compatible", "ZADD", "s", "NX", "XX", "nofloat", "aap")
+		c.Error("single increment-element pair", "ZADD", "s", "INCR", "1", "aap", "2", "aap")
 		c.Error("wrong kind", "ZADD", "str", "1", "aap")
 		c.Error("wrong number", "ZCARD")
 		c.Error("wrong number", "ZCARD", "too", "many")
@@ -231,6 +234,7 @@ func TestSortedSetRange(t *testing.T) {
 			c.Do("ZRANGE", "z", "-inf", "+inf", "BYSCORE", "LIMIT", "1", "2")
 			c.Do("ZRANGE", "z", "-inf", "+inf", "BYSCORE", "LIMIT", "0", "-1")
 			c.Do("ZRANGE", "z", "-inf", "+inf", "BYSCORE", "REV", "LIMIT", "0", "1")
+			c.Do("ZRANGE", "z", "+inf", "-inf", "BYSCORE", "REV", "LIMIT", "1", "2", "WITHSCORES")
 			c.Error("not a float", "ZRANGE", "z", "[1", "2", "BYSCORE")
 		})
 
@@ -245,6 +249,7 @@ func TestSortedSetRange(t *testing.T) {
 			c.Do("ZRANGE", "zs", "-", "+", "BYLEX", "LIMIT", "1", "-1")
 			c.Do("ZRANGE", "zs", "-", "+", "BYLEX", "LIMIT", "1", "-1", "REV")
 			c.Error("syntax error", "ZRANGE", "z", "[be", "[ma", "BYSCORE", "BYLEX")
+			c.Error("WITHSCORES not supported", "ZRANGE", "zs", "-", "+", "BYLEX", "WITHSCORES")
 			c.Error("range item", "ZRANGE", "z", "be", "(ma", "BYLEX")
 			c.Error("range item", "ZRANGE", "z", "(be", "ma", "BYLEX")
 		})
@@ -271,6 +276,50 @@ func TestSortedSetRange(t *testing.T) {
 	})
 }
 
+func TestZrangestore(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("ZADD", "z",
+			"1", "aap",
+			"2", "noot",
+			"3", "mies",
+			"2", "nootagain",
+			"3", "miesagain",
+			"+Inf", "the stars",
+			"-Inf", "big bang",
+		)
+		c.Do("ZADD", "lex", "0", "a", "0", "b", "0", "c", "0", "d")
+
+		c.Do("ZRANGESTORE", "dst", "z", "0", "2")
+		c.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")
+		c.Do("ZRANGESTORE", "dst", "z", "0", "2", "REV")
+		c.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")
+		c.Do("ZRANGESTORE", "dst", "z", "(1", "3", "BYSCORE")
+		c.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")
+		c.Do("ZRANGESTORE", "dst", "z", "+inf", "2", "BYSCORE", "REV", "LIMIT", "1", "2")
+		c.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")
+		c.Do("ZRANGESTORE", "dst", "lex", "[b", "+", "BYLEX")
+		c.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")
+		c.Do("ZRANGESTORE", "dst", "lex", "+", "-", "BYLEX", "REV", "LIMIT", "0", "2")
+		c.Do("ZRANGE", "dst", "0", "-1", "WITHSCORES")
+		c.Do("ZRANGESTORE", "dst", "z", "100", "200")
+		c.Do("EXISTS", "dst")
+		c.Do("ZRANGESTORE", "dst", "nosuch", "0", "-1")
+		c.Do("SET", "str", "value")
+		c.Do("ZRANGESTORE", "str", "z", "0", "0")
+		c.Do("TYPE", "str")
+
+		c.Error("wrong number", "ZRANGESTORE", "dst", "z", "0")
+		c.Error("syntax error", "ZRANGESTORE", "dst", "z", "0", "1", "WITHSCORES")
+		c.Error("syntax error", "ZRANGESTORE", "dst", "z", "0", "1", "BYSCORE", "BYLEX")
+		c.Error("LIMIT", "ZRANGESTORE", "dst", "z", "0", "1", "LIMIT", "0", "1")
+		c.Error("not an integer", "ZRANGESTORE", "dst", "z", "foo", "1")
+		c.Error("not a float", "ZRANGESTORE", "dst", "z", "foo", "1", "BYSCORE")
+		c.Error("not valid", "ZRANGESTORE", "dst", "z", "foo", "1", "BYLEX")
+		c.Error("wrong kind", "ZRANGESTORE", "dst", "str", "0", "1")
+	})
+}
+
 func TestSortedSetRevRange(t *testing.T) {
 	skip(t)
 	testRaw(t, func(c *client) {
@@ -487,6 +536,7 @@ func TestSortedSetRangeByScore(t *testing.T) {
 		c.Do("ZCOUNT", "z", "0", "3")
 		c.Do("ZCOUNT", "z", "0", "inf")
 		c.Do("ZCOUNT", "z", "(2", "inf")
+		c.Do("ZCOUNT", "z", "(-inf", "(+inf")
 
 		// Bunch of limit edge cases
 		c.Do("ZRANGEBYSCORE", "z", "-inf", "inf", "LIMIT", "0", "7")
@@ -524,6 +574,7 @@ func TestSortedSetRangeByScore(t *testing.T) {
 
 		c.Error("wrong number", "ZCOUNT")
 		c.Error("not a float", "ZCOUNT", "foo", "[4", "3")
+		c.Error("not a float", "ZCOUNT", "foo", "nan", "3")
 		c.Error("wrong kind", "ZCOUNT", "str", "300", "-110")
 	})
 
@@ -882,6 +933,34 @@ func TestZinter(t *testing.T) {
 		c.Do("SET", "str", "1")
 		c.Error("wrong kind", "ZINTERSTORE", "h", "1", "str")
 	})
+
+	// ZINTERCARD
+	testRaw(t, func(c *client) {
+		c.Do("ZADD", "h1", "1.0", "key1")
+		c.Do("ZADD", "h1", "2.0", "key2")
+		c.Do("ZADD", "h1", "3.0", "key3")
+		c.Do("ZADD", "h2", "1.0", "key1")
+		c.Do("ZADD", "h2", "4.0", "key2")
+		c.Do("SADD", "s1", "key1")
+		c.Do("ZINTERCARD", "1", "h1")
+		c.Do("ZINTERCARD", "2", "h1", "h2")
+		c.Do("ZINTERCARD", "3", "h1", "h2", "s1")
+		c.Do("ZINTERCARD", "2", "h1", "nosuch")
+		c.Do("ZINTERCARD", "2", "h1", "h2", "LIMIT", "0")
+		c.Do("ZINTERCARD", "2", "h1", "h2", "LIMIT", "1")
+		c.Do("ZINTERCARD", "2", "h1", "h2", "LIMIT", "10")
+
+		// Error cases
+		c.Error("wrong number", "ZINTERCARD")
+		c.Error("wrong number", "ZINTERCARD", "1")
+		c.Error("greater than 0", "ZINTERCARD", "0", "h1")
+		c.Error("greater than 0", "ZINTERCARD", "noint", "h1")
+		c.Error("can't be greater", "ZINTERCARD", "3", "h1", "h2")
+		c.Error("syntax error", "ZINTERCARD", "1", "h1", "h2")
+		c.Error("can't be negative", "ZINTERCARD", "1", "h1", "LIMIT", "-1")
+		c.Do("SET", "str", "1")
+		c.Error("wrong kind", "ZINTERCARD", "1", "str")
+	})
 }
 
 func TestZpopminmax(t *testing.T) {
@@ -978,3 +1057,63 @@ func TestZMScore(t *testing.T) {
 		c.Error("wrong kind", "ZMSCORE", "str", "key1")
 	})
 }
+
+func TestSortedSetBzpop(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("ZADD", "z", "1", "one", "2", "two", "3", "three")
+		c.Do("BZPOPMIN", "nosuch", "z", "1")
+		c.Do("BZPOPMAX", "z", "1")
+		c.Do("BZPOPMAX", "z", "1")
+		c.Do("EXISTS", "z")
+
+		// failure cases
+		c.Error("wrong number", "BZPOPMIN")
+		c.Error("wrong number", "BZPOPMIN", "z")
+		c.Error("not a float", "BZPOPMIN", "z", "X")
+		c.Error("timeout is negative", "BZPOPMIN", "z", "-1")
+		c.Do("SET", "str", "value")
+		c.Error("wrong kind", "BZPOPMAX", "str", "1")
+	})
+
+	testMulti(t,
+		func(c *client) {
+			c.Do("BZPOPMIN", "key", "1")
+			c.Do("BZPOPMAX", "key", "1")
+			c.Do("BZPOPMIN", "key", "1") // will timeout
+		},
+		func(c *client) {
+			c.Do("ZADD", "key", "1", "aap", "2", "noot")
+		},
+	)
+}
+
+func TestSortedSetMpop(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("ZADD", "z", "1", "one", "2", "two", "3", "three")
+		c.Do("ZMPOP", "2", "nosuch", "z", "MIN")
+		c.Do("ZMPOP", "1", "z", "max", "COUNT", "5")
+		c.Do("ZMPOP", "1", "z", "MIN")
+		c.Do("EXISTS", "z")
+
+		c.Do("ZADD", "z2", "1.5", "one")
+		c.Do("BZMPOP", "0.1", "1", "z2", "MAX")
+		c.Do("BZMPOP", "0.1", "1", "z2", "MAX")
+
+		c.Error("wrong number", "ZMPOP", "1", "z")
+		c.Error("numkeys", "ZMPOP", "0", "z", "MIN")
+		c.Error("syntax error", "ZMPOP", "1", "z", "LEFT")
+		c.Error("count", "ZMPOP", "1", "z", "MIN", "COUNT", "-1")
+		c.Error("wrong number", "BZMPOP", "0", "1", "z")
+		c.Error("not a float", "BZMPOP", "foo", "1", "z", "MIN")
+		c.Do("SET", "str", "value")
+		c.Error("wrong kind", "ZMPOP", "1", "str", "MIN")
+	})
+
+	testRESP3(t, func(c *client) {
+		c.Do("ZADD", "z", "1.5", "one")
+		c.Do("ZMPOP", "1", "z", "MIN")
+		c.Do("ZMPOP", "1", "z", "MIN")
+	})
+}
@@ -426,6 +426,13 @@ func TestStreamGroup(t *testing.T) {
 			c.Do("XGROUP", "CREATE", "planets", "processing", "$")
 			c.DoLoosely("XINFO", "GROUPS", "planets") // lag is wrong
 			c.Error("already exist", "XGROUP", "CREATE", "planets", "processing", "$")
+			c.Error("already exist", "XGROUP", "CREATE", "planets", "processing", "$", "MKSTREAM")
+			c.Error("to exist", "XGROUP", "CREATE", "nosuch", "processing", "$")
+			c.Do("XGROUP", "CREATE", "planets", "other", "0", "ENTRIESREAD", "0")
+			c.Do("XGROUP", "DESTROY", "planets", "other")
+			c.Error("Invalid stream ID", "XGROUP", "CREATE", "planets", "other", "foo")
+			c.Error("syntax error", "XGROUP", "CREATE", "planets", "other", "$", "FOO")
+			c.Error("syntax error", "XGROUP", "CREATE", "planets", "other", "$", "ENTRIESREAD")
 			c.Error("to exist", "XGROUP", "DESTROY", "foo", "bar")
 			c.Do("XGROUP", "DESTROY", "planets", "bar")
 			c.Error("No such consumer group", "XGROUP", "DELCONSUMER", "planets", "foo", "bar")
@@ -434,6 +441,16 @@ func TestStreamGroup(t *testing.T) {
 			c.Do("XGROUP", "DELCONSUMER", "planets", "processing", "foo")
 			c.Do("XGROUP", "DELCONSUMER", "planets", "processing", "alice")
 			c.Do("XINFO", "CONSUMERS", "planets", "processing")
+			c.Do("XGROUP", "SETID", "planets", "processing", "0")
+			c.Do("XREADGROUP", "GROUP", "processing", "alice", "STREAMS", "planets", ">")
+			c.Do("XGROUP", "SETID", "planets", "processing", "$", "ENTRIESREAD", "1")
+			c.Do("XREADGROUP", "GROUP", "processing", "alice", "STREAMS", "planets", ">")
+			c.Error("No such consumer group", "XGROUP", "SETID", "planets", "foo", "0")
+			c.Error("to exist", "XGROUP", "SETID", "foo", "processing", "0")
+			c.Error("Invalid stream ID", "XGROUP", "SETID", "planets", "processing", "foo")
+			c.Error("syntax error", "XGROUP", "SETID", "planets", "processing", "0", "FOO", "1")
+			c.Error("wrong number of arguments", "XGROUP", "SETID", "planets")
+			c.Do("XGROUP", "DELCONSUMER", "planets", "processing", "alice")
 			c.Do("XGROUP", "DESTROY", "planets", "processing")
 			c.Do("XINFO", "GROUPS", "planets")
 			c.Error("wrong number of arguments", "XGROUP")
@@ -587,6 +604,10 @@ func TestStreamGroup(t *testing.T) {
 			c.Do("XGROUP", "DELCONSUMER", "planets", "processing", "alice")
 			c.Do("XPENDING", "planets", "processing")
 
+			// nothing pending is an empty list
+			c.Do("XPENDING", "planets", "processing", "-", "+", "10", "nosuch")
+			c.Do("XPENDING", "planets", "processing", "-", "+", "-1")
+
 			c.Error("consumer group", "XPENDING", "foo", "processing")
 			c.Error("consumer group", "XPENDING", "planets", "foo")
 
@@ -716,6 +737,13 @@ func TestStreamGroup(t *testing.T) {
 
 			c.Do("XACK", "planets", "processing", "0-1", "0-2", "0-3", "0-4")
 			c.Do("XPENDING", "planets", "processing")
+			c.Do("XCLAIM", "planets", "processing", "alice", "0", "0-3", "FORCE", "JUSTID")
+			c.Do("XCLAIM", "planets", "processing", "alice", "0", "0-99", "FORCE")
+			c.Do("XPENDING", "planets", "processing", "-", "+", "999")
+			c.Do("XCLAIM", "planets", "processing", "bob", "0", "0-3", "JUSTID")
+			c.Do("XPENDING", "planets", "processing", "-", "+", "999")
+			c.Do("XCLAIM", "planets", "processing", "bob", "3600000", "0-3", "JUSTID")
+			c.Do("XACK", "planets", "processing", "0-3")
 
 			c.Error("Unrecognized XCLAIM option", "XCLAIM", "planets", "processing", "alice", "0", "0-3", "RETRYCOUNT", "10", "0-4", "IDLE", "0")
 			c.Error("Unrecognized XCLAIM option", "XCLAIM", "planets", "processing", "alice", "0", "0-3", "RETRYCOUNT", "10", "IDLE", "0", "0-4")
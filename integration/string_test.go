@@ -43,6 +43,9 @@ func TestString(t *testing.T) {
 		c.Error("wrong number", "SET")
 		c.Error("wrong number", "SET", "foo")
 		c.Error("syntax error", "SET", "foo", "bar", "baz")
+		c.Error("syntax error", "SET", "foo", "bar", "EX")
+		c.Error("syntax error", "SET", "foo", "bar", "NX", "XX")
+		c.Error("syntax error", "SET", "foo", "bar", "KEEPTTL", "PX", "10")
 		c.Error("wrong number", "GET")
 		c.Error("wrong number", "GET", "too", "many")
 		c.Error("invalid expire", "SET", "foo", "bar", "EX", "0")
@@ -98,6 +101,7 @@ func TestStringGetex(t *testing.T) {
 		c.Error("syntax error", "GETEX", "foo", "EX", "10", "PERSIST")
 		c.Error("syntax error", "GETEX", "foo", "EX", "10", "PX", "10")
 		c.Error("not an integer", "GETEX", "foo", "EX", "ten")
+		c.Error("invalid expire", "GETEX", "foo", "EX", "0")
 
 		// Wrong type
 		c.Do("HSET", "hash", "key", "value")
@@ -107,6 +111,13 @@ func TestStringGetex(t *testing.T) {
 		c.Do("PEXPIRE", "hittl", "999999")
 		c.Do("GETEX", "hittl", "PERSIST")
 		c.Do("TTL", "hittl")
+
+		c.Do("GETEX", "hittl", "EXAT", "1")
+		c.Do("EXISTS", "hittl")
+
+		c.Do("EXPIRE", "hash", "100")
+		c.Error("wrong kind", "GETEX", "hash", "PERSIST")
+		c.Do("TTL", "hash")
 	})
 }
 
@@ -119,6 +130,10 @@ func TestStringGetdel(t *testing.T) {
 		c.Do("GETDEL", "foo")
 		c.Do("EXISTS", "foo")
 
+		c.Do("PFADD", "hll", "a")
+		c.DoLoosely("GETDEL", "hll")
+		c.Do("EXISTS", "hll")
+
 		// Failure cases
 		c.Error("wrong number", "GETDEL")
 		c.Error("wrong number", "GETDEL", "foo", "bar")
@@ -143,6 +158,8 @@ func TestStringMget(t *testing.T) {
 		// Wrong type
 		c.Do("HSET", "hash", "key", "value")
 		c.Do("MGET", "hash") // not an error.
+		c.Do("RPUSH", "list", "value")
+		c.Do("MGET", "foo", "nosuch", "list", "hash")
 	})
 }
 
@@ -338,6 +355,12 @@ func TestSetrange(t *testing.T) {
 		// Non existing key
 		c.Do("SETRANGE", "nosuch", "2", "aap")
 		c.Do("GET", "nosuch")
+		// Empty value
+		c.Do("SETRANGE", "empty", "0", "")
+		c.Do("SETRANGE", "empty", "5", "")
+		c.Do("EXISTS", "empty")
+		c.Do("SETRANGE", "nosuch", "20", "")
+		c.Do("GET", "nosuch")
 
 		// Error cases
 		c.Error("wrong number", "SETRANGE", "foo")
@@ -445,8 +468,19 @@ func TestBitcount(t *testing.T) {
 		c.Do("BITCOUNT", "str", "-2", "-1")
 		c.Do("BITCOUNT", "str", "-2", "-12")
 		c.Do("BITCOUNT", "utf8", "0", "0")
+		c.Do("BITCOUNT", "str", "0", "0", "BYTE")
+		c.Do("BITCOUNT", "str", "0", "0", "BIT")
+		c.Do("BITCOUNT", "str", "5", "30", "BIT")
+		c.Do("BITCOUNT", "str", "-10", "-3", "bit")
+		c.Do("BITCOUNT", "str", "-3", "-10", "BIT")
+		c.Do("BITCOUNT", "str", "1", "-2000", "BIT")
+		c.Do("BITCOUNT", "utf8", "3", "17", "BIT")
+		c.Do("BITCOUNT", "nosuch", "0", "10", "BIT")
+		c.Error("syntax error", "BITCOUNT", "str", "0", "10", "BITS")
 
 		c.Do("SETBIT", "A", "10", "1")
+		c.Do("BITCOUNT", "A", "10", "10", "BIT")
+		c.Do("BITCOUNT", "A", "0", "9223372036854775807", "BIT")
 		c.Do("BITCOUNT", "A", "0", "100000")
 		c.Do("BITCOUNT", "A", "0", "9223372036854775806")
 		c.Do("BITCOUNT", "A", "0", "9223372036854775807") // max int64
@@ -461,6 +495,50 @@ func TestBitcount(t *testing.T) {
 	})
 }
 
+func TestBitfield(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("BITFIELD", "counters", "GET", "u8", "0", "SET", "i16", "#1", "1000", "INCRBY", "u4", "16", "1", "OVERFLOW", "SAT")
+		c.Do("GET", "counters")
+		c.Do("BITFIELD", "counters", "GET", "i16", "#1", "GET", "u4", "16", "GET", "u32", "0", "GET", "u8", "100")
+		c.Do("BITFIELD", "counters")
+		c.Do("BITFIELD", "nosuch", "GET", "u8", "0")
+		c.Do("EXISTS", "nosuch")
+
+		c.Do("BITFIELD", "ov", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1")
+		c.Do("BITFIELD", "ov", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1")
+		c.Do("BITFIELD", "ov", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1")
+		c.Do("BITFIELD", "ov", "INCRBY", "u2", "100", "1", "OVERFLOW", "SAT", "INCRBY", "u2", "102", "1")
+		c.Do("BITFIELD", "ov", "OVERFLOW", "FAIL", "INCRBY", "u2", "102", "1")
+		c.Do("BITFIELD", "sig", "SET", "i8", "0", "127", "INCRBY", "i8", "0", "1", "INCRBY", "i8", "0", "-1")
+		c.Do("BITFIELD", "sig", "OVERFLOW", "SAT", "INCRBY", "i8", "0", "100", "INCRBY", "i8", "0", "-1000")
+		c.Do("BITFIELD", "sig", "OVERFLOW", "FAIL", "INCRBY", "i8", "0", "-1", "INCRBY", "i8", "0", "1")
+		c.Do("BITFIELD", "set", "SET", "u8", "0", "256", "SET", "u8", "0", "-1", "GET", "u8", "0")
+		c.Do("BITFIELD", "set", "OVERFLOW", "SAT", "SET", "u8", "0", "300", "SET", "i8", "0", "-300", "GET", "i8", "0")
+		c.Do("BITFIELD", "fail", "OVERFLOW", "FAIL", "SET", "u8", "80", "300")
+		c.Do("GET", "fail")
+		c.Do("BITFIELD", "big", "SET", "i64", "0", "9223372036854775807", "INCRBY", "i64", "0", "1", "GET", "u63", "1")
+		c.Do("BITFIELD", "big", "SET", "u63", "1", "-1", "GET", "i64", "0")
+
+		c.Do("SET", "ro", "\x0f")
+		c.Do("BITFIELD_RO", "ro", "GET", "u4", "4", "GET", "i4", "#1")
+
+		c.Error("wrong number", "BITFIELD")
+		c.Error("syntax error", "BITFIELD", "k", "GET", "u8")
+		c.Error("syntax error", "BITFIELD", "k", "FOO", "u8", "0")
+		c.Error("Invalid bitfield type", "BITFIELD", "k", "GET", "u64", "0")
+		c.Error("Invalid bitfield type", "BITFIELD", "k", "GET", "i65", "0")
+		c.Error("Invalid bitfield type", "BITFIELD", "k", "GET", "x8", "0")
+		c.Error("bit offset", "BITFIELD", "k", "GET", "u8", "-1")
+		c.Error("bit offset", "BITFIELD", "k", "GET", "u8", "#foo")
+		c.Error("not an integer", "BITFIELD", "k", "SET", "u8", "0", "foo")
+		c.Error("Invalid OVERFLOW", "BITFIELD", "k", "OVERFLOW", "foo")
+		c.Error("only supports the GET", "BITFIELD_RO", "ro", "SET", "u4", "0", "1")
+		c.Do("HSET", "hash", "aap", "noot")
+		c.Error("wrong kind", "BITFIELD", "hash", "GET", "u8", "0")
+	})
+}
+
 func TestBitop(t *testing.T) {
 	skip(t)
 	testRaw(t, func(c *client) {
@@ -597,6 +675,21 @@ func TestBitpos(t *testing.T) {
 		c.Do("BITPOS", "nosuch", "1", "0")
 		c.Do("BITPOS", "nosuch", "1", "0", "0")
 
+		c.Do("BITPOS", "a", "1", "0", "-1", "BIT")
+		c.Do("BITPOS", "a", "1", "13", "-1", "BIT")
+		c.Do("BITPOS", "a", "1", "0", "11", "BIT")
+		c.Do("BITPOS", "a", "0", "12", "15", "BIT")
+		c.Do("BITPOS", "a", "0", "-3", "-1", "bit")
+		c.Do("BITPOS", "a", "1", "-5", "-3", "BIT")
+		c.Do("BITPOS", "a", "1", "-999", "999", "BIT")
+		c.Do("BITPOS", "a", "1", "10", "5", "BIT")
+		c.Do("BITPOS", "a", "1", "0", "-1", "BYTE")
+		c.Do("BITPOS", "e", "0", "0", "-1", "BIT")
+		c.Do("BITPOS", "empty", "0", "0", "-1", "BIT")
+		c.Do("BITPOS", "nosuch", "0", "0", "-1", "BIT")
+		c.Do("BITPOS", "nosuch", "1", "0", "-1", "BIT")
+		c.Error("syntax error", "BITPOS", "a", "1", "0", "-1", "BITS")
+
 		c.Do("HSET", "hash", "aap", "noot")
 		c.Error("wrong kind", "BITPOS", "hash", "1")
 		c.Error("not an integer", "BITPOS", "a", "aap")
@@ -670,6 +763,36 @@ func TestAppend(t *testing.T) {
 	})
 }
 
+func TestLcs(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("SET", "key1", "ohmytext")
+		c.Do("SET", "key2", "mynewtext")
+		c.Do("LCS", "key1", "key2")
+		c.Do("LCS", "key1", "key2", "LEN")
+		c.Do("LCS", "key1", "key2", "IDX")
+		c.Do("LCS", "key1", "key2", "IDX", "MINMATCHLEN", "4", "WITHMATCHLEN")
+		c.Do("LCS", "key1", "key2", "idx", "withmatchlen", "minmatchlen", "-1")
+		c.Do("LCS", "key1", "nosuch")
+		c.Do("LCS", "nosuch", "key2", "IDX")
+
+		// Failure cases
+		c.Do("HSET", "hash", "aap", "noot")
+		c.Error("must contain string values", "LCS", "key1", "hash")
+		c.Error("wrong number", "LCS", "key1")
+		c.Error("please just use IDX", "LCS", "key1", "key2", "LEN", "IDX")
+		c.Error("syntax error", "LCS", "key1", "key2", "MINMATCHLEN")
+		c.Error("not an integer", "LCS", "key1", "key2", "MINMATCHLEN", "foo")
+		c.Error("syntax error", "LCS", "key1", "key2", "NOSUCH")
+	})
+
+	testRESP3(t, func(c *client) {
+		c.Do("SET", "key1", "ohmytext")
+		c.Do("SET", "key2", "mynewtext")
+		c.Do("LCS", "key1", "key2", "IDX", "WITHMATCHLEN")
+	})
+}
+
 func TestMove(t *testing.T) {
 	skip(t)
 	testRaw(t, func(c *client) {
@@ -704,3 +827,17 @@ func TestMove(t *testing.T) {
 		c.Error("the same", "MOVE", "foo", "0")
 	})
 }
+
+func TestStringEncoding(t *testing.T) {
+	skip(t)
+	testRaw(t, func(c *client) {
+		c.Do("SET", "s", "12345")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("SET", "s", "007")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("SET", "s", "01234567890123456789012345678901234567890123")
+		c.Do("OBJECT", "ENCODING", "s")
+		c.Do("SET", "s", "012345678901234567890123456789012345678901234")
+		c.Do("OBJECT", "ENCODING", "s")
+	})
+}
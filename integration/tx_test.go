@@ -178,4 +178,32 @@ func TestTx(t *testing.T) {
 		c2.Error("without", "EXEC") // nil
 		c1.Do("EXEC")               // 0-length
 	})
+
+	testRaw2(t, func(c1, c2 *client) {
+		c1.Do("SET", "foo", "bar")
+		c1.Do("WATCH", "foo")
+		c2.Do("GETDEL", "foo")
+		c1.Do("MULTI")
+		c1.Do("GET", "foo")
+		c1.Do("EXEC")
+
+		c1.Do("SET", "foo", "bar")
+		c1.Do("WATCH", "foo")
+		c2.Do("GETEX", "foo", "EX", "100")
+		c1.Do("MULTI")
+		c1.Do("GET", "foo")
+		c1.Do("EXEC")
+
+		c1.Do("WATCH", "foo")
+		c2.Do("GETEX", "foo", "PERSIST")
+		c1.Do("MULTI")
+		c1.Do("GET", "foo")
+		c1.Do("EXEC")
+
+		c1.Do("WATCH", "foo")
+		c2.Do("GETEX", "foo")
+		c1.Do("MULTI")
+		c1.Do("GET", "foo")
+		c1.Do("EXEC")
+	})
 }
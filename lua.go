@@ -18,7 +18,10 @@ var luaRedisConstants = map[string]lua.LValue{
 	"LOG_WARNING": lua.LNumber(3),
 }
 
-func mkLua(srv *server.Server, c *server.Peer, sha string) (map[string]lua.LGFunction, map[string]lua.LValue) {
+// mkLua makes the functions for the 'redis' Lua module. With readOnly set
+// redis.call() refuses write commands. With allowOOM set the commands skip the
+// maxmemory check.
+func mkLua(srv *server.Server, c *server.Peer, sha string, readOnly, allowOOM bool) (map[string]lua.LGFunction, map[string]lua.LValue) {
 	mkCall := func(failFast bool) func(l *lua.LState) int {
 		// one server.Ctx for a single Lua run
 		pCtx := &connCtx{}
@@ -27,6 +30,7 @@ func mkLua(srv *server.Server, c *server.Peer, sha string) (map[string]lua.LGFun
 		}
 		pCtx.nested = true
 		pCtx.nestedSHA = sha
+		pCtx.allowOOM = allowOOM
 		pCtx.selectedDB = getCtx(c).selectedDB
 
 		return func(l *lua.LState) int {
@@ -51,6 +55,10 @@ func mkLua(srv *server.Server, c *server.Peer, sha string) (map[string]lua.LGFun
 				l.Error(lua.LString(msgNotFromScripts(sha)), 1)
 				return 0
 			}
+			if _, ok := writeCommands[strings.ToUpper(args[0])]; ok && readOnly {
+				l.Error(lua.LString(msgWriteFromReadOnly), 1)
+				return 0
+			}
 
 			buf := &bytes.Buffer{}
 			wr := bufio.NewWriter(buf)
@@ -279,3 +287,100 @@ func luaStatusReply(msg string) *lua.LTable {
 	tab.RawSetString("ok", lua.LString(msg))
 	return tab
 }
+
+// writeCommands are all commands which can modify data. These are not allowed
+// from read-only functions.
+var writeCommands = map[string]struct{}{
+	"APPEND":            {},
+	"BITFIELD":          {},
+	"BITOP":             {},
+	"BLMOVE":            {},
+	"BLMPOP":            {},
+	"BLPOP":             {},
+	"BRPOP":             {},
+	"BRPOPLPUSH":        {},
+	"BZPOPMAX":          {},
+	"BZMPOP":            {},
+	"BZPOPMIN":          {},
+	"COPY":              {},
+	"DECR":              {},
+	"DECRBY":            {},
+	"DEL":               {},
+	"EXPIRE":            {},
+	"EXPIREAT":          {},
+	"FLUSHALL":          {},
+	"FLUSHDB":           {},
+	"GEOADD":            {},
+	"GEORADIUS":         {},
+	"GEORADIUSBYMEMBER": {},
+	"GETDEL":            {},
+	"GETEX":             {},
+	"GETSET":            {},
+	"HDEL":              {},
+	"HINCRBY":           {},
+	"HINCRBYFLOAT":      {},
+	"HMSET":             {},
+	"HSET":              {},
+	"HSETNX":            {},
+	"INCR":              {},
+	"INCRBY":            {},
+	"INCRBYFLOAT":       {},
+	"LINSERT":           {},
+	"LMOVE":             {},
+	"LMPOP":             {},
+	"LPOP":              {},
+	"LPUSH":             {},
+	"LPUSHX":            {},
+	"LREM":              {},
+	"LSET":              {},
+	"LTRIM":             {},
+	"MOVE":              {},
+	"MSET":              {},
+	"MSETNX":            {},
+	"PERSIST":           {},
+	"PEXPIRE":           {},
+	"PEXPIREAT":         {},
+	"PFADD":             {},
+	"PFMERGE":           {},
+	"PSETEX":            {},
+	"RENAME":            {},
+	"RENAMENX":          {},
+	"RPOP":              {},
+	"RPOPLPUSH":         {},
+	"RPUSH":             {},
+	"RPUSHX":            {},
+	"SADD":              {},
+	"SDIFFSTORE":        {},
+	"SET":               {},
+	"SETBIT":            {},
+	"SETEX":             {},
+	"SETNX":             {},
+	"SETRANGE":          {},
+	"SINTERSTORE":       {},
+	"SMOVE":             {},
+	"SPOP":              {},
+	"SREM":              {},
+	"SUNIONSTORE":       {},
+	"SWAPDB":            {},
+	"UNLINK":            {},
+	"XACK":              {},
+	"XADD":              {},
+	"XAUTOCLAIM":        {},
+	"XCLAIM":            {},
+	"XDEL":              {},
+	"XGROUP":            {},
+	"XREADGROUP":        {},
+	"XTRIM":             {},
+	"ZADD":              {},
+	"ZINCRBY":           {},
+	"ZINTERSTORE":       {},
+	"ZMPOP":             {},
+	"ZPOPMAX":           {},
+	"ZPOPMIN":           {},
+	"ZRANGESTORE":       {},
+	"ZREM":              {},
+	"ZREMRANGEBYLEX":    {},
+	"ZREMRANGEBYRANK":   {},
+	"ZREMRANGEBYSCORE":  {},
+	"ZUNIONSTORE":       {},
+}
@@ -0,0 +1,210 @@
+package miniredis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+const msgOOM = "OOM command not allowed when used memory > 'maxmemory'."
+
+// maxmemoryPolicies are all valid maxmemory-policy values. There are no
+// access counters, so the LFU policies evict the same keys as the LRU ones.
+var maxmemoryPolicies = map[string]struct{}{
+	"noeviction":      {},
+	"allkeys-lru":     {},
+	"allkeys-lfu":     {},
+	"allkeys-random":  {},
+	"volatile-lru":    {},
+	"volatile-lfu":    {},
+	"volatile-random": {},
+	"volatile-ttl":    {},
+}
+
+// lfuInitVal is the access counter of a new key under an LFU policy.
+const lfuInitVal = 5
+
+// lfuPolicy is true if maxmemory-policy is one of the LFU ones. Needs the lock.
+func (m *Miniredis) lfuPolicy() bool {
+	return strings.HasSuffix(m.config["maxmemory-policy"], "-lfu")
+}
+
+// denyOOMCommands are the commands which can use more memory. These evict
+// keys first when maxmemory is set, or fail if that isn't possible.
+var denyOOMCommands = map[string]struct{}{
+	"APPEND":            {},
+	"BITFIELD":          {},
+	"BITOP":             {},
+	"BLMOVE":            {},
+	"BRPOPLPUSH":        {},
+	"COPY":              {},
+	"DECR":              {},
+	"DECRBY":            {},
+	"GEOADD":            {},
+	"GEORADIUS":         {},
+	"GEORADIUSBYMEMBER": {},
+	"GETSET":            {},
+	"HINCRBY":           {},
+	"HINCRBYFLOAT":      {},
+	"HMSET":             {},
+	"HSET":              {},
+	"HSETNX":            {},
+	"INCR":              {},
+	"INCRBY":            {},
+	"INCRBYFLOAT":       {},
+	"LINSERT":           {},
+	"LMOVE":             {},
+	"LPUSH":             {},
+	"LPUSHX":            {},
+	"LSET":              {},
+	"MSET":              {},
+	"MSETNX":            {},
+	"PFADD":             {},
+	"PFMERGE":           {},
+	"PSETEX":            {},
+	"RPOPLPUSH":         {},
+	"RPUSH":             {},
+	"RPUSHX":            {},
+	"SADD":              {},
+	"SDIFFSTORE":        {},
+	"SET":               {},
+	"SETBIT":            {},
+	"SETEX":             {},
+	"SETNX":             {},
+	"SETRANGE":          {},
+	"SINTERSTORE":       {},
+	"SUNIONSTORE":       {},
+	"XADD":              {},
+	"ZADD":              {},
+	"ZINCRBY":           {},
+	"ZINTERSTORE":       {},
+	"ZRANGESTORE":       {},
+	"ZUNIONSTORE":       {},
+}
+
+func checkConfigMaxmemoryPolicy(v string) error {
+	if _, ok := maxmemoryPolicies[v]; !ok {
+		return errConfigMaxmemoryPolicy
+	}
+	return nil
+}
+
+// SetMaxMemory sets the 'maxmemory' config value, in bytes. Writes which can
+// use more memory will evict keys according to the 'maxmemory-policy' config
+// value, which defaults to "noeviction". 0 disables the limit.
+//
+// Memory usage is a rough estimate, see UsedMemory().
+func (m *Miniredis) SetMaxMemory(bytes int) {
+	m.Lock()
+	defer m.Unlock()
+	m.config["maxmemory"] = strconv.Itoa(bytes)
+}
+
+// UsedMemory gives the estimated memory used by all keys, in all databases.
+// It's the sum of the key names and the MEMORY USAGE of every value.
+func (m *Miniredis) UsedMemory() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.usedMemory()
+}
+
+// usedMemory needs the lock.
+func (m *Miniredis) usedMemory() int {
+	total := 0
+	for _, db := range m.dbs {
+		for k := range db.keys {
+			n, _ := db.memoryUsage(k)
+			total += len(k) + n
+		}
+	}
+	return total
+}
+
+// checkMaxmemory evicts keys until we're below maxmemory. It writes an OOM
+// error and returns false if that's not possible. Needs the lock.
+func (m *Miniredis) checkMaxmemory(c *server.Peer, cmd string) bool {
+	if _, ok := denyOOMCommands[cmd]; !ok {
+		return true
+	}
+	if getCtx(c).allowOOM {
+		return true
+	}
+	if !m.evict() {
+		setDirty(c)
+		c.WriteError(msgOOM)
+		return false
+	}
+	return true
+}
+
+// evict removes keys until we're below maxmemory. It returns false if the
+// policy doesn't allow that. Needs the lock.
+func (m *Miniredis) evict() bool {
+	max := m.configInt("maxmemory")
+	if max <= 0 {
+		return true
+	}
+	used := m.usedMemory()
+	if used <= max {
+		return true
+	}
+	policy := m.config["maxmemory-policy"]
+	cands := m.evictionCandidates(policy)
+	for used > max {
+		if len(cands) == 0 {
+			return false
+		}
+		i := 0
+		if strings.HasSuffix(policy, "-random") {
+			i = m.randIntn(len(cands))
+		}
+		c := cands[i]
+		cands = append(cands[:i], cands[i+1:]...)
+
+		n, _ := c.db.memoryUsage(c.key)
+		used -= len(c.key) + n
+		c.db.del(c.key, true)
+		c.db.notify(notifyEvicted, "evicted", c.key)
+		m.evictedKeys++
+	}
+	return true
+}
+
+type evictionCandidate struct {
+	db  *RedisDB
+	key string
+}
+
+// evictionCandidates gives the keys which can be evicted, in the order the
+// policy evicts them. Random policies pick from anywhere in the list.
+func (m *Miniredis) evictionCandidates(policy string) []evictionCandidate {
+	if policy == "noeviction" {
+		return nil
+	}
+	volatile := strings.HasPrefix(policy, "volatile-")
+	var cands []evictionCandidate
+	for _, id := range m.dbIDs() {
+		db := m.dbs[id]
+		for _, k := range db.allKeys() {
+			if _, ok := db.ttl[k]; volatile && !ok {
+				continue
+			}
+			cands = append(cands, evictionCandidate{db, k})
+		}
+	}
+
+	switch policy {
+	case "allkeys-random", "volatile-random":
+	case "volatile-ttl":
+		sort.SliceStable(cands, func(i, j int) bool {
+			return cands[i].db.ttl[cands[i].key] < cands[j].db.ttl[cands[j].key]
+		})
+	default: // LRU and LFU
+		sort.SliceStable(cands, func(i, j int) bool {
+			return cands[i].db.lru[cands[i].key].Before(cands[j].db.lru[cands[j].key])
+		})
+	}
+	return cands
+}
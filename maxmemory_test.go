@@ -0,0 +1,160 @@
+package miniredis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+func TestMaxmemory(t *testing.T) {
+	s, c := runWithClient(t)
+
+	// fill 4 keys, and allow about 2 and a half of them
+	fill := func(t *testing.T, policy string) {
+		t.Helper()
+		mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", policy)
+		s.FlushAll()
+		for i, k := range []string{"k1", "k2", "k3", "k4"} {
+			s.SetTime(time.Unix(int64(100+i), 0))
+			ok(t, s.Set(k, "value"))
+		}
+		s.SetMaxMemory(0)
+		per := s.UsedMemory() / 4
+		s.SetMaxMemory(per*2 + per/2)
+	}
+
+	t.Run("UsedMemory", func(t *testing.T) {
+		s.FlushAll()
+		equals(t, 0, s.UsedMemory())
+		s.Set("foo", "bar")
+		n := s.UsedMemory()
+		assert(t, n > 3, "used memory")
+		s.Set("foo", "a much longer value than before")
+		assert(t, s.UsedMemory() > n, "used memory grows")
+	})
+
+	t.Run("noeviction", func(t *testing.T) {
+		fill(t, "noeviction")
+		mustDo(t, c,
+			"SET", "new", "value",
+			proto.Error(msgOOM),
+		)
+		mustDo(t, c,
+			"LPUSH", "new", "value",
+			proto.Error(msgOOM),
+		)
+		equals(t, []string{"k1", "k2", "k3", "k4"}, s.Keys())
+
+		// reads and deletes are fine
+		mustDo(t, c, "GET", "k1", proto.String("value"))
+		must1(t, c, "DEL", "k1")
+		must1(t, c, "DEL", "k2")
+		mustOK(t, c, "SET", "new", "value")
+	})
+
+	t.Run("tiny", func(t *testing.T) {
+		mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", "noeviction")
+		s.FlushAll()
+		s.SetMaxMemory(1)
+		// an empty db is below any limit
+		mustOK(t, c, "SET", "foo", "bar")
+		mustDo(t, c,
+			"SET", "foo", "baz",
+			proto.Error(msgOOM),
+		)
+		mustDo(t, c, "GET", "foo", proto.String("bar"))
+		s.SetMaxMemory(0)
+		mustOK(t, c, "SET", "foo", "baz")
+	})
+
+	t.Run("allkeys-lru", func(t *testing.T) {
+		fill(t, "allkeys-lru")
+		s.SetTime(time.Unix(200, 0))
+		mustDo(t, c, "GET", "k1", proto.String("value")) // k1 is now recently used
+		mustOK(t, c, "SET", "new", "value")
+		equals(t, []string{"k1", "k4", "new"}, s.Keys())
+		mustContain(t, c, "INFO", "stats", "evicted_keys:2\r\n")
+	})
+
+	t.Run("allkeys-random", func(t *testing.T) {
+		fill(t, "allkeys-random")
+		s.Seed(42)
+		mustOK(t, c, "SET", "new", "value")
+		equals(t, 3, len(s.Keys()))
+	})
+
+	t.Run("many", func(t *testing.T) {
+		mustOK(t, c, "CONFIG", "SET", "maxmemory-policy", "allkeys-lru")
+		s.FlushAll()
+		s.SetMaxMemory(0)
+		for i := 0; i < 1000; i++ {
+			ok(t, s.Set(fmt.Sprintf("key%d", i), "value"))
+		}
+		max := s.UsedMemory() / 2
+		s.SetMaxMemory(max)
+		mustOK(t, c, "SET", "new", "value")
+		n := len(s.Keys())
+		assert(t, n > 400 && n < 600, "evicted about half")
+		assert(t, s.UsedMemory() <= max, "below maxmemory")
+	})
+
+	t.Run("volatile-ttl", func(t *testing.T) {
+		fill(t, "volatile-ttl")
+		s.SetTTL("k1", time.Hour)
+		s.SetTTL("k3", time.Minute)
+		s.SetTTL("k4", 24*time.Hour)
+		mustOK(t, c, "SET", "new", "value")
+		equals(t, []string{"k2", "k4", "new"}, s.Keys())
+
+		mustOK(t, c, "SET", "new2", "value")
+		equals(t, []string{"k2", "new", "new2"}, s.Keys())
+
+		// nothing volatile left
+		mustDo(t, c,
+			"SET", "new3", "value",
+			proto.Error(msgOOM),
+		)
+	})
+
+	t.Run("volatile-lru", func(t *testing.T) {
+		fill(t, "volatile-lru")
+		mustDo(t, c,
+			"SET", "new", "value",
+			proto.Error(msgOOM),
+		)
+		s.SetTTL("k4", time.Hour)
+		s.SetTTL("k2", time.Hour)
+		mustOK(t, c, "SET", "new", "value")
+		equals(t, []string{"k1", "k3", "new"}, s.Keys())
+	})
+
+	t.Run("MULTI", func(t *testing.T) {
+		fill(t, "noeviction")
+		mustOK(t, c, "MULTI")
+		mustDo(t, c,
+			"SET", "new", "value",
+			proto.Error(msgOOM),
+		)
+		mustDo(t, c,
+			"EXEC",
+			proto.Error("EXECABORT Transaction discarded because of previous errors."),
+		)
+	})
+
+	t.Run("CONFIG", func(t *testing.T) {
+		s.SetMaxMemory(1234)
+		mustDo(t, c,
+			"CONFIG", "GET", "maxmemory*",
+			proto.Strings("maxmemory", "1234", "maxmemory-policy", "noeviction"),
+		)
+		mustOK(t, c, "CONFIG", "SET", "maxmemory", "0")
+		mustContain(t, c, "INFO", "memory", "maxmemory:0\r\n")
+
+		mustContain(t, c,
+			"CONFIG", "SET", "maxmemory-policy", "nosuch",
+			"must be one of the following",
+		)
+	})
+}
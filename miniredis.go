@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -55,18 +56,53 @@ type RedisDB struct {
 // Miniredis is a Redis server implementation.
 type Miniredis struct {
 	sync.Mutex
-	srv         *server.Server
-	port        int
-	passwords   map[string]string // username password
-	dbs         map[int]*RedisDB
-	selectedDB  int               // DB id used in the direct Get(), Set() &c.
-	scripts     map[string]string // sha1 -> lua src
-	signal      *sync.Cond
-	now         time.Time // time.Now() if not set.
-	subscribers map[*Subscriber]struct{}
-	rand        *rand.Rand
-	Ctx         context.Context
-	CtxCancel   context.CancelFunc
+	srv           *server.Server
+	port          int
+	passwords     map[string]string // username password
+	authGen       int               // bumped when all connections need to AUTH again
+	authFailures  int               // failed AUTH and HELLO AUTH attempts
+	authFailDelay time.Duration     // sleep after a failed AUTH or HELLO AUTH
+	evictedKeys   int               // keys removed because of maxmemory
+	dbs           map[int]*RedisDB
+	selectedDB    int                         // DB id used in the direct Get(), Set() &c.
+	scripts       map[string]string           // sha1 -> lua src
+	functions     map[string]*FunctionLibrary // FUNCTION LOAD libraries, by name
+	runningMu     sync.Mutex                  // guards running, which FUNCTION STATS and KILL read without the lock
+	running       *runningFunction            // FCALL function being run, or nil
+	signal        *sync.Cond
+	now           time.Time // time.Now() if not set.
+	subscribers   map[*Subscriber]struct{}
+	rand          *rand.Rand
+	Ctx           context.Context
+	CtxCancel     context.CancelFunc
+	errMsg        string                   // set via SetError()
+	disabled      map[string]struct{}      // set via DisableCommand()
+	renames       [][2]string              // from, to. Set via RenameCommand()
+	config        map[string]string        // CONFIG GET/SET values
+	dirty         int                      // changes since the last SAVE
+	lastSave      time.Time                // last SAVE, or when miniredis was made
+	latency       map[string]time.Duration // set via SetCommandLatency()
+	slowlog       []slowlogEntry           // newest first
+	slowlogID     int                      // next slowlog entry id
+	latencyEvents map[string]*latencyEvent // LATENCY events, by name
+	replicas      int                      // set via SetReplicas(), for WAIT
+	replLag       time.Duration            // set via SetReplicationLag(), for WAIT
+	role          string                   // set via SetRole(), roleMaster if empty
+	masterHost    string                   // set via SetMaster()
+	masterPort    int                      // set via SetMaster()
+	qlThreshold   int                      // DEBUG QUICKLIST-PACKED-THRESHOLD
+	notifyFlags   int                      // notify-keyspace-events
+	pauseMode     int                      // CLIENT PAUSE, pauseNone if not paused
+	pauseEnd      time.Time                // CLIENT PAUSE end
+	pauseTimer    *time.Timer              // ends CLIENT PAUSE
+
+	trackers       map[*server.Peer]*clientTracking // CLIENT TRACKING connections
+	trackingSrc    *server.Peer                     // connection running the current command
+	invalidated    []invalidation                   // modified keys, for the trackers
+	invalidatedAll bool                             // a FLUSH happened, for the trackers
+
+	onReply  func(cmd string, reply []byte) // set via OnReply()
+	onExpire func(db int, key string)       // set via SetExpireCallback()
 }
 
 type txCmd func(*server.Peer, *connCtx)
@@ -82,12 +118,19 @@ type dbKey struct {
 type connCtx struct {
 	selectedDB       int            // selected DB
 	authenticated    bool           // auth enabled and a valid AUTH seen
+	noAuth           bool           // used while auth was disabled, in noAuthGen
+	noAuthGen        int            // Miniredis.authGen when noAuth was set
 	transaction      []txCmd        // transaction callbacks. Or nil.
 	dirtyTransaction bool           // any error during QUEUEing
 	watch            map[dbKey]uint // WATCHed keys
 	subscriber       *Subscriber    // client is in PUBSUB mode if not nil
 	nested           bool           // this is called via Lua
 	nestedSHA        string         // set to the SHA of the nesting function
+	noEvict          bool           // CLIENT NO-EVICT
+	noTouch          bool           // CLIENT NO-TOUCH
+	allowOOM         bool           // in a function with the 'allow-oom' flag
+	blocked          time.Duration  // time the current command spent blocked
+	authFailures     int            // failed AUTH and HELLO AUTH attempts
 }
 
 // NewMiniRedis makes a new, non-started, Miniredis object.
@@ -95,7 +138,12 @@ func NewMiniRedis() *Miniredis {
 	m := Miniredis{
 		dbs:         map[int]*RedisDB{},
 		scripts:     map[string]string{},
+		functions:   map[string]*FunctionLibrary{},
 		subscribers: map[*Subscriber]struct{}{},
+		trackers:    map[*server.Peer]*clientTracking{},
+		config:      defaultConfig(),
+		lastSave:    time.Now().UTC(),
+		qlThreshold: defaultPackedThreshold,
 	}
 	m.Ctx, m.CtxCancel = context.WithCancel(context.Background())
 	m.signal = sync.NewCond(&m)
@@ -209,6 +257,15 @@ func (m *Miniredis) start(s *server.Server) error {
 	defer m.Unlock()
 	m.srv = s
 	m.port = s.Addr().Port
+	if m.Ctx.Err() != nil {
+		// Close() cancelled the old one, which would stop all blocking commands.
+		m.Ctx, m.CtxCancel = context.WithCancel(context.Background())
+	}
+	s.SetPreHook(m.preHook)
+	s.SetPostHook(m.postHook)
+	if m.onReply != nil {
+		s.SetReplyHook(m.replyHook)
+	}
 
 	commandsConnection(m)
 	commandsGeneric(m)
@@ -222,15 +279,113 @@ func (m *Miniredis) start(s *server.Server) error {
 	commandsStream(m)
 	commandsTransaction(m)
 	commandsScripting(m)
+	commandsFunction(m)
 	commandsGeo(m)
 	commandsCluster(m)
 	commandsHll(m)
 	commandsClient(m)
 	commandsObject(m)
-
+	commandsConfig(m)
+	commandsDebug(m)
+	commandsSlowlog(m)
+	commandsLatency(m)
+
+	for _, r := range m.renames {
+		if err := s.Rename(r[0], r[1]); err != nil {
+			s.Close()
+			m.srv = nil
+			return err
+		}
+	}
 	return nil
 }
 
+// preHook runs before every command.
+func (m *Miniredis) preHook(c *server.Peer, cmd string, args ...string) bool {
+	if m.busyFunction(c, cmd, args) != nil {
+		// FCALL has the lock for as long as the function runs
+		return false
+	}
+	if !getCtx(c).nested {
+		// Lua's .call() is already locked.
+		m.Lock()
+		defer m.Unlock()
+	}
+	if _, ok := m.disabled[cmd]; ok {
+		setDirty(c)
+		c.WriteError(server.ErrUnknownCommand(cmd, args))
+		return true
+	}
+	if m.errMsg != "" {
+		c.WriteError(m.errMsg)
+		return true
+	}
+	if !getCtx(c).nested {
+		// hold back the command for as long as CLIENT PAUSE is in effect
+		for m.pausedFor(cmd) && !c.Closed() {
+			m.wait(c)
+		}
+	}
+	if !m.checkMaxmemory(c, cmd) {
+		return true
+	}
+	m.trackRead(c, cmd, args)
+	return false
+}
+
+// postHook runs after every command.
+func (m *Miniredis) postHook(c *server.Peer, cmd string, args []string, d time.Duration) {
+	if getCtx(c).nested {
+		// only the EVAL counts
+		return
+	}
+	if m.busyFunction(c, cmd, args) != nil {
+		// can't wait for the lock, so it's not logged
+		return
+	}
+	m.Lock()
+	latency := m.latency[cmd]
+	m.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+		d += latency
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	// time spent blocked or paused doesn't count, same as in Redis
+	ctx := getCtx(c)
+	d -= ctx.blocked
+	ctx.blocked = 0
+	m.logSlow(c, cmd, args, d)
+	m.logLatency(d)
+}
+
+// wait waits for m.signal, and keeps track of how long the connection was
+// blocked. Needs the lock.
+func (m *Miniredis) wait(c *server.Peer) {
+	start := time.Now()
+	m.signal.Wait()
+	getCtx(c).blocked += time.Since(start)
+}
+
+// replyHook runs after every command from a network connection.
+func (m *Miniredis) replyHook(c *server.Peer, cmd string, reply []byte) {
+	m.Lock()
+	f := m.onReply
+	m.Unlock()
+	if f != nil {
+		f(cmd, reply)
+	}
+}
+
+// Unlock releases the lock. Before it does so it sends the invalidation
+// messages for connections which have CLIENT TRACKING enabled.
+func (m *Miniredis) Unlock() {
+	m.sendInvalidations()
+	m.Mutex.Unlock()
+}
+
 // Restart restarts a Close()d server on the same port. Values will be
 // preserved.
 func (m *Miniredis) Restart() error {
@@ -248,6 +403,7 @@ func (m *Miniredis) Close() {
 	srv := m.srv
 	m.srv = nil
 	m.CtxCancel()
+	m.unpause()
 	m.Unlock()
 
 	// the OnDisconnect callbacks can lock m, so run Close() outside the lock.
@@ -267,6 +423,56 @@ func (m *Miniredis) RequireAuth(pw string) {
 func (m *Miniredis) RequireUserAuth(username, pw string) {
 	m.Lock()
 	defer m.Unlock()
+	m.authGen++
+	m.setPassword(username, pw)
+}
+
+// SetPassword changes the password of the default user, the way CONFIG SET
+// requirepass does: connections which are already in use keep working, but
+// new connections need to AUTH. An empty string disables auth.
+// Use RequireAuth() if every connection should AUTH again.
+func (m *Miniredis) SetPassword(pw string) {
+	m.Lock()
+	defer m.Unlock()
+	m.setPassword("default", pw)
+}
+
+// AuthFailures gives the number of failed AUTH attempts, for all
+// connections. HELLO with a wrong AUTH counts as well. This is also in INFO
+// stats as "acl_access_denied_auth".
+func (m *Miniredis) AuthFailures() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.authFailures
+}
+
+// ClientAuthFailures gives the number of failed AUTH attempts of a single
+// connection, by its CLIENT ID. Returns 0 for unknown or closed connections.
+func (m *Miniredis) ClientAuthFailures(id int) int {
+	m.Lock()
+	defer m.Unlock()
+	if m.srv == nil {
+		return 0
+	}
+	for _, p := range m.srv.Peers() {
+		// don't use getCtx(), it would make a context for other connections
+		if ctx, ok := p.Ctx.(*connCtx); ok && p.ID == id {
+			return ctx.authFailures
+		}
+	}
+	return 0
+}
+
+// SetAuthFailDelay makes every failed AUTH, or HELLO AUTH, take at least d
+// before the error is returned. Use this to test client backoff logic.
+func (m *Miniredis) SetAuthFailDelay(d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.authFailDelay = d
+}
+
+// setPassword sets or removes a password. Needs the lock.
+func (m *Miniredis) setPassword(username, pw string) {
 	if m.passwords == nil {
 		m.passwords = map[string]string{}
 	}
@@ -362,10 +568,86 @@ func (m *Miniredis) TotalConnectionCount() int {
 // expired.
 func (m *Miniredis) FastForward(duration time.Duration) {
 	m.Lock()
-	defer m.Unlock()
+	expired := m.fastForward(duration)
+	f := m.onExpire
+	m.Unlock()
+
+	if f != nil {
+		for _, e := range expired {
+			f(e.db, e.key)
+		}
+	}
+}
+
+// FastForwardToNextExpiry decreases all TTLs by the smallest TTL in any
+// database, which expires exactly the keys with that TTL. It returns the names
+// of the expired keys, in database order. If there are no TTLs it does
+// nothing.
+func (m *Miniredis) FastForwardToNextExpiry() []string {
+	m.Lock()
+	var (
+		next  time.Duration
+		found bool
+	)
 	for _, db := range m.dbs {
-		db.fastForward(duration)
+		for _, ttl := range db.ttl {
+			if !found || ttl < next {
+				next, found = ttl, true
+			}
+		}
+	}
+	if !found {
+		m.Unlock()
+		return nil
+	}
+	expired := m.fastForward(next)
+	f := m.onExpire
+	m.Unlock()
+
+	keys := make([]string, 0, len(expired))
+	for _, e := range expired {
+		keys = append(keys, e.key)
+		if f != nil {
+			f(e.db, e.key)
+		}
+	}
+	return keys
+}
+
+type expiredKey struct {
+	db  int
+	key string
+}
+
+// fastForward decreases the TTLs in all databases. Needs the lock.
+func (m *Miniredis) fastForward(duration time.Duration) []expiredKey {
+	var expired []expiredKey
+	for _, id := range m.dbIDs() {
+		for _, k := range m.dbs[id].fastForward(duration) {
+			expired = append(expired, expiredKey{id, k})
+		}
 	}
+	return expired
+}
+
+// dbIDs gives the IDs of all databases in use, sorted. Needs the lock.
+func (m *Miniredis) dbIDs() []int {
+	ids := make([]int, 0, len(m.dbs))
+	for id := range m.dbs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// SetExpireCallback sets a function which is called for every key which
+// expires in FastForward(), in database order, and in key order per database.
+// It's called after the keys are removed, without the lock held, so it can use
+// the Miniredis methods. Clear it with nil.
+func (m *Miniredis) SetExpireCallback(f func(db int, key string)) {
+	m.Lock()
+	defer m.Unlock()
+	m.onExpire = f
 }
 
 // Server returns the underlying server to allow custom commands to be implemented
@@ -456,14 +738,124 @@ func (m *Miniredis) SetTime(t time.Time) {
 //
 // Clear it with an empty string. Don't add newlines.
 func (m *Miniredis) SetError(msg string) {
-	cb := server.Hook(nil)
-	if msg != "" {
-		cb = func(c *server.Peer, cmd string, args ...string) bool {
-			c.WriteError(msg)
-			return true
+	m.Lock()
+	defer m.Unlock()
+	m.errMsg = msg
+}
+
+// SetCommandLatency makes a command take (at least) d longer, before the
+// reply is sent. The extra time counts for SLOWLOG. Clear it with 0.
+func (m *Miniredis) SetCommandLatency(cmd string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	if m.latency == nil {
+		m.latency = map[string]time.Duration{}
+	}
+	m.latency[strings.ToUpper(cmd)] = d
+}
+
+// SetReplicas sets the number of (pretend) replicas WAIT reports. The default
+// is 0. WAIT returns this number right away, unless SetReplicationLag() is
+// used.
+func (m *Miniredis) SetReplicas(n int) {
+	m.Lock()
+	defer m.Unlock()
+	m.replicas = n
+}
+
+// SetReplicationLag makes the replicas from SetReplicas() take d to
+// acknowledge writes, so WAIT blocks for that long, or until its timeout.
+func (m *Miniredis) SetReplicationLag(d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.replLag = d
+}
+
+// SetRole sets the role ROLE, HELLO, and INFO replication report, either
+// "master" (the default), or "slave". "replica" is the same as "slave". Other
+// roles are an error. Nothing is replicated either way. See SetMaster() for
+// the master a "slave" reports.
+func (m *Miniredis) SetRole(role string) error {
+	switch role {
+	case roleMaster:
+	case roleSlave, "replica":
+		role = roleSlave
+	default:
+		return fmt.Errorf("invalid role: %q", role)
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.role = role
+	return nil
+}
+
+// SetMaster sets the master host and port reported when the role is "slave".
+func (m *Miniredis) SetMaster(host string, port int) {
+	m.Lock()
+	defer m.Unlock()
+	m.masterHost, m.masterPort = host, port
+}
+
+// currentRole is either roleMaster or roleSlave. Needs the lock.
+func (m *Miniredis) currentRole() string {
+	if m.role == roleSlave {
+		return roleSlave
+	}
+	return roleMaster
+}
+
+// OnReply sets a function which gets the exact RESP bytes sent in reply to
+// every command, for tests which care about the wire format. cmd is in upper
+// case. Messages pushed to a connection while a command runs, such as pubsub
+// messages, are part of its reply. Commands run by Lua scripts aren't
+// reported. Clear it with nil.
+func (m *Miniredis) OnReply(f func(cmd string, reply []byte)) {
+	m.Lock()
+	defer m.Unlock()
+	m.onReply = f
+	if m.srv == nil {
+		return
+	}
+	if f == nil {
+		m.srv.SetReplyHook(nil)
+		return
+	}
+	m.srv.SetReplyHook(m.replyHook)
+}
+
+// DisableCommand makes a command unknown, as if it was renamed to "" with
+// Redis' rename-command config. Undo with EnableCommand().
+func (m *Miniredis) DisableCommand(cmd string) {
+	m.Lock()
+	defer m.Unlock()
+	if m.disabled == nil {
+		m.disabled = map[string]struct{}{}
+	}
+	m.disabled[strings.ToUpper(cmd)] = struct{}{}
+}
+
+// EnableCommand undoes a DisableCommand().
+func (m *Miniredis) EnableCommand(cmd string) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.disabled, strings.ToUpper(cmd))
+}
+
+// RenameCommand makes a command only available under a new name, the old name
+// will be an unknown command. This is Redis' rename-command config. Renaming
+// to "" removes the command. It's an error if the command doesn't exist, or if
+// the new name is already taken. Renames done before Start() are checked when
+// the server starts.
+func (m *Miniredis) RenameCommand(from, to string) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.srv != nil {
+		if err := m.srv.Rename(from, to); err != nil {
+			return err
 		}
 	}
-	m.srv.SetPreHook(cb)
+	m.renames = append(m.renames, [2]string{from, to})
+	return nil
 }
 
 // isValidCMD returns true if command is valid and can be executed.
@@ -486,16 +878,25 @@ func (m *Miniredis) handleAuth(c *server.Peer) bool {
 
 	m.Lock()
 	defer m.Unlock()
-	if len(m.passwords) == 0 {
-		return true
-	}
-	if !getCtx(c).authenticated {
+	if !m.isAuthenticated(getCtx(c)) {
 		c.WriteError("NOAUTH Authentication required.")
 		return false
 	}
 	return true
 }
 
+// isAuthenticated is true if the connection doesn't need to AUTH. Needs the
+// lock.
+func (m *Miniredis) isAuthenticated(ctx *connCtx) bool {
+	if len(m.passwords) == 0 {
+		// Redis authenticates these as the default user
+		ctx.noAuth = true
+		ctx.noAuthGen = m.authGen
+		return true
+	}
+	return ctx.authenticated || (ctx.noAuth && ctx.noAuthGen == m.authGen)
+}
+
 // handlePubsub sends an error to the user if the connection is in PUBSUB mode.
 // It'll return true if it did.
 func (m *Miniredis) checkPubsub(c *server.Peer, cmd string) bool {
@@ -530,6 +931,12 @@ func getCtx(c *server.Peer) *connCtx {
 	return c.Ctx.(*connCtx)
 }
 
+// noTouch is true if the connection running the current command has CLIENT
+// NO-TOUCH on. Needs the lock.
+func (m *Miniredis) noTouch() bool {
+	return m.trackingSrc != nil && getCtx(m.trackingSrc).noTouch
+}
+
 func startTx(ctx *connCtx) {
 	ctx.transaction = []txCmd{}
 	ctx.dirtyTransaction = false
@@ -568,6 +975,13 @@ func setDirty(c *server.Peer) {
 	getCtx(c).dirtyTransaction = true
 }
 
+// syntaxError writes the generic "ERR syntax error", which option parsers use
+// for unknown, missing, or conflicting options. Can be called when not in a tx.
+func syntaxError(c *server.Peer) {
+	setDirty(c)
+	c.WriteError(msgSyntaxError)
+}
+
 func (m *Miniredis) addSubscriber(s *Subscriber) {
 	m.subscribers[s] = struct{}{}
 }
@@ -638,6 +1052,45 @@ func (m *Miniredis) NewSubscriber() *Subscriber {
 	return sub
 }
 
+// Subscribe gets the messages published on a channel, without going through
+// a connection. Messages are queued until they're read, so PUBLISH never
+// blocks. Call the returned func when done, which closes the channel, and
+// drops messages which weren't read yet.
+func (m *Miniredis) Subscribe(channel string) (<-chan PubsubMessage, func()) {
+	sub := m.NewSubscriber()
+	sub.Subscribe(channel)
+
+	out := make(chan PubsubMessage)
+	go func() {
+		defer close(out)
+		var queue []PubsubMessage
+		for {
+			var (
+				send chan PubsubMessage // nil when there's nothing to send
+				next PubsubMessage
+			)
+			if len(queue) > 0 {
+				send, next = out, queue[0]
+			}
+			select {
+			case msg, ok := <-sub.Messages():
+				if !ok {
+					return
+				}
+				queue = append(queue, msg)
+			case send <- next:
+				queue = queue[1:]
+			}
+		}
+	}()
+
+	return out, func() {
+		m.Lock()
+		defer m.Unlock()
+		m.removeSubscriber(sub)
+	}
+}
+
 func (m *Miniredis) allSubscribers() []*Subscriber {
 	var subs []*Subscriber
 	for s := range m.subscribers {
@@ -646,6 +1099,9 @@ func (m *Miniredis) allSubscribers() []*Subscriber {
 	return subs
 }
 
+// Seed makes all randomized commands (RANDOMKEY, SPOP, SRANDMEMBER,
+// HRANDFIELD, ZRANDMEMBER, and the maxmemory "random" policies) use their own
+// RNG with this seed, so results are reproducible.
 func (m *Miniredis) Seed(seed int) {
 	m.Lock()
 	defer m.Unlock()
@@ -701,6 +1157,11 @@ func (m *Miniredis) copy(
 	if !srcDB.exists(src) {
 		return ErrKeyNotFound
 	}
+	if srcDB == destDB && src == dst {
+		return nil
+	}
+	// the old value might be a different type, and have a TTL
+	destDB.unset(dst, true)
 
 	switch srcDB.t(src) {
 	case "string":
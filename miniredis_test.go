@@ -3,6 +3,9 @@ package miniredis
 import (
 	"bufio"
 	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -63,6 +66,122 @@ func TestRestart(t *testing.T) {
 	)
 }
 
+// Test Close() with blocking commands
+func TestCloseBlocking(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	s := RunT(t)
+	s.XAdd("stream", "*", []string{"name", "mies"})
+
+	var done []chan struct{}
+	for _, args := range [][]string{
+		{"BLPOP", "list", "0"},
+		{"BRPOPLPUSH", "list", "other", "0"},
+		{"BZPOPMIN", "zset", "0"},
+		{"BLMPOP", "0", "1", "list", "LEFT"},
+		{"XREAD", "BLOCK", "0", "STREAMS", "stream", "$"},
+	} {
+		c, err := proto.Dial(s.Addr())
+		ok(t, err)
+		d := make(chan struct{})
+		done = append(done, d)
+		go func(args []string) {
+			defer close(d)
+			defer c.Close()
+			c.Do(args...) // connection will be closed
+		}(args)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close() didn't return")
+	}
+	for _, d := range done {
+		select {
+		case <-d:
+		case <-time.After(time.Second):
+			t.Fatal("blocking command didn't return")
+		}
+	}
+
+	// everything should be gone
+	for i := 0; ; i++ {
+		if runtime.NumGoroutine() <= before {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("leaked goroutines: %d > %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// blocking still works after a restart
+	ok(t, s.Restart())
+	got := goStrings(t, s, "BLPOP", "list", "0")
+	time.Sleep(30 * time.Millisecond)
+	s.Push("list", "aap")
+	select {
+	case have := <-got:
+		equals(t, proto.Strings("list", "aap"), have)
+	case <-time.After(time.Second):
+		t.Fatal("BLPOP didn't return")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	s, c := runWithClient(t)
+
+	members := make([]string, 100)
+	for i := range members {
+		members[i] = fmt.Sprintf("m%d", i)
+		s.Set(fmt.Sprintf("key%d", i), "value")
+		s.HSet("hash", members[i], "value")
+		s.ZAdd("zset", float64(i), members[i])
+	}
+	s.SetAdd("set", members...)
+
+	run := func() []string {
+		var res []string
+		for _, args := range [][]string{
+			{"SRANDMEMBER", "set", "5"},
+			{"SRANDMEMBER", "set", "-5"},
+			{"RANDOMKEY"},
+			{"HRANDFIELD", "hash", "5"},
+			{"ZRANDMEMBER", "zset", "5"},
+		} {
+			v, err := c.Do(args...)
+			ok(t, err)
+			res = append(res, v)
+		}
+		return res
+	}
+
+	s.Seed(42)
+	first := run()
+	s.Seed(42)
+	equals(t, first, run())
+	s.Seed(43)
+	assert(t, !reflect.DeepEqual(first, run()), "different seed, different results")
+
+	// SPOP changes the set, so put them back
+	s.Seed(42)
+	popped, err := c.DoStrings("SPOP", "set", "3")
+	ok(t, err)
+	s.SetAdd("set", popped...)
+	s.Seed(42)
+	again, err := c.DoStrings("SPOP", "set", "3")
+	ok(t, err)
+	equals(t, popped, again)
+}
+
 // Test a custom addr
 func TestAddr(t *testing.T) {
 	m := NewMiniRedis()
@@ -224,6 +343,85 @@ func TestExpireWithFastForward(t *testing.T) {
 	equals(t, 1, len(s.Keys()))
 }
 
+func TestExpireCallback(t *testing.T) {
+	s := RunT(t)
+
+	type expired struct {
+		db  int
+		key string
+	}
+	var got []expired
+	s.SetExpireCallback(func(db int, key string) {
+		got = append(got, expired{db, key})
+		// the lock isn't held
+		equals(t, false, s.Exists(key))
+	})
+
+	s.Set("aap", "noot")
+	s.Set("noot", "aap")
+	s.Set("mies", "aap")
+	s.SetTTL("aap", 10*time.Second)
+	s.SetTTL("noot", 20*time.Second)
+	s.SetTTL("mies", 5*time.Second)
+	s.DB(3).Set("vuur", "noot")
+	s.DB(3).SetTTL("vuur", 10*time.Second)
+
+	s.FastForward(5 * time.Second)
+	equals(t, []expired{{0, "mies"}}, got)
+
+	got = nil
+	s.FastForward(5 * time.Second)
+	equals(t, []expired{{0, "aap"}, {3, "vuur"}}, got)
+
+	s.SetExpireCallback(nil)
+	got = nil
+	s.FastForward(10 * time.Second)
+	equals(t, []expired(nil), got)
+	equals(t, []string{}, s.Keys())
+}
+
+func TestFastForwardToNextExpiry(t *testing.T) {
+	s := RunT(t)
+
+	equals(t, []string(nil), s.FastForwardToNextExpiry())
+
+	s.Set("aap", "noot")
+	s.Set("noot", "aap")
+	s.Set("mies", "aap")
+	s.Set("vuur", "aap")
+	s.SetTTL("aap", 10*time.Second)
+	s.SetTTL("noot", 20*time.Second)
+	s.SetTTL("mies", 5*time.Second)
+	s.DB(3).Set("vuur", "noot")
+	s.DB(3).SetTTL("vuur", 10*time.Second)
+
+	equals(t, []string{"mies"}, s.FastForwardToNextExpiry())
+	equals(t, 5*time.Second, s.TTL("aap"))
+	equals(t, 15*time.Second, s.TTL("noot"))
+
+	equals(t, []string{"aap", "vuur"}, s.FastForwardToNextExpiry())
+	equals(t, 10*time.Second, s.TTL("noot"))
+	equals(t, []string{"noot", "vuur"}, s.Keys())
+
+	equals(t, []string{"noot"}, s.FastForwardToNextExpiry())
+	equals(t, []string{"vuur"}, s.Keys())
+
+	// no TTLs left
+	equals(t, []string(nil), s.FastForwardToNextExpiry())
+	equals(t, []string{"vuur"}, s.Keys())
+
+	t.Run("callback", func(t *testing.T) {
+		var got []string
+		s.SetExpireCallback(func(db int, key string) {
+			got = append(got, key)
+		})
+		defer s.SetExpireCallback(nil)
+		s.SetTTL("vuur", time.Minute)
+		equals(t, []string{"vuur"}, s.FastForwardToNextExpiry())
+		equals(t, []string{"vuur"}, got)
+	})
+}
+
 /*
 we don't have the redis client anymore
 func TestPool(t *testing.T) {
@@ -298,3 +496,111 @@ func TestMiniredis_isValidCMD(t *testing.T) {
 		})
 	}
 }
+
+// Redis has no defined order for these, but we always return the same order.
+func TestOrdering(t *testing.T) {
+	var scans []string
+	for i := 0; i < 2; i++ {
+		s, c := runWithClient(t)
+
+		for _, m := range []string{"mies", "aap", "vuur", "noot"} {
+			s.SetAdd("set", m)
+			s.HSet("hash", m, "v-"+m)
+			s.Set("k-"+m, m)
+		}
+
+		mustDo(t, c,
+			"SMEMBERS", "set",
+			proto.Strings("aap", "mies", "noot", "vuur"),
+		)
+		mustDo(t, c,
+			"SSCAN", "set", "0",
+			proto.Array(
+				proto.String("0"),
+				proto.Strings("aap", "mies", "noot", "vuur"),
+			),
+		)
+		mustDo(t, c,
+			"HKEYS", "hash",
+			proto.Strings("aap", "mies", "noot", "vuur"),
+		)
+		mustDo(t, c,
+			"HVALS", "hash",
+			proto.Strings("v-aap", "v-mies", "v-noot", "v-vuur"),
+		)
+		mustDo(t, c,
+			"HGETALL", "hash",
+			proto.Strings("aap", "v-aap", "mies", "v-mies", "noot", "v-noot", "vuur", "v-vuur"),
+		)
+
+		res, err := c.Do("SCAN", "0", "COUNT", "100")
+		ok(t, err)
+		scans = append(scans, res)
+	}
+	equals(t, scans[0], scans[1])
+
+	t.Run("hash", func(t *testing.T) {
+		s, c := runWithClient(t)
+
+		// values sort differently than their fields
+		s.HSet("hash", "aap", "3", "mies", "1", "noot", "4", "vuur", "2")
+
+		fields, err := c.Do("HKEYS", "hash")
+		ok(t, err)
+		vals, err := c.Do("HVALS", "hash")
+		ok(t, err)
+		all, err := c.Do("HGETALL", "hash")
+		ok(t, err)
+
+		fs, err := proto.ReadStrings(fields)
+		ok(t, err)
+		vs, err := proto.ReadStrings(vals)
+		ok(t, err)
+		as, err := proto.ReadStrings(all)
+		ok(t, err)
+		equals(t, len(fs), len(vs))
+		equals(t, 2*len(fs), len(as))
+		for i := range fs {
+			equals(t, fs[i], as[2*i])
+			equals(t, vs[i], as[2*i+1])
+		}
+		equals(t, []string{"3", "1", "4", "2"}, vs)
+	})
+}
+
+func TestOnReply(t *testing.T) {
+	s, c := runWithClient(t)
+
+	type reply struct {
+		cmd string
+		raw string
+	}
+	replies := make(chan reply, 10)
+	s.OnReply(func(cmd string, raw []byte) {
+		replies <- reply{cmd, string(raw)}
+	})
+
+	s.HSet("h", "aap", "noot")
+	mustDo(t, c,
+		"HGETALL", "h",
+		proto.Strings("aap", "noot"),
+	)
+	equals(t, reply{"HGETALL", "*2\r\n$3\r\naap\r\n$4\r\nnoot\r\n"}, <-replies)
+
+	mustOK(t, c, "set", "foo", "bar")
+	equals(t, reply{"SET", "+OK\r\n"}, <-replies)
+
+	_, err := c.Do("HELLO", "3")
+	ok(t, err)
+	<-replies
+	mustDo(t, c, "HGETALL", "h", proto.StringMap("aap", "noot"))
+	equals(t, reply{"HGETALL", "%1\r\n$3\r\naap\r\n$4\r\nnoot\r\n"}, <-replies)
+
+	s.OnReply(nil)
+	mustOK(t, c, "SET", "foo", "bar")
+	select {
+	case r := <-replies:
+		t.Fatalf("unexpected reply: %v", r)
+	default:
+	}
+}
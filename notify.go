@@ -0,0 +1,124 @@
+package miniredis
+
+// Keyspace notifications, see "notify-keyspace-events".
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// notify-keyspace-events classes.
+const (
+	notifyKeyspace = 1 << iota // K
+	notifyKeyevent             // E
+	notifyGeneric              // g
+	notifyString               // $
+	notifyList                 // l
+	notifySet                  // s
+	notifyHash                 // h
+	notifyZset                 // z
+	notifyExpired              // x
+	notifyEvicted              // e
+	notifyStream               // t
+	notifyModule               // d
+	notifyKeyMiss              // m
+	notifyNew                  // n
+
+	// A
+	notifyAll = notifyGeneric | notifyString | notifyList | notifySet |
+		notifyHash | notifyZset | notifyExpired | notifyEvicted | notifyStream |
+		notifyModule
+)
+
+var errConfigNotify = errors.New("Invalid event class character. Use 'Ag$lshzxeKEtmdn'.")
+
+// notifyClasses are all classes, in the order Redis shows them.
+var notifyClasses = []struct {
+	c    byte
+	flag int
+}{
+	{'g', notifyGeneric},
+	{'$', notifyString},
+	{'l', notifyList},
+	{'s', notifySet},
+	{'h', notifyHash},
+	{'z', notifyZset},
+	{'x', notifyExpired},
+	{'e', notifyEvicted},
+	{'t', notifyStream},
+	{'d', notifyModule},
+	{'K', notifyKeyspace},
+	{'E', notifyKeyevent},
+	{'m', notifyKeyMiss},
+	{'n', notifyNew},
+}
+
+// parseNotifyFlags parses a notify-keyspace-events value.
+func parseNotifyFlags(v string) (int, bool) {
+	flags := 0
+outer:
+	for i := 0; i < len(v); i++ {
+		if v[i] == 'A' {
+			flags |= notifyAll
+			continue
+		}
+		for _, nc := range notifyClasses {
+			if nc.c == v[i] {
+				flags |= nc.flag
+				continue outer
+			}
+		}
+		return 0, false
+	}
+	return flags, true
+}
+
+// notifyFlagsString is the notify-keyspace-events value for the flags, the
+// way CONFIG GET shows it.
+func notifyFlagsString(flags int) string {
+	var b strings.Builder
+	all := flags&notifyAll == notifyAll
+	if all {
+		b.WriteByte('A')
+	}
+	for _, nc := range notifyClasses {
+		if all && nc.flag&notifyAll != 0 {
+			continue
+		}
+		if flags&nc.flag != 0 {
+			b.WriteByte(nc.c)
+		}
+	}
+	return b.String()
+}
+
+func checkConfigNotify(v string) error {
+	if _, ok := parseNotifyFlags(v); !ok {
+		return errConfigNotify
+	}
+	return nil
+}
+
+func getNotify(m *Miniredis) string {
+	return notifyFlagsString(m.notifyFlags)
+}
+
+func setNotify(m *Miniredis, v string) {
+	m.notifyFlags, _ = parseNotifyFlags(v)
+}
+
+// notify publishes a keyspace notification, if notify-keyspace-events has
+// the class. Needs the lock.
+func (db *RedisDB) notify(class int, event, key string) {
+	m := db.master
+	if m.notifyFlags&class == 0 {
+		return
+	}
+	if m.notifyFlags&notifyKeyspace != 0 {
+		m.publish(fmt.Sprintf("__keyspace@%d__:%s", db.id, key), event)
+	}
+	if m.notifyFlags&notifyKeyevent != 0 {
+		m.publish(fmt.Sprintf("__keyevent@%d__:%s", db.id, event), key)
+	}
+}
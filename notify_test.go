@@ -0,0 +1,93 @@
+package miniredis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/proto"
+)
+
+func TestNotifyConfig(t *testing.T) {
+	_, c := runWithClient(t)
+
+	mustDo(t, c,
+		"CONFIG", "GET", "notify-keyspace-events",
+		proto.Strings("notify-keyspace-events", ""),
+	)
+	mustOK(t, c, "CONFIG", "SET", "notify-keyspace-events", "KEA")
+	mustDo(t, c,
+		"CONFIG", "GET", "notify-keyspace-events",
+		proto.Strings("notify-keyspace-events", "AKE"),
+	)
+	mustOK(t, c, "CONFIG", "SET", "notify-keyspace-events", "Kx$g")
+	mustDo(t, c,
+		"CONFIG", "GET", "notify-keyspace-events",
+		proto.Strings("notify-keyspace-events", "g$xK"),
+	)
+	mustDo(t, c,
+		"CONFIG", "SET", "notify-keyspace-events", "KEq",
+		proto.Error("ERR CONFIG SET failed (possibly related to argument 'notify-keyspace-events') - Invalid event class character. Use 'Ag$lshzxeKEtmdn'."),
+	)
+}
+
+func TestNotify(t *testing.T) {
+	s, c := runWithClient(t)
+
+	// nothing is published until it's configured
+	sets, cancel := s.Subscribe("__keyevent@0__:set")
+	defer cancel()
+	mustOK(t, c, "SET", "foo", "bar")
+	mustOK(t, c, "CONFIG", "SET", "notify-keyspace-events", "KE$gx")
+	mustOK(t, c, "SET", "foo", "baz")
+	equals(t, PubsubMessage{"__keyevent@0__:set", "foo"}, <-sets)
+
+	t.Run("keyspace", func(t *testing.T) {
+		msgs, cancel := s.Subscribe("__keyspace@0__:foo")
+		defer cancel()
+
+		mustOK(t, c, "SET", "foo", "bar", "EX", "100")
+		<-sets
+		equals(t, PubsubMessage{"__keyspace@0__:foo", "set"}, <-msgs)
+		equals(t, PubsubMessage{"__keyspace@0__:foo", "expire"}, <-msgs)
+		must1(t, c, "PERSIST", "foo")
+		equals(t, PubsubMessage{"__keyspace@0__:foo", "persist"}, <-msgs)
+		mustDo(t, c, "APPEND", "foo", "!", proto.Int(4))
+		equals(t, PubsubMessage{"__keyspace@0__:foo", "append"}, <-msgs)
+		mustOK(t, c, "RENAME", "foo", "bar")
+		equals(t, PubsubMessage{"__keyspace@0__:foo", "rename_from"}, <-msgs)
+		must1(t, c, "DEL", "bar")
+		must0(t, c, "DEL", "foo")
+	})
+
+	t.Run("keyevent", func(t *testing.T) {
+		dels, cancel := s.Subscribe("__keyevent@0__:del")
+		defer cancel()
+		incrs, cancel := s.Subscribe("__keyevent@0__:incrby")
+		defer cancel()
+
+		must1(t, c, "INCR", "count")
+		equals(t, PubsubMessage{"__keyevent@0__:incrby", "count"}, <-incrs)
+		must1(t, c, "DEL", "count")
+		equals(t, PubsubMessage{"__keyevent@0__:del", "count"}, <-dels)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		msgs, cancel := s.Subscribe("__keyevent@0__:expired")
+		defer cancel()
+
+		mustOK(t, c, "SET", "short", "lived", "EX", "10")
+		<-sets
+		s.FastForward(20 * time.Second)
+		equals(t, PubsubMessage{"__keyevent@0__:expired", "short"}, <-msgs)
+	})
+
+	t.Run("other db", func(t *testing.T) {
+		msgs, cancel := s.Subscribe("__keyevent@2__:set")
+		defer cancel()
+
+		mustOK(t, c, "SELECT", "2")
+		mustOK(t, c, "SET", "foo", "bar")
+		equals(t, PubsubMessage{"__keyevent@2__:set", "foo"}, <-msgs)
+		mustOK(t, c, "SELECT", "0")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alicebob/miniredis/v2/server"
@@ -58,3 +59,58 @@ func optDuration(c *server.Peer, src string, dest *time.Duration) bool {
 	*dest = time.Duration(n*1_000_000) * time.Microsecond
 	return true
 }
+
+// optMpop parses the "numkeys key [key ...] where [COUNT count]" arguments
+// of the LMPOP and ZMPOP commands. where is set to 0 or 1, for the first or
+// second element of wheres.
+// Writes an error to c when the arguments are wrong. Returns whether or not
+// things were okay.
+func optMpop(c *server.Peer, args []string, wheres [2]string, keys *[]string, where *int, count *int) bool {
+	var numKeys int
+	if ok := optIntErr(c, args[0], &numKeys, msgNumkeysPositive); !ok {
+		return false
+	}
+	if numKeys <= 0 {
+		setDirty(c)
+		c.WriteError(msgNumkeysPositive)
+		return false
+	}
+	args = args[1:]
+	if numKeys >= len(args) {
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return false
+	}
+	*keys, args = args[:numKeys], args[numKeys:]
+
+	switch strings.ToUpper(args[0]) {
+	case wheres[0]:
+		*where = 0
+	case wheres[1]:
+		*where = 1
+	default:
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return false
+	}
+	args = args[1:]
+
+	*count = 1
+	if len(args) == 2 && strings.ToUpper(args[0]) == "COUNT" {
+		if ok := optIntErr(c, args[1], count, msgCountPositive); !ok {
+			return false
+		}
+		if *count <= 0 {
+			setDirty(c)
+			c.WriteError(msgCountPositive)
+			return false
+		}
+		args = args[2:]
+	}
+	if len(args) > 0 {
+		setDirty(c)
+		c.WriteError(msgSyntaxError)
+		return false
+	}
+	return true
+}
@@ -149,16 +149,18 @@ func Read(r *bufio.Reader) (string, error) {
 	switch line[0] {
 	default:
 		return "", ErrProtocol
-	case '+', '-', ':', ',', '_':
+	case '+', '-', ':', ',', '_', '(':
 		// +: inline string
 		// -: errors
 		// :: integer
 		// ,: float
 		// _: null
+		// (: big number
 		// Simple line based replies.
 		return line, nil
-	case '$':
+	case '$', '=':
 		// bulk strings are: `$5\r\nhello\r\n`
+		// verbatim strings are: `=9\r\ntxt:hello\r\n`
 		length, err := strconv.Atoi(line[1 : len(line)-2])
 		if err != nil {
 			return "", err
@@ -84,6 +84,16 @@ func TestRead(t *testing.T) {
 		test(t, "_\r\n")
 	})
 
+	t.Run("big numbers", func(t *testing.T) {
+		test(t, "(3492890328409238509324850943850943825024385\r\n")
+		test(t, "(-12\r\n")
+	})
+
+	t.Run("verbatim strings", func(t *testing.T) {
+		test(t, "=15\r\ntxt:Some string\r\n")
+		test(t, "=4\r\ntxt:\r\n")
+	})
+
 	t.Run("array", func(t *testing.T) {
 		test(t, "*0\r\n")
 		test(t, "*1\r\n-foo\r\n")
@@ -10,6 +10,16 @@ func String(s string) string {
 	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
 }
 
+// Verbatim string, with a 3 letter format such as "txt"
+func Verbatim(format, s string) string {
+	return fmt.Sprintf("=%d\r\n%s:%s\r\n", len(format)+1+len(s), format, s)
+}
+
+// BigNumber
+func BigNumber(n string) string {
+	return inline('(', n)
+}
+
 // Inline string
 func Inline(s string) string {
 	return inline('+', s)
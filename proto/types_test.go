@@ -23,6 +23,10 @@ func TestTypes(t *testing.T) {
 
 	test(Float(42.42), ",42.42\r\n")
 
+	test(BigNumber("12345678901234567890"), "(12345678901234567890\r\n")
+
+	test(Verbatim("txt", "hello"), "=9\r\ntxt:hello\r\n")
+
 	test(Array(Inline("hi"), Inline("ho")), "*2\r\n+hi\r\n+ho\r\n")
 	test(Strings("hi", "ho"), "*2\r\n$2\r\nhi\r\n$2\r\nho\r\n")
 
@@ -114,7 +114,10 @@ func (s *Subscriber) Channels() []string {
 func (s *Subscriber) Patterns() []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.sortedPatterns()
+}
 
+func (s *Subscriber) sortedPatterns() []string {
 	var ps []string
 	for p := range s.patterns {
 		ps = append(ps, p)
@@ -140,12 +143,11 @@ subs:
 		}
 	}
 
-pats:
-	for orig, pat := range s.patterns {
-		if pat != nil && pat.MatchString(c) {
+	// every matching pattern gets the message
+	for _, orig := range s.sortedPatterns() {
+		if pat := s.patterns[orig]; pat != nil && pat.MatchString(c) {
 			s.ppublish <- PubsubPmessage{orig, c, msg}
 			found++
-			break pats
 		}
 	}
 
@@ -0,0 +1,212 @@
+package miniredis
+
+// The DUMP payload format, as used by real Redis. This only implements the
+// parts needed for FUNCTION DUMP and FUNCTION RESTORE.
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc64"
+	"strconv"
+)
+
+const (
+	rdbVersion          = 10 // Redis 7.0
+	rdbOpcodeFunction2  = 245
+	rdbEncInt8          = 0
+	rdbEncInt16         = 1
+	rdbEncInt32         = 2
+	rdbEncLZF           = 3
+	rdbLen32            = 0x80
+	rdbLen64            = 0x81
+	rdbPayloadFooterLen = 2 + 8 // rdb version, crc64
+	// LZF's longest back reference gives 264 bytes for 3 input bytes
+	lzfMaxRatio = 88
+)
+
+var (
+	errRdbPayload = errors.New("ERR payload version or checksum are wrong")
+	errRdbFormat  = errors.New("ERR given payload is not a valid dump")
+
+	// the CRC64 variant Redis uses ("Jones", reflected)
+	rdbCRCTable = crc64.MakeTable(0x95ac9329ac4bc9b5)
+)
+
+func rdbCRC(b []byte) uint64 {
+	return ^crc64.Update(^uint64(0), rdbCRCTable, b)
+}
+
+// rdbFooter adds the rdb version and the checksum to a payload.
+func rdbFooter(b []byte) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint16(buf[:], rdbVersion)
+	b = append(b, buf[:2]...)
+	binary.LittleEndian.PutUint64(buf[:], rdbCRC(b))
+	return append(b, buf[:]...)
+}
+
+// rdbCheckFooter verifies the version and checksum, and returns the payload
+// without the footer.
+func rdbCheckFooter(b []byte) ([]byte, error) {
+	if len(b) < rdbPayloadFooterLen {
+		return nil, errRdbPayload
+	}
+	l := len(b) - rdbPayloadFooterLen
+	if binary.LittleEndian.Uint16(b[l:]) > rdbVersion+1 {
+		return nil, errRdbPayload
+	}
+	if binary.LittleEndian.Uint64(b[l+2:]) != rdbCRC(b[:l+2]) {
+		return nil, errRdbPayload
+	}
+	return b[:l], nil
+}
+
+// rdbAppendString adds a length prefixed string, without compression.
+func rdbAppendString(b []byte, s string) []byte {
+	b = rdbAppendLen(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func rdbAppendLen(b []byte, n uint64) []byte {
+	var buf [8]byte
+	switch {
+	case n < 1<<6:
+		return append(b, byte(n))
+	case n < 1<<14:
+		return append(b, byte(n>>8)|0x40, byte(n))
+	case n <= 1<<32-1:
+		binary.BigEndian.PutUint32(buf[:], uint32(n))
+		return append(append(b, rdbLen32), buf[:4]...)
+	default:
+		binary.BigEndian.PutUint64(buf[:], n)
+		return append(append(b, rdbLen64), buf[:]...)
+	}
+}
+
+// rdbReadLen reads a length. If encoded is true the "length" is one of the
+// rdbEnc* constants.
+func rdbReadLen(b []byte) (n uint64, encoded bool, rest []byte, err error) {
+	if len(b) == 0 {
+		return 0, false, nil, errRdbFormat
+	}
+	switch t := b[0] >> 6; t {
+	case 0:
+		return uint64(b[0] & 0x3f), false, b[1:], nil
+	case 1:
+		if len(b) < 2 {
+			return 0, false, nil, errRdbFormat
+		}
+		return uint64(b[0]&0x3f)<<8 | uint64(b[1]), false, b[2:], nil
+	case 2:
+		switch {
+		case b[0] == rdbLen32 && len(b) >= 5:
+			return uint64(binary.BigEndian.Uint32(b[1:])), false, b[5:], nil
+		case b[0] == rdbLen64 && len(b) >= 9:
+			return binary.BigEndian.Uint64(b[1:]), false, b[9:], nil
+		default:
+			return 0, false, nil, errRdbFormat
+		}
+	default:
+		return uint64(b[0] & 0x3f), true, b[1:], nil
+	}
+}
+
+// rdbReadString reads a string, which might be an encoded integer, or LZF
+// compressed.
+func rdbReadString(b []byte) (string, []byte, error) {
+	n, encoded, b, err := rdbReadLen(b)
+	if err != nil {
+		return "", nil, err
+	}
+	if !encoded {
+		if uint64(len(b)) < n {
+			return "", nil, errRdbFormat
+		}
+		return string(b[:n]), b[n:], nil
+	}
+
+	switch n {
+	case rdbEncInt8:
+		if len(b) < 1 {
+			return "", nil, errRdbFormat
+		}
+		return strconv.Itoa(int(int8(b[0]))), b[1:], nil
+	case rdbEncInt16:
+		if len(b) < 2 {
+			return "", nil, errRdbFormat
+		}
+		return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(b)))), b[2:], nil
+	case rdbEncInt32:
+		if len(b) < 4 {
+			return "", nil, errRdbFormat
+		}
+		return strconv.Itoa(int(int32(binary.LittleEndian.Uint32(b)))), b[4:], nil
+	case rdbEncLZF:
+		clen, _, b, err := rdbReadLen(b)
+		if err != nil {
+			return "", nil, err
+		}
+		l, _, b, err := rdbReadLen(b)
+		if err != nil {
+			return "", nil, err
+		}
+		if uint64(len(b)) < clen {
+			return "", nil, errRdbFormat
+		}
+		if l > clen*lzfMaxRatio {
+			// don't allocate whatever the payload claims
+			return "", nil, errRdbPayload
+		}
+		s, err := lzfDecompress(b[:clen], int(l))
+		if err != nil {
+			return "", nil, err
+		}
+		return string(s), b[clen:], nil
+	default:
+		return "", nil, errRdbFormat
+	}
+}
+
+// lzfDecompress is the decompression part of liblzf, which Redis uses.
+func lzfDecompress(in []byte, l int) ([]byte, error) {
+	out := make([]byte, 0, l)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 1<<5 {
+			// literal run
+			ctrl++
+			if i+ctrl > len(in) {
+				return nil, errRdbFormat
+			}
+			out = append(out, in[i:i+ctrl]...)
+			i += ctrl
+			continue
+		}
+
+		// back reference
+		n := ctrl >> 5
+		if n == 7 {
+			if i >= len(in) {
+				return nil, errRdbFormat
+			}
+			n += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errRdbFormat
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errRdbFormat
+		}
+		for j := 0; j < n+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != l {
+		return nil, errRdbFormat
+	}
+	return out, nil
+}
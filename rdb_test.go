@@ -0,0 +1,68 @@
+package miniredis
+
+import (
+	"testing"
+)
+
+func TestRdb(t *testing.T) {
+	t.Run("crc", func(t *testing.T) {
+		// the check value from Redis' crc64.c
+		equals(t, uint64(0xe9c6d914c4b8d9ca), rdbCRC([]byte("123456789")))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		for _, n := range []int{0, 10, 63, 64, 16383, 16384, 70000} {
+			s := string(make([]byte, n))
+			b := rdbAppendString(nil, s)
+			have, rest, err := rdbReadString(b)
+			ok(t, err)
+			equals(t, s, have)
+			equals(t, 0, len(rest))
+		}
+	})
+
+	t.Run("int encoded", func(t *testing.T) {
+		have, _, err := rdbReadString([]byte{0xc0, 0xfe})
+		ok(t, err)
+		equals(t, "-2", have)
+
+		have, _, err = rdbReadString([]byte{0xc1, 0x39, 0x30})
+		ok(t, err)
+		equals(t, "12345", have)
+	})
+
+	t.Run("lzf", func(t *testing.T) {
+		// 'a' literal, then a back reference of 9 bytes
+		have, _, err := rdbReadString([]byte{0xc3, 0x05, 0x0a, 0x00, 'a', 0xe0, 0x00, 0x00})
+		ok(t, err)
+		equals(t, "aaaaaaaaaa", have)
+
+		_, _, err = rdbReadString([]byte{0xc3, 0x05, 0x0b, 0x00, 'a', 0xe0, 0x00, 0x00})
+		equals(t, errRdbFormat, err)
+
+		// claims to decompress to ~2^63 bytes
+		b := []byte{0xc3, 0x05, rdbLen64, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 'a', 0xe0, 0x00, 0x00}
+		_, _, err = rdbReadString(b)
+		equals(t, errRdbPayload, err)
+	})
+
+	t.Run("footer", func(t *testing.T) {
+		b := rdbFooter([]byte("hello"))
+		have, err := rdbCheckFooter(b)
+		ok(t, err)
+		equals(t, "hello", string(have))
+
+		b[0] = 'H'
+		_, err = rdbCheckFooter(b)
+		equals(t, errRdbPayload, err)
+
+		_, err = rdbCheckFooter([]byte("short"))
+		equals(t, errRdbPayload, err)
+
+		// a zero checksum is still checked
+		b = rdbFooter([]byte("hello"))
+		copy(b[len(b)-8:], make([]byte, 8))
+		_, err = rdbCheckFooter(b)
+		equals(t, errRdbPayload, err)
+	})
+}
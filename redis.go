@@ -12,6 +12,15 @@ import (
 	"github.com/alicebob/miniredis/v2/server"
 )
 
+// redisVersion is the version we pretend to be, in HELLO and LOLWUT.
+const redisVersion = "6.0.5"
+
+// Roles, see SetRole().
+const (
+	roleMaster = "master"
+	roleSlave  = "slave"
+)
+
 const (
 	msgWrongType            = "WRONGTYPE Operation against a key holding the wrong kind of value"
 	msgNotValidHllValue     = "WRONGTYPE Key is not a valid HyperLogLog string value."
@@ -28,17 +37,27 @@ const (
 	msgInvalidCursor        = "ERR invalid cursor"
 	msgXXandNX              = "ERR XX and NX options at the same time are not compatible"
 	msgTimeoutNegative      = "ERR timeout is negative"
+	msgTimeoutNotInt        = "ERR timeout is not an integer or out of range"
 	msgTimeoutIsOutOfRange  = "ERR timeout is out of range"
 	msgInvalidSETime        = "ERR invalid expire time in set"
 	msgInvalidSETEXTime     = "ERR invalid expire time in setex"
 	msgInvalidPSETEXTime    = "ERR invalid expire time in psetex"
+	msgInvalidGETEXTime     = "ERR invalid expire time in getex"
 	msgInvalidKeysNumber    = "ERR Number of keys can't be greater than number of args"
 	msgNegativeKeysNumber   = "ERR Number of keys can't be negative"
 	msgFScriptUsage         = "ERR unknown subcommand or wrong number of arguments for '%s'. Try SCRIPT HELP."
 	msgFScriptUsageSimple   = "ERR unknown subcommand '%s'. Try SCRIPT HELP."
 	msgFPubsubUsage         = "ERR unknown subcommand or wrong number of arguments for '%s'. Try PUBSUB HELP."
 	msgFPubsubUsageSimple   = "ERR unknown subcommand '%s'. Try PUBSUB HELP."
+	msgFreqNoLFU            = "ERR An LFU maxmemory policy is not selected, access frequency not tracked. Please note that when switching between policies at runtime LRU and LFU data will take some time to adjust."
+	msgIdletimeLFU          = "ERR An LFU maxmemory policy is selected, idle time not tracked. Please note that when switching between policies at runtime LRU and LFU data will take some time to adjust."
+	msgFCommandUsage        = "ERR unknown subcommand '%s'. Try COMMAND HELP."
+	msgInvalidCommand       = "ERR Invalid command specified"
+	msgNoKeyArgs            = "ERR The command has no key arguments"
 	msgFObjectUsage         = "ERR unknown subcommand '%s'. Try OBJECT HELP."
+	msgPackedThreshold      = "ERR argument must be a memory value bigger than 1 and smaller than 4gb"
+	msgNotListpack          = "ERR Not a listpack encoded object."
+	msgFDebugUsage          = "ERR unknown subcommand or wrong number of arguments for '%s'. Try DEBUG HELP."
 	msgScriptFlush          = "ERR SCRIPT FLUSH only support SYNC|ASYNC option"
 	msgSingleElementPair    = "ERR INCR option supports a single increment-element pair"
 	msgGTLTandNX            = "ERR GT, LT, and/or NX options at the same time are not compatible"
@@ -47,18 +66,43 @@ const (
 	msgStreamIDZero         = "ERR The ID specified in XADD must be greater than 0-0"
 	msgNoScriptFound        = "NOSCRIPT No matching script. Please use EVAL."
 	msgUnsupportedUnit      = "ERR unsupported unit provided. please use M, KM, FT, MI"
+	msgAnyWithoutCount      = "ERR the ANY argument requires COUNT argument"
+	msgFailoverTimeout      = "ERR FAILOVER timeout must be greater than 0"
+	msgFailoverAbortArgs    = "ERR FAILOVER abort cannot be used with other arguments"
+	msgFailoverForce        = "ERR FAILOVER with force option requires both a timeout and target HOST and IP."
+	msgFailoverReplica      = "ERR FAILOVER is not valid when server is a replica."
+	msgNoFailover           = "ERR No failover in progress."
 	msgXreadUnbalanced      = "ERR Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified."
 	msgXgroupKeyNotFound    = "ERR The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically."
 	msgXtrimInvalidStrategy = "ERR unsupported XTRIM strategy. Please use MAXLEN, MINID"
 	msgXtrimInvalidMaxLen   = "ERR value is not an integer or out of range"
 	msgXtrimInvalidLimit    = "ERR syntax error, LIMIT cannot be used without the special ~ option"
 	msgDBIndexOutOfRange    = "ERR DB index is out of range"
+	msgWithScoresByLex      = "ERR syntax error, WITHSCORES not supported in combination with BYLEX"
 	msgLimitCombination     = "ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"
 	msgRankIsZero           = "ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list"
 	msgCountIsNegative      = "ERR COUNT can't be negative"
+	msgCountPositive        = "ERR count should be greater than 0"
+	msgNumkeysPositive      = "ERR numkeys should be greater than 0"
+	msgStringTooLong        = "ERR string exceeds maximum allowed size (proto-max-bulk-len)"
+	msgBitOffset            = "ERR bit offset is not an integer or out of range"
 	msgMaxLengthIsNegative  = "ERR MAXLEN can't be negative"
 	msgLimitIsNegative      = "ERR LIMIT can't be negative"
 	msgMemorySubcommand     = "ERR unknown subcommand '%s'. Try MEMORY HELP."
+	msgFFunctionUsage       = "ERR unknown subcommand '%s'. Try FUNCTION HELP."
+	msgFunctionNotFound     = "ERR Function not found"
+	msgLibraryNotFound      = "ERR Library not found"
+	msgLibraryMetadata      = "ERR Missing library metadata"
+	msgLibraryNoName        = "ERR Library name was not given"
+	msgLibraryNameInvalid   = "ERR Library names can only contain letters, numbers, or underscores(_) and must be at least one character long"
+	msgFunctionNameInvalid  = "Function names can only contain letters, numbers, or underscores(_) and must be at least one character long"
+	msgNoFunctions          = "ERR No functions registered"
+	msgFunctionWriteRO      = "ERR Can not execute a script with write flag using *_ro command."
+	msgWriteFromReadOnly    = "ERR Write commands are not allowed from read-only scripts."
+	msgNoFunctionRunning    = "NOTBUSY No scripts in execution right now."
+	msgFunctionKilled       = "ERR Script killed by user with FUNCTION KILL."
+	msgLCSWrongType         = "ERR The specified keys must contain string values"
+	msgLCSLenAndIdx         = "ERR If you want both the length and indexes, please just use IDX."
 )
 
 func errWrongNumber(cmd string) string {
@@ -69,6 +113,10 @@ func errLuaParseError(err error) string {
 	return fmt.Sprintf("ERR Error compiling script (new function): %s", err.Error())
 }
 
+func errFunctionRunError(err error) string {
+	return fmt.Sprintf("ERR Error running function: %s", err.Error())
+}
+
 func errReadgroup(key, group string) error {
 	return fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, group)
 }
@@ -103,7 +151,9 @@ func withTx(
 		return
 	}
 	m.Lock()
+	m.trackingSrc = c
 	cb(c, ctx)
+	m.trackingSrc = nil
 	// done, wake up anyone who waits on anything.
 	m.signal.Broadcast()
 	m.Unlock()
@@ -113,10 +163,13 @@ func withTx(
 type blockCmd func(*server.Peer, *connCtx) bool
 
 // blocking keeps trying a command until the callback returns true. Calls
-// onTimeout after the timeout (or when we call this in a transaction).
+// onTimeout after the timeout (or when we call this in a transaction or from
+// a script, which can't block). The command isn't tried while CLIENT PAUSE
+// holds back cmd.
 func blocking(
 	m *Miniredis,
 	c *server.Peer,
+	cmd string,
 	timeout time.Duration,
 	cb blockCmd,
 	onTimeout func(*server.Peer),
@@ -133,6 +186,14 @@ func blocking(
 		c.WriteInline("QUEUED")
 		return
 	}
+	if ctx.nested {
+		// Lua's .call(), which is already locked. Scripts never block, it's
+		// a timeout right away, even with timeout 0.
+		if !cb(c, ctx) {
+			onTimeout(c)
+		}
+		return
+	}
 
 	localCtx, cancel := context.WithCancel(m.Ctx)
 	defer cancel()
@@ -145,11 +206,8 @@ func blocking(
 		m.signal.Broadcast() // main loop might miss this signal
 	}()
 
-	if !ctx.nested {
-		// this is a call via Lua's .call(). It's already locked.
-		m.Lock()
-		defer m.Unlock()
-	}
+	m.Lock()
+	defer m.Unlock()
 	for {
 		if c.Closed() {
 			return
@@ -159,9 +217,13 @@ func blocking(
 			return
 		}
 
-		done := cb(c, ctx)
-		if done {
-			return
+		if !m.pausedFor(cmd) {
+			m.trackingSrc = c
+			done := cb(c, ctx)
+			m.trackingSrc = nil
+			if done {
+				return
+			}
 		}
 
 		if timedOut {
@@ -169,7 +231,7 @@ func blocking(
 			return
 		}
 
-		m.signal.Wait()
+		m.wait(c)
 	}
 }
 
@@ -17,7 +17,7 @@ func TestRedis(t *testing.T) {
 
 	go func() {
 		defer wg.Done()
-		blocking(s, peer, time.Second, func(p *server.Peer, cc *connCtx) bool {
+		blocking(s, peer, "BLPOP", time.Second, func(p *server.Peer, cc *connCtx) bool {
 			err := s.Ctx.Err()
 			if err != nil {
 				t.Error("blocking call should not retry command when context has error")
@@ -0,0 +1,58 @@
+package miniredis
+
+// SCAN cursors, the way Redis does them.
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/alicebob/miniredis/v2/metro"
+)
+
+// scanKeys does a single SCAN call over keys. It behaves as if the keys are
+// stored in a hash table, and it visits the buckets in "reverse binary" order,
+// the same way Redis does. This guarantees that every key which exists during
+// the whole scan is returned at least once, even when keys are added or
+// removed between calls (and the table grows or shrinks).
+// A count of 0 means no limit. Returns the next cursor, which is 0 when the
+// scan is done.
+func scanKeys(keys []string, cursor uint64, count int) (uint64, []string) {
+	size := uint64(4) // the smallest table Redis makes
+	for size < uint64(len(keys)) {
+		size *= 2
+	}
+	mask := size - 1
+
+	buckets := map[uint64][]string{}
+	for _, k := range keys {
+		b := metro.Hash64Str(k, 0) & mask
+		buckets[b] = append(buckets[b], k)
+	}
+
+	var res []string
+	for {
+		bucket := buckets[cursor&mask]
+		sort.Strings(bucket)
+		res = append(res, bucket...)
+
+		// increment the reversed cursor
+		cursor |= ^mask
+		cursor = bits.Reverse64(cursor)
+		cursor++
+		cursor = bits.Reverse64(cursor)
+
+		if cursor == 0 || (count > 0 && len(res) >= count) {
+			return cursor, res
+		}
+	}
+}
+
+// scanMembers is scanKeys for the elements of a single hash, set, or sorted
+// set. Without a COUNT the first call returns everything, in the order given,
+// which is also what Redis does for small keys.
+func scanMembers(members []string, cursor uint64, count int) (uint64, []string) {
+	if cursor == 0 && count == 0 {
+		return 0, members
+	}
+	return scanKeys(members, cursor, count)
+}
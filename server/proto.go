@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"strconv"
+	"strings"
 )
 
 type Simple string
@@ -11,37 +12,117 @@ type Simple string
 // ErrProtocol is the general error for unexpected input
 var ErrProtocol = errors.New("invalid request")
 
-// client always sends arrays with bulk strings
+// client always sends arrays with bulk strings, but we also accept inline
+// commands: "PING\r\n"
 func readArray(rd *bufio.Reader) ([]string, error) {
 	line, err := rd.ReadString('\n')
 	if err != nil {
 		return nil, err
 	}
+	if len(line) == 0 || line[0] != '*' {
+		return readInline(line)
+	}
 	if len(line) < 3 {
 		return nil, ErrProtocol
 	}
 
-	switch line[0] {
-	default:
-		return nil, ErrProtocol
-	case '*':
-		l, err := strconv.Atoi(line[1 : len(line)-2])
+	l, err := strconv.Atoi(line[1 : len(line)-2])
+	if err != nil {
+		return nil, err
+	}
+	// l can be -1
+	var fields []string
+	for ; l > 0; l-- {
+		s, err := readString(rd)
 		if err != nil {
 			return nil, err
 		}
-		// l can be -1
-		var fields []string
-		for ; l > 0; l-- {
-			s, err := readString(rd)
-			if err != nil {
-				return nil, err
+		fields = append(fields, s)
+	}
+	return fields, nil
+}
+
+// readInline splits an inline command the way redis does: on spaces, with
+// "double quoted" strings which can have escapes, and 'single quoted' strings.
+func readInline(line string) ([]string, error) {
+	line = strings.TrimRight(line, "\r\n")
+	var (
+		fields []string
+		i      = 0
+	)
+	for {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			return fields, nil
+		}
+
+		var (
+			field  []byte
+			quote  byte
+			closed bool
+		)
+	arg:
+		for ; i < len(line); i++ {
+			b := line[i]
+			switch {
+			case quote == '"' && b == '\\' && i+3 < len(line) && line[i+1] == 'x' && isHex(line[i+2]) && isHex(line[i+3]):
+				n, _ := strconv.ParseUint(line[i+2:i+4], 16, 8)
+				field = append(field, byte(n))
+				i += 3
+			case quote == '"' && b == '\\' && i+1 < len(line):
+				i++
+				switch c := line[i]; c {
+				case 'n':
+					field = append(field, '\n')
+				case 'r':
+					field = append(field, '\r')
+				case 't':
+					field = append(field, '\t')
+				case 'b':
+					field = append(field, '\b')
+				case 'a':
+					field = append(field, '\a')
+				default:
+					field = append(field, c)
+				}
+			case quote == '\'' && b == '\\' && i+1 < len(line) && line[i+1] == '\'':
+				i++
+				field = append(field, '\'')
+			case quote != 0 && b == quote:
+				// the closing quote must be followed by a space
+				if i+1 < len(line) && !isSpace(line[i+1]) {
+					return nil, ErrProtocol
+				}
+				i++
+				closed = true
+				break arg
+			case quote != 0:
+				field = append(field, b)
+			case isSpace(b):
+				break arg
+			case (b == '"' || b == '\'') && len(field) == 0:
+				quote = b
+			default:
+				field = append(field, b)
 			}
-			fields = append(fields, s)
 		}
-		return fields, nil
+		if quote != 0 && !closed {
+			return nil, ErrProtocol // unbalanced quotes
+		}
+		fields = append(fields, string(field))
 	}
 }
 
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+func isHex(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
 func readString(rd *bufio.Reader) (string, error) {
 	line, err := rd.ReadString('\n')
 	if err != nil {
@@ -143,8 +224,11 @@ func ParseReply(rd *bufio.Reader) (interface{}, error) {
 		if err != nil {
 			return nil, ErrProtocol
 		}
-		// l can be -1
-		var fields []interface{}
+		if l < 0 {
+			// null array
+			return nil, nil
+		}
+		fields := []interface{}{}
 		for ; l > 0; l-- {
 			s, err := ParseReply(rd)
 			if err != nil {
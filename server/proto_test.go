@@ -40,6 +40,37 @@ func TestReadArray(t *testing.T) {
 		{
 			payload: "*-1\r\n", // not sure this is legal in a request
 		},
+		{
+			payload: "PING\r\n",
+			res:     []string{"PING"},
+		},
+		{
+			payload: "  SET  foo\tbar \r\n",
+			res:     []string{"SET", "foo", "bar"},
+		},
+		{
+			payload: "PING\n",
+			res:     []string{"PING"},
+		},
+		{
+			payload: "\r\n",
+		},
+		{
+			payload: `SET "foo bar" 'a "b"' "\x41\n\"" ''` + "\r\n",
+			res:     []string{"SET", "foo bar", `a "b"`, "A\n\"", ""},
+		},
+		{
+			payload: `SET 'it\'s'` + "\r\n",
+			res:     []string{"SET", "it's"},
+		},
+		{
+			payload: `SET "foo` + "\r\n",
+			err:     ErrProtocol,
+		},
+		{
+			payload: `SET "foo"bar` + "\r\n",
+			err:     ErrProtocol,
+		},
 	} {
 		res, err := readArray(bufio.NewReader(bytes.NewBufferString(c.payload)))
 		if have, want := err, c.err; have != want {
@@ -2,17 +2,23 @@ package server
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/alicebob/miniredis/v2/fpconv"
 )
 
-func errUnknownCommand(cmd string, args []string) string {
+// ErrUnknownCommand is the error for a command which isn't registered.
+func ErrUnknownCommand(cmd string, args []string) string {
 	s := fmt.Sprintf("ERR unknown command `%s`, with args beginning with: ", cmd)
 	if len(args) > 20 {
 		args = args[:20]
@@ -31,12 +37,20 @@ type DisconnectHandler func(c *Peer)
 // Hook is can be added to run before every cmd. Return true if the command is done.
 type Hook func(*Peer, string, ...string) bool
 
+// PostHook runs after every known cmd, with how long the cmd took.
+type PostHook func(c *Peer, cmd string, args []string, d time.Duration)
+
+// ReplyHook gets the exact bytes sent to a connection in reply to a cmd.
+type ReplyHook func(c *Peer, cmd string, reply []byte)
+
 // Server is a simple redis server
 type Server struct {
 	l         net.Listener
 	cmds      map[string]Cmd
 	preHook   Hook
-	peers     map[net.Conn]struct{}
+	postHook  PostHook
+	replyHook ReplyHook
+	peers     map[net.Conn]*Peer
 	mu        sync.Mutex
 	wg        sync.WaitGroup
 	infoConns int
@@ -63,7 +77,7 @@ func NewServerTLS(addr string, cfg *tls.Config) (*Server, error) {
 func newServer(l net.Listener) *Server {
 	s := Server{
 		cmds:  map[string]Cmd{},
-		peers: map[net.Conn]struct{}{},
+		peers: map[net.Conn]*Peer{},
 		l:     l,
 	}
 
@@ -88,6 +102,21 @@ func (s *Server) SetPreHook(h Hook) {
 	s.mu.Unlock()
 }
 
+// (un)set a hook which is ran after every known command.
+func (s *Server) SetPostHook(h PostHook) {
+	s.mu.Lock()
+	s.postHook = h
+	s.mu.Unlock()
+}
+
+// (un)set a hook which gets the reply to every command from a network
+// connection.
+func (s *Server) SetReplyHook(h ReplyHook) {
+	s.mu.Lock()
+	s.replyHook = h
+	s.mu.Unlock()
+}
+
 func (s *Server) serve(l net.Listener) {
 	for {
 		conn, err := l.Accept()
@@ -100,17 +129,28 @@ func (s *Server) serve(l net.Listener) {
 
 // ServeConn handles a net.Conn. Nice with net.Pipe()
 func (s *Server) ServeConn(conn net.Conn) {
+	tee := &teeWriter{w: conn}
+	peer := &Peer{
+		w:    bufio.NewWriter(tee),
+		tee:  tee,
+		Addr: conn.RemoteAddr().String(),
+	}
+	if a := conn.LocalAddr(); a != nil {
+		peer.LocalAddr = a.String()
+	}
+
 	s.wg.Add(1)
 	s.mu.Lock()
-	s.peers[conn] = struct{}{}
 	s.infoConns++
+	peer.ID = s.infoConns
+	s.peers[conn] = peer
 	s.mu.Unlock()
 
 	go func() {
 		defer s.wg.Done()
 		defer conn.Close()
 
-		s.servePeer(conn)
+		s.servePeer(conn, peer)
 
 		s.mu.Lock()
 		delete(s.peers, conn)
@@ -154,11 +194,48 @@ func (s *Server) Register(cmd string, f Cmd) error {
 	return nil
 }
 
-func (s *Server) servePeer(c net.Conn) {
-	r := bufio.NewReader(c)
-	peer := &Peer{
-		w: bufio.NewWriter(c),
+// Commands gives all registered commands, sorted.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cmds := make([]string, 0, len(s.cmds))
+	for cmd := range s.cmds {
+		cmds = append(cmds, cmd)
 	}
+	sort.Strings(cmds)
+	return cmds
+}
+
+// Registered is true if the command is registered.
+func (s *Server) Registered(cmd string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.cmds[strings.ToUpper(cmd)]
+	return ok
+}
+
+// Rename moves a registered command to a new name. The old name will be an
+// unknown command. An empty new name removes the command.
+func (s *Server) Rename(from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	f, ok := s.cmds[from]
+	if !ok {
+		return fmt.Errorf("command not registered: %s", from)
+	}
+	if _, ok := s.cmds[to]; ok && to != from {
+		return fmt.Errorf("command already registered: %s", to)
+	}
+	delete(s.cmds, from)
+	if to != "" {
+		s.cmds[to] = f
+	}
+	return nil
+}
+
+func (s *Server) servePeer(c net.Conn, peer *Peer) {
+	r := bufio.NewReader(c)
 
 	defer func() {
 		for _, f := range peer.onDisconnect {
@@ -183,8 +260,21 @@ func (s *Server) servePeer(c net.Conn) {
 	}()
 
 	for args := range readCh {
+		if len(args) == 0 {
+			// empty inline command
+			continue
+		}
+		s.mu.Lock()
+		hook := s.replyHook
+		s.mu.Unlock()
+		if hook != nil {
+			peer.startCapture()
+		}
 		s.Dispatch(peer, args)
 		peer.Flush()
+		if hook != nil {
+			hook(peer, strings.ToUpper(args[0]), peer.stopCapture())
+		}
 
 		if peer.Closed() {
 			c.Close()
@@ -208,16 +298,21 @@ func (s *Server) Dispatch(c *Peer, args []string) {
 	cb, ok := s.cmds[cmdUp]
 	s.mu.Unlock()
 	if !ok {
-		c.WriteError(errUnknownCommand(cmd, args))
+		c.WriteError(ErrUnknownCommand(cmd, args))
 		return
 	}
 
 	s.mu.Lock()
 	s.infoCmds++
+	post := s.postHook
 	s.mu.Unlock()
+	start := time.Now()
 	cb(c, cmdUp, args)
+	if post != nil {
+		post(c, cmdUp, args, time.Since(start))
+	}
 	if c.SwitchResp3 != nil {
-		c.Resp3 = *c.SwitchResp3
+		c.SetResp3(*c.SwitchResp3)
 		c.SwitchResp3 = nil
 	}
 }
@@ -236,6 +331,18 @@ func (s *Server) ClientsLen() int {
 	return len(s.peers)
 }
 
+// Peers gives all connected clients, ordered by ID
+func (s *Server) Peers() []*Peer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps := make([]*Peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		ps = append(ps, p)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].ID < ps[j].ID })
+	return ps
+}
+
 // TotalConnections give the number of clients connected since the server
 // started, including the currently connected ones
 func (s *Server) TotalConnections() int {
@@ -247,13 +354,19 @@ func (s *Server) TotalConnections() int {
 // Peer is a client connected to the server
 type Peer struct {
 	w            *bufio.Writer
+	tee          *teeWriter // nil for internal peers
 	closed       bool
-	Resp3        bool
+	Resp3        bool        // change with SetResp3(), other connections read it with IsResp3()
 	SwitchResp3  *bool       // we'll switch to this version _after_ the command
 	Ctx          interface{} // anything goes, server won't touch this
 	onDisconnect []func()    // list of callbacks
 	mu           sync.Mutex  // for Block()
 	ClientName   string      // client name set by CLIENT SETNAME
+	LibName      string      // set by CLIENT SETINFO
+	LibVer       string      // set by CLIENT SETINFO
+	ID           int         // unique per connection, 0 for internal peers
+	Addr         string      // remote address, empty for internal peers
+	LocalAddr    string      // local address, empty for internal peers
 }
 
 func NewPeer(w *bufio.Writer) *Peer {
@@ -269,6 +382,37 @@ func (c *Peer) Flush() {
 	c.w.Flush()
 }
 
+// startCapture keeps a copy of everything sent to the connection, until
+// stopCapture.
+func (c *Peer) startCapture() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tee.buf = &bytes.Buffer{}
+}
+
+// stopCapture gives everything sent since startCapture.
+func (c *Peer) stopCapture() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.w.Flush()
+	b := c.tee.buf.Bytes()
+	c.tee.buf = nil
+	return b
+}
+
+// teeWriter writes to w, and to buf if that's set.
+type teeWriter struct {
+	w   io.Writer
+	buf *bytes.Buffer
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	if t.buf != nil {
+		t.buf.Write(p)
+	}
+	return t.w.Write(p)
+}
+
 // Close the client connection after the current command is done.
 func (c *Peer) Close() {
 	c.mu.Lock()
@@ -283,6 +427,22 @@ func (c *Peer) Closed() bool {
 	return c.closed
 }
 
+// SetResp3 switches the protocol version of the connection. Use this, and not
+// Resp3 directly, since other connections might be reading it.
+func (c *Peer) SetResp3(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Resp3 = v
+}
+
+// IsResp3 is true if the connection uses RESP3. Use this when reading the
+// version of another connection.
+func (c *Peer) IsResp3() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Resp3
+}
+
 // Register a function to execute on disconnect. There can be multiple
 // functions registered.
 func (c *Peer) OnDisconnect(f func()) {
@@ -371,6 +531,21 @@ func (c *Peer) WriteFloat(n float64) {
 	})
 }
 
+// WriteVerbatim writes a verbatim string, with a 3 letter format such as
+// "txt" or "mkd". It's a normal bulk string in RESP2.
+func (c *Peer) WriteVerbatim(format, s string) {
+	c.Block(func(w *Writer) {
+		w.WriteVerbatim(format, s)
+	})
+}
+
+// WriteBigNumber writes a big number. It's a bulk string in RESP2.
+func (c *Peer) WriteBigNumber(n *big.Int) {
+	c.Block(func(w *Writer) {
+		w.WriteBigNumber(n)
+	})
+}
+
 // WriteRaw writes a raw redis response
 func (c *Peer) WriteRaw(s string) {
 	c.Block(func(w *Writer) {
@@ -469,6 +644,24 @@ func (w *Writer) WriteNull() {
 	fmt.Fprintf(w.w, "$-1\r\n")
 }
 
+// WriteVerbatim writes a verbatim string
+func (w *Writer) WriteVerbatim(format, s string) {
+	if w.resp3 {
+		fmt.Fprintf(w.w, "=%d\r\n%s:%s\r\n", len(format)+1+len(s), format, s)
+		return
+	}
+	w.WriteBulk(s)
+}
+
+// WriteBigNumber writes a big number
+func (w *Writer) WriteBigNumber(n *big.Int) {
+	if w.resp3 {
+		fmt.Fprintf(w.w, "(%s\r\n", n.String())
+		return
+	}
+	w.WriteBulk(n.String())
+}
+
 // WriteInline writes a redis inline string
 func (w *Writer) WriteInline(s string) {
 	fmt.Fprintf(w.w, "+%s\r\n", toInline(s))
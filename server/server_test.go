@@ -1,10 +1,14 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"net"
 	"strconv"
 	"strings"
 	"testing"
@@ -238,6 +242,74 @@ func TestTLS(t *testing.T) {
 	}
 }
 
+func TestInline(t *testing.T) {
+	s, err := NewServer(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Register("PING", func(c *Peer, cmd string, args []string) {
+		c.WriteInline("PONG")
+	})
+	s.Register("ECHO", func(c *Peer, cmd string, args []string) {
+		if len(args) != 1 {
+			c.WriteError(errWrongNumberOfArgs)
+			return
+		}
+		c.WriteBulk(args[0])
+	})
+
+	c, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	r := bufio.NewReader(c)
+
+	if _, err := c.Write([]byte("PING\r\n\r\nECHO \"hello world\"\r\n*1\r\n$4\r\nPING\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"+PONG\r\n",
+		"$11\r\n",
+		"hello world\r\n",
+		"+PONG\r\n",
+	} {
+		have, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have != want {
+			t.Errorf("have: %q, want: %q", have, want)
+		}
+	}
+}
+
+func TestWriteResp3Types(t *testing.T) {
+	test := func(t *testing.T, resp3 bool, f func(*Peer), want string) {
+		t.Helper()
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		c := NewPeer(w)
+		c.Resp3 = resp3
+		f(c)
+		w.Flush()
+		if have := buf.String(); have != want {
+			t.Errorf("have: %q, want: %q", have, want)
+		}
+	}
+
+	verbatim := func(c *Peer) { c.WriteVerbatim("txt", "hello") }
+	test(t, false, verbatim, "$5\r\nhello\r\n")
+	test(t, true, verbatim, "=9\r\ntxt:hello\r\n")
+
+	n, _ := new(big.Int).SetString("1234567890123456789012345678901234567890", 10)
+	bigNumber := func(c *Peer) { c.WriteBigNumber(n) }
+	test(t, false, bigNumber, "$40\r\n1234567890123456789012345678901234567890\r\n")
+	test(t, true, bigNumber, "(1234567890123456789012345678901234567890\r\n")
+}
+
 func TestFormatFloat(t *testing.T) {
 	eq := func(t *testing.T, want string, n float64) {
 		t.Helper()
@@ -229,6 +229,23 @@ func (s *streamKey) createGroup(group, id string) error {
 	return nil
 }
 
+// setLastID sets the last delivered ID of the group. id can be "$".
+func (g *streamGroup) setLastID(id string) error {
+	s := g.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == "$" {
+		id = s.lastIDUnlocked()
+	}
+	id, err := formatStreamID(id)
+	if err != nil {
+		return err
+	}
+	g.lastID = id
+	return nil
+}
+
 // streamAdd adds an entry to a stream. Returns the new entry ID.
 // If id is empty or "*" the ID will be generated automatically.
 // `values` should have an even length.
@@ -0,0 +1,265 @@
+package miniredis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+// clientTracking is the CLIENT TRACKING state of a single connection.
+type clientTracking struct {
+	bcast    bool
+	prefixes []string // only used in BCAST mode
+	optin    bool
+	optout   bool
+	noloop   bool
+	caching  bool                // CLIENT CACHING was called, for the next command only
+	keys     map[string]struct{} // keys read by this client. Not used in BCAST mode.
+}
+
+func newClientTracking() *clientTracking {
+	return &clientTracking{
+		keys: map[string]struct{}{},
+	}
+}
+
+// matches is true if the key matches one of the BCAST prefixes. No prefixes
+// matches everything.
+func (t *clientTracking) matches(key string) bool {
+	if len(t.prefixes) == 0 {
+		return true
+	}
+	for _, p := range t.prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// a modified key, waiting to be sent to the tracking clients.
+type invalidation struct {
+	key string
+	src *server.Peer // connection which modified the key, or nil
+}
+
+// trackRead remembers the keys read by cmd, if c has tracking enabled.
+func (m *Miniredis) trackRead(c *server.Peer, cmd string, args []string) {
+	t, ok := m.trackers[c]
+	if !ok || t.bcast {
+		return
+	}
+	if cmd == "CLIENT" && len(args) > 0 && strings.ToUpper(args[0]) == "CACHING" {
+		return
+	}
+	caching := t.caching
+	t.caching = false
+	switch {
+	case t.optin && !caching:
+		return
+	case t.optout && caching:
+		return
+	}
+	keys, ok := readCommands[cmd]
+	if !ok {
+		return
+	}
+	for _, k := range keys(args) {
+		t.keys[k] = struct{}{}
+	}
+}
+
+// invalidate queues an invalidation message for the tracking clients. Messages
+// are sent when the lock is released. Needs to be called with the lock held.
+func (m *Miniredis) invalidate(key string) {
+	if len(m.trackers) == 0 {
+		return
+	}
+	for _, inv := range m.invalidated {
+		if inv.key == key && inv.src == m.trackingSrc {
+			return
+		}
+	}
+	m.invalidated = append(m.invalidated, invalidation{key: key, src: m.trackingSrc})
+}
+
+// invalidateAll queues a "flush everything" message for the tracking clients.
+func (m *Miniredis) invalidateAll() {
+	if len(m.trackers) == 0 {
+		return
+	}
+	m.invalidatedAll = true
+}
+
+// sendInvalidations sends all queued invalidation messages. Needs to be called
+// with the lock held.
+func (m *Miniredis) sendInvalidations() {
+	if len(m.invalidated) == 0 && !m.invalidatedAll {
+		return
+	}
+	all, invalidated := m.invalidatedAll, m.invalidated
+	m.invalidatedAll, m.invalidated = false, nil
+
+	for c, t := range m.trackers {
+		var keys []string
+		if all {
+			t.keys = map[string]struct{}{}
+		}
+		for _, inv := range invalidated {
+			if t.noloop && inv.src == c {
+				continue
+			}
+			if t.bcast {
+				if t.matches(inv.key) {
+					keys = append(keys, inv.key)
+				}
+				continue
+			}
+			if _, ok := t.keys[inv.key]; ok {
+				delete(t.keys, inv.key)
+				keys = append(keys, inv.key)
+			}
+		}
+		if !all && len(keys) == 0 {
+			continue
+		}
+		if !c.IsResp3() {
+			// there is no REDIRECT support, so RESP2 clients can't
+			// receive these.
+			continue
+		}
+		c.Block(func(w *server.Writer) {
+			w.WritePushLen(2)
+			w.WriteBulk("invalidate")
+			if all {
+				w.WriteNull()
+			} else {
+				w.WriteStrings(keys)
+			}
+			w.Flush()
+		})
+	}
+}
+
+// keysFunc gives the keys in the arguments of a command.
+type keysFunc func(args []string) []string
+
+// keyRange gives the keys from first to last (inclusive), with step. A
+// negative last counts from the end.
+func keyRange(first, last, step int) keysFunc {
+	return func(args []string) []string {
+		l := last
+		if l < 0 {
+			l = len(args) + l
+		}
+		var keys []string
+		for i := first; i <= l && i < len(args); i += step {
+			keys = append(keys, args[i])
+		}
+		return keys
+	}
+}
+
+// numKeys is for commands which have the number of keys at position pos,
+// followed by the keys.
+func numKeys(pos int) keysFunc {
+	return func(args []string) []string {
+		if len(args) <= pos {
+			return nil
+		}
+		n, err := strconv.Atoi(args[pos])
+		if err != nil || n < 0 {
+			return nil
+		}
+		keys := args[pos+1:]
+		if n < len(keys) {
+			keys = keys[:n]
+		}
+		return keys
+	}
+}
+
+// streamsKeys is for XREAD, which has the keys after STREAMS.
+func streamsKeys(args []string) []string {
+	for i, a := range args {
+		if strings.ToUpper(a) == "STREAMS" {
+			rest := args[i+1:]
+			return rest[:len(rest)/2]
+		}
+	}
+	return nil
+}
+
+// readCommands are all read-only commands, with where to find their keys.
+// Keys read by these commands are tracked for CLIENT TRACKING.
+var readCommands = map[string]keysFunc{
+	"BITCOUNT":             keyRange(0, 0, 1),
+	"BITFIELD_RO":          keyRange(0, 0, 1),
+	"BITPOS":               keyRange(0, 0, 1),
+	"EXISTS":               keyRange(0, -1, 1),
+	"EXPIRETIME":           keyRange(0, 0, 1),
+	"GEODIST":              keyRange(0, 0, 1),
+	"GEOPOS":               keyRange(0, 0, 1),
+	"GEORADIUSBYMEMBER_RO": keyRange(0, 0, 1),
+	"GEORADIUS_RO":         keyRange(0, 0, 1),
+	"GEOSEARCH":            keyRange(0, 0, 1),
+	"GET":                  keyRange(0, 0, 1),
+	"GETBIT":               keyRange(0, 0, 1),
+	"GETRANGE":             keyRange(0, 0, 1),
+	"HEXISTS":              keyRange(0, 0, 1),
+	"HGET":                 keyRange(0, 0, 1),
+	"HGETALL":              keyRange(0, 0, 1),
+	"HKEYS":                keyRange(0, 0, 1),
+	"HLEN":                 keyRange(0, 0, 1),
+	"HMGET":                keyRange(0, 0, 1),
+	"HRANDFIELD":           keyRange(0, 0, 1),
+	"HSCAN":                keyRange(0, 0, 1),
+	"HSTRLEN":              keyRange(0, 0, 1),
+	"HVALS":                keyRange(0, 0, 1),
+	"LCS":                  keyRange(0, 1, 1),
+	"LINDEX":               keyRange(0, 0, 1),
+	"LLEN":                 keyRange(0, 0, 1),
+	"LPOS":                 keyRange(0, 0, 1),
+	"LRANGE":               keyRange(0, 0, 1),
+	"MGET":                 keyRange(0, -1, 1),
+	"PEXPIRETIME":          keyRange(0, 0, 1),
+	"PFCOUNT":              keyRange(0, -1, 1),
+	"PTTL":                 keyRange(0, 0, 1),
+	"SCARD":                keyRange(0, 0, 1),
+	"SDIFF":                keyRange(0, -1, 1),
+	"SINTER":               keyRange(0, -1, 1),
+	"SINTERCARD":           numKeys(0),
+	"SISMEMBER":            keyRange(0, 0, 1),
+	"SMEMBERS":             keyRange(0, 0, 1),
+	"SMISMEMBER":           keyRange(0, 0, 1),
+	"SRANDMEMBER":          keyRange(0, 0, 1),
+	"SSCAN":                keyRange(0, 0, 1),
+	"STRLEN":               keyRange(0, 0, 1),
+	"SUNION":               keyRange(0, -1, 1),
+	"TTL":                  keyRange(0, 0, 1),
+	"TYPE":                 keyRange(0, 0, 1),
+	"XINFO":                keyRange(1, 1, 1),
+	"XLEN":                 keyRange(0, 0, 1),
+	"XRANGE":               keyRange(0, 0, 1),
+	"XREAD":                streamsKeys,
+	"XREVRANGE":            keyRange(0, 0, 1),
+	"ZCARD":                keyRange(0, 0, 1),
+	"ZCOUNT":               keyRange(0, 0, 1),
+	"ZINTER":               numKeys(0),
+	"ZINTERCARD":           numKeys(0),
+	"ZLEXCOUNT":            keyRange(0, 0, 1),
+	"ZMSCORE":              keyRange(0, 0, 1),
+	"ZRANDMEMBER":          keyRange(0, 0, 1),
+	"ZRANGE":               keyRange(0, 0, 1),
+	"ZRANGEBYLEX":          keyRange(0, 0, 1),
+	"ZRANGEBYSCORE":        keyRange(0, 0, 1),
+	"ZRANK":                keyRange(0, 0, 1),
+	"ZREVRANGE":            keyRange(0, 0, 1),
+	"ZREVRANGEBYLEX":       keyRange(0, 0, 1),
+	"ZREVRANGEBYSCORE":     keyRange(0, 0, 1),
+	"ZREVRANK":             keyRange(0, 0, 1),
+	"ZSCAN":                keyRange(0, 0, 1),
+	"ZSCORE":               keyRange(0, 0, 1),
+	"ZUNION":               numKeys(0),
+}